@@ -1,6 +1,7 @@
 package main
 
 import (
+	"os"
 	"time"
 
 	"github.com/rs/zerolog"
@@ -29,5 +30,13 @@ func main() {
 		log.Fatal().Msgf("could not load config: %v", err)
 	}
 
+	// "reconcile" fixes folder sizes once and exits instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "reconcile" {
+		if err = rest.Reconcile(*conf); err != nil {
+			log.Fatal().Msgf("could not reconcile folder sizes: %v", err)
+		}
+		return
+	}
+
 	rest.CreateServer(*conf)
 }
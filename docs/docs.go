@@ -35,6 +35,75 @@ const docTemplate = `{
                 }
             }
         },
+        "/v1/admin/users/{target_id}/quota": {
+            "put": {
+                "description": "Changes the storage quota of the user, null removes the limit. A quota below the current usage\ndoes not delete anything, but new uploads are rejected. Available to admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Set a user quota",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID of the admin",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the user whose quota is changed",
+                        "name": "target_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New quota",
+                        "name": "quota",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.SetUserQuotaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Quota successfully changed",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid user id or request body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/v1/folders": {
             "post": {
                 "description": "Creates a new folder with the given name and parent folder ID for the authenticated user.",
@@ -61,6 +130,17 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/api.RequestData"
                         }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -76,6 +156,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Folder with the same name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -87,7 +173,7 @@ const docTemplate = `{
         },
         "/v1/folders/{folder_id}": {
             "get": {
-                "description": "Retrieves size details of the folder specified by folder ID, similar to the 'du' command, but in JSON format",
+                "description": "Retrieves size details of the folder specified by folder ID, similar to the 'du' command, but in JSON format. Only direct subfolders are returned, use the tree endpoint for deeper levels",
                 "produces": [
                     "application/json"
                 ],
@@ -136,7 +222,7 @@ const docTemplate = `{
                 }
             },
             "delete": {
-                "description": "Deletes a specified folder and updates size calculations for all related folders",
+                "description": "Moves a specified folder with all its content to the trash and updates size calculations for all related folders. The root folder can't be removed",
                 "produces": [
                     "application/json"
                 ],
@@ -165,7 +251,13 @@ const docTemplate = `{
                         "description": "Folder successfully removed"
                     },
                     "400": {
-                        "description": "Invalid folder_id",
+                        "description": "Invalid folder_id or the folder is the root folder",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -177,21 +269,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/v1/folders/{folder_id}/files": {
-            "post": {
-                "description": "Uploads a file to S3 storage and saves the file details in the database. It also updates the folder size cache in there is no transaction",
-                "consumes": [
-                    "multipart/form-data"
-                ],
+            },
+            "patch": {
+                "description": "Changes the name of a specified folder, the name must be unique among its siblings",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "file"
+                    "folder"
                 ],
-                "summary": "Upload a file",
+                "summary": "Rename a folder",
                 "parameters": [
                     {
                         "type": "integer",
@@ -208,70 +295,47 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "file",
-                        "description": "File to upload",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
+                        "description": "New folder name",
+                        "name": "renameFolder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RenameRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "File successfully uploaded"
+                    "200": {
+                        "description": "Folder successfully renamed",
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid input parameters or file upload failed",
+                        "description": "Invalid folder_id, request body or name",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Folder not found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/v1/folders/{folder_id}/files/{file_id}": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "file"
-                ],
-                "summary": "Get a file",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Folder ID",
-                        "name": "folder_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "File ID",
-                        "name": "file_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "header",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
                     },
-                    "400": {
-                        "description": "Invalid file_id",
+                    "409": {
+                        "description": "Folder with the same name already exists",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -283,69 +347,26 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes a file from the specified folder by file ID.",
+            }
+        },
+        "/v1/folders/{folder_id}/children": {
+            "get": {
+                "description": "Returns subfolders and files of the folder page by page, subfolders are always listed before files. The breadcrumbs and the path of the folder are returned with every page. Pass next_cursor of the response to get the next page with the same sort and order.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "file"
+                    "folder"
                 ],
-                "summary": "Remove a file",
+                "summary": "List folder content",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Folder ID",
-                        "name": "folder_id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "File ID",
-                        "name": "file_id",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "integer",
                         "description": "User ID",
                         "name": "user_id",
                         "in": "header",
                         "required": true
-                    }
-                ],
-                "responses": {
-                    "204": {
-                        "description": "No Content"
-                    },
-                    "400": {
-                        "description": "Invalid folder_id or file_id",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
-                        "schema": {
-                            "$ref": "#/definitions/api.ErrorResponse"
-                        }
-                    }
-                }
-            }
-        },
-        "/v1/folders/{folder_id}/files/{file_id}/move": {
-            "put": {
-                "description": "Moves a specified file to a new folder based on provided folder ID.",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "file"
-                ],
-                "summary": "Move a file",
-                "parameters": [
                     {
                         "type": "integer",
                         "description": "Folder ID",
@@ -354,35 +375,67 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "File ID",
-                        "name": "file_id",
-                        "in": "path",
-                        "required": true
+                        "enum": [
+                            "name",
+                            "size",
+                            "created_at"
+                        ],
+                        "type": "string",
+                        "default": "name",
+                        "description": "Sort key",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "folder",
+                            "file"
+                        ],
+                        "type": "string",
+                        "description": "List only folders or only files",
+                        "name": "type",
+                        "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "User ID",
-                        "name": "user_id",
-                        "in": "header",
-                        "required": true
+                        "default": 100,
+                        "description": "Page size, up to 1000",
+                        "name": "limit",
+                        "in": "query"
                     },
                     {
-                        "description": "Request payload containing the new folder ID",
-                        "name": "moveFile",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/api.MoveFileRequest"
-                        }
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "File successfully moved"
+                        "description": "Page of the folder content",
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderListResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid input parameters",
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -396,16 +449,16 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/folders/{folder_id}/move": {
-            "put": {
-                "description": "Changes the parent folder of a specified folder and updates the size calculations",
+        "/v1/folders/{folder_id}/copy": {
+            "post": {
+                "description": "Copies a specified folder with all subfolders and files into the new parent folder, the content of files is shared with the source",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "folder"
                 ],
-                "summary": "Move a folder",
+                "summary": "Copy a folder",
                 "parameters": [
                     {
                         "type": "integer",
@@ -423,43 +476,84 @@ const docTemplate = `{
                     },
                     {
                         "description": "New parent folder ID",
-                        "name": "moveFolder",
+                        "name": "copyFolder",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/api.MoveFolderRequest"
+                            "$ref": "#/definitions/api.CopyFolderRequest"
                         }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Folder successfully moved"
+                    "201": {
+                        "description": "Folder successfully copied",
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderResponse"
+                        }
                     },
                     "400": {
-                        "description": "Invalid folder_id or request body",
+                        "description": "Invalid folder_id, request body or the folder is copied into itself",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Folder with the same name already exists in the new parent folder",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "413": {
+                        "description": "Copy is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/v1/folders/{folder_id}/transaction/start": {
+        "/v1/folders/{folder_id}/files": {
             "post": {
-                "description": "Initiates a new transaction for the specified folder",
+                "description": "Uploads a file to the file storage and saves the file details in the database. It also updates the folder size cache. A file uploaded in a transaction is added to the folder sizes when the transaction completes, the transaction has to be pending or resumed and started for the folder",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "file"
                 ],
-                "summary": "Start a new transaction",
+                "summary": "Upload a file",
                 "parameters": [
                     {
                         "type": "integer",
@@ -474,48 +568,160 @@ const docTemplate = `{
                         "name": "folder_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "ID of the pending or resumed transaction of the folder to upload the file in",
+                        "name": "transaction_id",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected checksum of the file content, e.g. sha-256=\u003cbase64\u003e",
+                        "name": "Digest",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected base64 encoded MD5 of the file content",
+                        "name": "Content-MD5",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, a new version of the existing file by default",
+                        "name": "on_conflict",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Transaction successfully started",
+                        "description": "File successfully uploaded",
                         "schema": {
-                            "$ref": "#/definitions/api.TransactionStartResponse"
+                            "$ref": "#/definitions/api.FileResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid folder_id",
+                        "description": "Invalid input parameters, file upload failed or checksum mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "File with the same name already exists or transaction is neither pending nor resumed",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "413": {
+                        "description": "File is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
                     }
                 }
             }
         },
-        "/v1/folders/{folder_id}/transaction/{transaction_id}/complete": {
-            "put": {
-                "description": "Completes the specified transaction by updating its status to \"completed\" and updating folder sizes",
+        "/v1/folders/{folder_id}/files/{file_id}": {
+            "get": {
+                "description": "Returns metadata of the file with its breadcrumbs and path, use the content endpoint to download the file itself.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "file"
                 ],
-                "summary": "Complete a transaction",
+                "summary": "Get a file",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "file_id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "User ID",
                         "name": "user_id",
                         "in": "header",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File metadata",
+                        "schema": {
+                            "$ref": "#/definitions/api.FileResponse"
+                        }
                     },
+                    "400": {
+                        "description": "Invalid file_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Moves a file from the specified folder to the trash by file ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "Remove a file",
+                "parameters": [
                     {
                         "type": "integer",
                         "description": "Folder ID",
@@ -525,24 +731,31 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "transaction_id",
+                        "description": "File ID",
+                        "name": "file_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Transaction successfully completed"
+                    "204": {
+                        "description": "No Content"
                     },
                     "400": {
-                        "description": "Invalid transaction_id",
+                        "description": "Invalid folder_id or file_id",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
                     "404": {
-                        "description": "Transaction not found",
+                        "description": "File not found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
@@ -554,19 +767,31 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/v1/folders/{folder_id}/transaction/{transaction_id}/stop": {
-            "put": {
-                "description": "Stops the specified transaction by updating its status to \"failed\"",
+            },
+            "patch": {
+                "description": "Changes the name of a specified file, the name must be unique in the folder",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "transaction"
+                    "file"
                 ],
-                "summary": "Stop a transaction",
+                "summary": "Rename a file",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "file_id",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
                         "description": "User ID",
@@ -574,6 +799,72 @@ const docTemplate = `{
                         "in": "header",
                         "required": true
                     },
+                    {
+                        "description": "New file name",
+                        "name": "renameFile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.RenameRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File successfully renamed",
+                        "schema": {
+                            "$ref": "#/definitions/api.FileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input parameters or name",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "File with the same name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/files/{file_id}/content": {
+            "get": {
+                "description": "Streams the file content. Supports partial downloads with the Range header\nand conditional requests with If-None-Match, If-Modified-Since and If-Range headers.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "Download a file",
+                "parameters": [
                     {
                         "type": "integer",
                         "description": "Folder ID",
@@ -583,75 +874,2540 @@ const docTemplate = `{
                     },
                     {
                         "type": "integer",
-                        "description": "Transaction ID",
-                        "name": "transaction_id",
+                        "description": "File ID",
+                        "name": "file_id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Requested byte ranges, e.g. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached file",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the cached file",
+                        "name": "If-Modified-Since",
+                        "in": "header"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Transaction successfully stopped"
+                        "description": "File content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested part of the file content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "File is not modified"
                     },
                     "400": {
-                        "description": "Invalid transaction_id",
+                        "description": "Invalid folder_id or file_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     },
+                    "416": {
+                        "description": "Requested range not satisfiable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "500": {
-                        "description": "Failed to stop transaction",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/api.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "api.ErrorResponse": {
-            "type": "object"
-        },
-        "api.MoveFileRequest": {
-            "type": "object",
-            "properties": {
-                "new_folder_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "api.MoveFolderRequest": {
-            "type": "object",
-            "properties": {
-                "new_folder_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "api.NewFolderResponse": {
-            "type": "object",
-            "properties": {
-                "folder_id": {
-                    "type": "integer"
-                }
-            }
-        },
-        "api.RequestData": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
-                },
-                "parent_folder_id": {
-                    "type": "integer"
-                }
-            }
         },
-        "api.Size": {
-            "type": "object",
-            "properties": {
-                "name": {
+        "/v1/folders/{folder_id}/files/{file_id}/copy": {
+            "post": {
+                "description": "Copies a specified file into the folder based on provided folder ID, the content is shared with the source file",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "Copy a file",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "file_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Request payload containing the destination folder ID",
+                        "name": "copyFile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.CopyFileRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "File successfully copied",
+                        "schema": {
+                            "$ref": "#/definitions/api.FileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input parameters",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File or folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "File with the same name already exists in the folder",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Copy is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/files/{file_id}/move": {
+            "put": {
+                "description": "Moves a specified file to a new folder based on provided folder ID.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "Move a file",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "file_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Request payload containing the new folder ID",
+                        "name": "moveFile",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MoveFileRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File successfully moved"
+                    },
+                    "400": {
+                        "description": "Invalid input parameters",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File or folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "File with the same name already exists in the new folder",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Moved item is larger than the folder size limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Folder size limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/files/{file_id}/versions": {
+            "get": {
+                "description": "Returns all kept versions of the file, newest first. The first one is the current content of the file.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "List file versions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "file_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "File versions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.FileVersionResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or file_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/files/{file_id}/versions/{version}/content": {
+            "get": {
+                "description": "Streams the content of the file version. Supports the same Range and conditional headers as the file download.",
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "Download a file version",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "file_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version number",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Requested byte ranges, e.g. bytes=0-1023",
+                        "name": "Range",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ETag of the cached version",
+                        "name": "If-None-Match",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Date of the cached version",
+                        "name": "If-Modified-Since",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Version content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "206": {
+                        "description": "Requested part of the version content",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Version is not modified"
+                    },
+                    "400": {
+                        "description": "Invalid folder_id, file_id or version",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File or version not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "416": {
+                        "description": "Requested range not satisfiable",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/files/{file_id}/versions/{version}/restore": {
+            "post": {
+                "description": "Uploads the content of the version as the new current version of the file, so no version is lost.\nRestoring the current version changes nothing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "file"
+                ],
+                "summary": "Restore a file version",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "File ID",
+                        "name": "file_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Version number",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Version successfully restored",
+                        "schema": {
+                            "$ref": "#/definitions/api.FileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder_id, file_id or version",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "File or version not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Version is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/move": {
+            "put": {
+                "description": "Changes the parent folder of a specified folder and updates the size calculations",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folder"
+                ],
+                "summary": "Move a folder",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "New parent folder ID",
+                        "name": "moveFolder",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.MoveFolderRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Folder successfully moved"
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or request body, or the root folder is moved",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Folder with the same name already exists in the new parent folder",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Moved item is larger than the folder size limit",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Folder size limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/size_limit": {
+            "put": {
+                "description": "Limits the size of the folder with all its content, null removes the limit. Uploads, moves, copies and restores\nwhich would push the folder or any of its parent folders over the limit are rejected. A limit below the current size\ndoes not delete anything, but the folder can't grow anymore.",
+                "tags": [
+                    "folder"
+                ],
+                "summary": "Set the folder size limit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Size limit",
+                        "name": "sizeLimit",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderSizeLimitRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Limit successfully changed"
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or request body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/transaction/start": {
+            "post": {
+                "description": "Initiates a new transaction for the specified folder. A transaction which is not completed or stopped until it expires is rolled back with all its files",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Start a new transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Time the transaction stays pending, e.g. 30m, the configured one by default",
+                        "name": "ttl",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Transaction successfully started",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionStartResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or ttl",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/transaction/{transaction_id}/complete": {
+            "put": {
+                "description": "Completes the specified pending or resumed transaction of the folder by updating its status to \"completed\", files uploaded in it are added to the folder sizes",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Complete a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transaction_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction successfully completed"
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or transaction_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transaction is neither pending nor resumed",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Folder size limit exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/transaction/{transaction_id}/pause": {
+            "put": {
+                "description": "Pauses the specified pending or resumed transaction of the folder. Files can't be uploaded in a paused transaction and it doesn't expire until it is resumed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Pause a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transaction_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction successfully paused",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or transaction_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transaction is neither pending nor resumed",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/transaction/{transaction_id}/resume": {
+            "put": {
+                "description": "Moves the specified paused transaction of the folder to resumed, it accepts uploads again and its expiry moves by the time it was paused for. Returns the files already uploaded in it, so the client can continue with the rest",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Resume a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transaction_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction successfully resumed",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or transaction_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transaction is not paused",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/transaction/{transaction_id}/stop": {
+            "put": {
+                "description": "Rolls back the specified pending, paused or resumed transaction of the folder: its status is updated to \"failed\", files uploaded in it are deleted with the stored content and removed from the folder sizes. Stopping it again does nothing",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Stop a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transaction_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction successfully stopped"
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or transaction_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Transaction is completed",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to stop transaction",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/tree": {
+            "get": {
+                "description": "Returns the folder with its subfolders nested down to the depth, like 'du -d N'. Sizes are in bytes and file counts include all subfolders, also the ones below the depth. Files of the folders above the depth are added on request. The tree is streamed while it is read from the database.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "folder"
+                ],
+                "summary": "Get folder tree",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "Number of levels below the folder, 0 for the folder alone",
+                        "name": "depth",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": false,
+                        "description": "Add files of the folders",
+                        "name": "files",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Folder tree",
+                        "schema": {
+                            "$ref": "#/definitions/api.TreeFolderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/uploads": {
+            "post": {
+                "description": "Creates a tus upload. The file name is taken from the \"filename\" key of Upload-Metadata.\nContent is sent with PATCH requests to the returned Location.",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Create a resumable upload",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Size of the whole file in bytes",
+                        "name": "Upload-Length",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Comma separated key and base64 encoded value pairs",
+                        "name": "Upload-Metadata",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy applied when the upload is finished, a new version of the existing file by default",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Upload created",
+                        "headers": {
+                            "Location": {
+                                "type": "string",
+                                "description": "URL of the created upload"
+                            },
+                            "Upload-Expires": {
+                                "type": "string",
+                                "description": "Time when the unfinished upload expires"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid folder_id, file name or upload headers",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Empty file with the same name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Unsupported protocol version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "File is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/folders/{folder_id}/versioning": {
+            "put": {
+                "description": "Sets how many versions, including the current one, are kept for each file directly in the folder.\nnull resets the limit to the configured default. Files over the new limit lose their oldest versions with the next upload.",
+                "tags": [
+                    "folder"
+                ],
+                "summary": "Set the folder version limit",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder ID",
+                        "name": "folder_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Version limit",
+                        "name": "versioning",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderVersioningRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Limit successfully changed"
+                    },
+                    "400": {
+                        "description": "Invalid folder_id or request body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/paths/{path}": {
+            "get": {
+                "description": "Returns the folder or the file at the slash separated path under the root folder of the user. A folder is preferred to a file with the same name, names match ignoring case if it is enabled in the user settings.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "path"
+                ],
+                "summary": "Resolve a path",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slash separated path, e.g. docs/2024/report.pdf",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Folder or file at the path",
+                        "schema": {
+                            "$ref": "#/definitions/api.PathResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid path",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Path not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Path matches several items ignoring case",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Returns the folder at the slash separated path under the root folder of the user, missing folders on the way are created like with \"mkdir -p\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "path"
+                ],
+                "summary": "Create folders of a path",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slash separated path, e.g. docs/2024",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Folder already exists",
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderResponse"
+                        }
+                    },
+                    "201": {
+                        "description": "Folder created",
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid path",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Path is ambiguous or a file has the name of a folder",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Uploads a file with the last name of the slash separated path into the folder of the path under the root folder of the user, missing folders are created together with the file, a rejected upload leaves none of them. With case insensitive paths an existing file is matched ignoring case.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "path"
+                ],
+                "summary": "Upload a file to a path",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Slash separated path of the file, e.g. docs/2024/report.pdf",
+                        "name": "path",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File to upload",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected checksum of the file content, e.g. sha-256=\u003cbase64\u003e",
+                        "name": "Digest",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Expected base64 encoded MD5 of the file content",
+                        "name": "Content-MD5",
+                        "in": "header"
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, a new version of the existing file by default",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "File successfully uploaded",
+                        "schema": {
+                            "$ref": "#/definitions/api.FileResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid path, file upload failed or checksum mismatch",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Path is ambiguous or the name already exists",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "File is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/quota": {
+            "get": {
+                "description": "Returns the storage quota of the user and how much of it is used. The usage is the size of the root folder,\nfiles uploaded in unfinished transactions are reported as pending.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "quota"
+                ],
+                "summary": "Get the storage quota",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/settings": {
+            "get": {
+                "description": "Returns the settings of the user.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Get the user settings",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User settings",
+                        "schema": {
+                            "$ref": "#/definitions/api.SettingsResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Changes the settings of the user, settings missing in the request keep their values.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "settings"
+                ],
+                "summary": "Update the user settings",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Changed settings",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.UpdateSettingsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Settings successfully changed",
+                        "schema": {
+                            "$ref": "#/definitions/api.SettingsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request body",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/storage/stats": {
+            "get": {
+                "description": "Returns logical size of all files (as it is reported for folders) and physical size of the stored objects, files with the same content share one object. Covers files of all users, available to admins only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "storage"
+                ],
+                "summary": "Get storage statistics",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Storage statistics",
+                        "schema": {
+                            "$ref": "#/definitions/api.StorageStatsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Not an admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/transactions": {
+            "get": {
+                "description": "Returns upload transactions of the user, the newest first, with the number and the size of files uploaded in them so far",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "List transactions",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "pending",
+                            "paused",
+                            "resumed",
+                            "completed",
+                            "failed"
+                        ],
+                        "type": "string",
+                        "description": "Status of the transactions",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Folder the transactions were started for",
+                        "name": "folder_id",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transactions",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.TransactionResponse"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid status or folder_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/transactions/{transaction_id}": {
+            "get": {
+                "description": "Returns the status of the upload transaction of the user, the number and the size of files uploaded in it so far and the files themselves in the upload order",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "transaction"
+                ],
+                "summary": "Get a transaction",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Transaction ID",
+                        "name": "transaction_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Transaction with its files",
+                        "schema": {
+                            "$ref": "#/definitions/api.TransactionDetailResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid transaction_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Transaction not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trash": {
+            "get": {
+                "description": "Returns folders and files deleted by the user, the newest first. Content of a deleted folder is not listed separately, it is restored and deleted permanently together with the folder",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "List the trash",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Deleted items",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/api.TrashItemResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trash/{trash_id}": {
+            "delete": {
+                "description": "Permanently deletes a deleted folder with all its content or a deleted file, it can't be restored anymore",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Delete a trash item permanently",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Trash item ID",
+                        "name": "trash_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Item permanently deleted"
+                    },
+                    "400": {
+                        "description": "Invalid trash_id",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Trash item not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/trash/{trash_id}/restore": {
+            "post": {
+                "description": "Restores a deleted folder with all its content or a deleted file into the folder it was deleted from. If that folder does not exist anymore, the item is restored into parent_folder_id",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "trash"
+                ],
+                "summary": "Restore a deleted item",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Trash item ID",
+                        "name": "trash_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Folder to restore into, if the original one is gone",
+                        "name": "restoreItem",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/api.RestoreItemRequest"
+                        }
+                    },
+                    {
+                        "enum": [
+                            "fail",
+                            "rename",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "description": "Name conflict policy, the configured one by default",
+                        "name": "on_conflict",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Folder successfully restored, a restored file is returned as FileResponse",
+                        "schema": {
+                            "$ref": "#/definitions/api.FolderResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid trash_id, request body or name",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Trash item or parent folder not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Item with the same name already exists or the original folder is gone and parent_folder_id is not set",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "Restored item is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/uploads": {
+            "options": {
+                "description": "Returns supported tus protocol version and extensions",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Resumable upload capabilities",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content",
+                        "headers": {
+                            "Tus-Extension": {
+                                "type": "string",
+                                "description": "Supported protocol extensions"
+                            },
+                            "Tus-Version": {
+                                "type": "string",
+                                "description": "Supported protocol versions"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/uploads/{upload_id}": {
+            "delete": {
+                "description": "Stops the tus upload and removes all received chunks",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Terminate a resumable upload",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "upload_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Upload terminated"
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Upload expired",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Unsupported protocol version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "head": {
+                "description": "Returns the state of the tus upload, the client continues the upload from Upload-Offset",
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Get resumable upload offset",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "upload_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Upload state",
+                        "headers": {
+                            "Upload-Length": {
+                                "type": "int",
+                                "description": "Size of the whole file in bytes"
+                            },
+                            "Upload-Offset": {
+                                "type": "int",
+                                "description": "Number of received bytes"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Upload expired",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Unsupported protocol version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "patch": {
+                "description": "Appends the request body to the tus upload at Upload-Offset.\nWhen the last byte is received, the upload is saved as a file in the folder.",
+                "consumes": [
+                    "application/offset+octet-stream"
+                ],
+                "tags": [
+                    "upload"
+                ],
+                "summary": "Upload a chunk",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Upload ID",
+                        "name": "upload_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Protocol version, 1.0.0",
+                        "name": "Tus-Resumable",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Offset of the chunk, must be equal to the current upload offset",
+                        "name": "Upload-Offset",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Chunk received",
+                        "headers": {
+                            "Upload-Offset": {
+                                "type": "int",
+                                "description": "Number of received bytes"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid Upload-Offset",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Upload not found",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Upload-Offset does not match the upload offset",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Upload expired",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "412": {
+                        "description": "Unsupported protocol version",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "413": {
+                        "description": "Chunk exceeds the upload length or the file is larger than the storage quota",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "415": {
+                        "description": "Invalid Content-Type",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/api.ErrorResponse"
+                        }
+                    },
+                    "507": {
+                        "description": "Storage quota exceeded",
+                        "schema": {
+                            "$ref": "#/definitions/api.QuotaErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "api.BreadcrumbResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.CopyFileRequest": {
+            "type": "object",
+            "properties": {
+                "new_folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.CopyFolderRequest": {
+            "type": "object",
+            "properties": {
+                "new_folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.FileResponse": {
+            "type": "object",
+            "properties": {
+                "breadcrumbs": {
+                    "description": "Breadcrumbs are the parent folders of the item, the root folder first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BreadcrumbResponse"
+                    }
+                },
+                "checksum": {
+                    "description": "Checksum is hex encoded SHA-256 of the content, empty for files uploaded before checksums were calculated",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "folder_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "md5": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path is the slash separated path of the item under the root folder, \"/\" for the root folder itself",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "description": "Version is the number of the current version, it grows with every replacing upload",
+                    "type": "integer"
+                },
+                "versions_size": {
+                    "description": "VersionsSize is the total size of the previous versions kept for the file",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.FileVersionResponse": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "description": "Checksum is hex encoded SHA-256 of the content, empty for versions uploaded before checksums were calculated",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "current": {
+                    "description": "Current is true for the version which is the content of the file now",
+                    "type": "boolean"
+                },
+                "md5": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.FolderListResponse": {
+            "type": "object",
+            "properties": {
+                "breadcrumbs": {
+                    "description": "Breadcrumbs are the parent folders of the item, the root folder first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BreadcrumbResponse"
+                    }
+                },
+                "folder": {
+                    "$ref": "#/definitions/api.ListItem"
+                },
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.ListItem"
+                    }
+                },
+                "next_cursor": {
+                    "description": "NextCursor is empty on the last page",
+                    "type": "string"
+                },
+                "path": {
+                    "description": "Path is the slash separated path of the item under the root folder, \"/\" for the root folder itself",
+                    "type": "string"
+                }
+            }
+        },
+        "api.FolderResponse": {
+            "type": "object",
+            "properties": {
+                "breadcrumbs": {
+                    "description": "Breadcrumbs are the parent folders of the item, the root folder first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.BreadcrumbResponse"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_size": {
+                    "description": "MaxSize is the size limit of the folder, it is not set for unlimited folders",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_folder_id": {
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "Path is the slash separated path of the item under the root folder, \"/\" for the root folder itself",
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.FolderSizeLimitRequest": {
+            "type": "object",
+            "properties": {
+                "max_size": {
+                    "description": "MaxSize is the max size of the folder in bytes, null removes the limit",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.FolderVersioningRequest": {
+            "type": "object",
+            "properties": {
+                "max_versions": {
+                    "description": "MaxVersions is the number of kept versions, null for the configured default",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.ListItem": {
+            "type": "object",
+            "properties": {
+                "checksum": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "file_count": {
+                    "description": "FileCount and FolderCount are numbers of direct children, set only for folders",
+                    "type": "integer"
+                },
+                "folder_count": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "max_size": {
+                    "description": "MaxSize is the size limit of a folder, it is not set for unlimited folders",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.MoveFileRequest": {
+            "type": "object",
+            "properties": {
+                "new_folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.MoveFolderRequest": {
+            "type": "object",
+            "properties": {
+                "new_folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.NewFolderResponse": {
+            "type": "object",
+            "properties": {
+                "folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.PathResponse": {
+            "type": "object",
+            "properties": {
+                "file": {
+                    "$ref": "#/definitions/api.FileResponse"
+                },
+                "folder": {
+                    "$ref": "#/definitions/api.FolderResponse"
+                },
+                "type": {
+                    "description": "Type is either folder or file",
+                    "type": "string"
+                }
+            }
+        },
+        "api.QuotaErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "folder_id": {
+                    "description": "FolderID is the folder which would grow over its limit, it is not set when the storage quota is exceeded",
+                    "type": "integer"
+                },
+                "quota": {
+                    "type": "integer"
+                },
+                "requested": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.QuotaResponse": {
+            "type": "object",
+            "properties": {
+                "available": {
+                    "description": "Available is the number of bytes which can be stored yet, null if the storage is not limited",
+                    "type": "integer"
+                },
+                "pending": {
+                    "description": "Pending is the size of files uploaded in unfinished transactions",
+                    "type": "integer"
+                },
+                "quota": {
+                    "description": "Quota is the max number of stored bytes, null if the storage is not limited",
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.RenameRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.RequestData": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "parent_folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.RestoreItemRequest": {
+            "type": "object",
+            "properties": {
+                "parent_folder_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SetUserQuotaRequest": {
+            "type": "object",
+            "properties": {
+                "quota": {
+                    "description": "Quota is the max number of stored bytes, null removes the limit",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.SettingsResponse": {
+            "type": "object",
+            "properties": {
+                "case_insensitive_paths": {
+                    "description": "CaseInsensitivePaths makes paths match folder and file names ignoring case",
+                    "type": "boolean"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.Size": {
+            "type": "object",
+            "properties": {
+                "name": {
                     "type": "string"
                 },
                 "size": {
@@ -659,13 +3415,198 @@ const docTemplate = `{
                 }
             }
         },
+        "api.StorageStatsResponse": {
+            "type": "object",
+            "properties": {
+                "files": {
+                    "type": "integer"
+                },
+                "logical_size": {
+                    "description": "LogicalSize is the total size of all files in bytes",
+                    "type": "integer"
+                },
+                "objects": {
+                    "type": "integer"
+                },
+                "physical_size": {
+                    "description": "PhysicalSize is the total size of all stored objects in bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.TransactionDetailResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is the time the pending or resumed transaction is rolled back at, it moves by the pause when a paused one resumes",
+                    "type": "string"
+                },
+                "file_count": {
+                    "description": "FileCount and TotalSize are the number and the size with versions of the files uploaded so far,\nfiles in the trash are not counted",
+                    "type": "integer"
+                },
+                "files": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.FileResponse"
+                    }
+                },
+                "folder_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "paused_at": {
+                    "description": "PausedAt is the time the paused transaction was paused at",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_size": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "api.TransactionResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is the time the pending or resumed transaction is rolled back at, it moves by the pause when a paused one resumes",
+                    "type": "string"
+                },
+                "file_count": {
+                    "description": "FileCount and TotalSize are the number and the size with versions of the files uploaded so far,\nfiles in the trash are not counted",
+                    "type": "integer"
+                },
+                "folder_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "paused_at": {
+                    "description": "PausedAt is the time the paused transaction was paused at",
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "total_size": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
         "api.TransactionStartResponse": {
             "type": "object",
             "properties": {
+                "expires_at": {
+                    "description": "ExpiresAt is the time the transaction is rolled back at unless it is completed or stopped before",
+                    "type": "string"
+                },
                 "transaction_id": {
                     "type": "integer"
                 }
             }
+        },
+        "api.TrashItemResponse": {
+            "type": "object",
+            "properties": {
+                "deleted_at": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "description": "ExpiresAt is the time after which the item is deleted permanently",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "item_id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "parent_folder_id": {
+                    "description": "ParentFolderID is the folder the item was deleted from",
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "Type is folder or file",
+                    "type": "string"
+                }
+            }
+        },
+        "api.TreeFileResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "type": "integer"
+                }
+            }
+        },
+        "api.TreeFolderResponse": {
+            "type": "object",
+            "properties": {
+                "file_count": {
+                    "description": "FileCount is the number of files in the folder with all its subfolders",
+                    "type": "integer"
+                },
+                "files": {
+                    "description": "Files are set only on request, for the folders above the depth",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TreeFileResponse"
+                    }
+                },
+                "folders": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/api.TreeFolderResponse"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "size": {
+                    "description": "Size is the size of the folder with all its content in bytes",
+                    "type": "integer"
+                }
+            }
+        },
+        "api.UpdateSettingsRequest": {
+            "type": "object",
+            "properties": {
+                "case_insensitive_paths": {
+                    "type": "boolean"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,41 @@
+package checksum
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+)
+
+// Reader calculates SHA-256 and MD5 checksums of the content while it is read,
+// so a streamed file is hashed without reading it twice
+type Reader struct {
+	reader io.Reader
+	sha256 hash.Hash
+	md5    hash.Hash
+}
+
+// NewReader wraps the reader with checksums calculation
+func NewReader(r io.Reader) *Reader {
+	c := &Reader{
+		sha256: sha256.New(),
+		md5:    md5.New(),
+	}
+	c.reader = io.TeeReader(r, io.MultiWriter(c.sha256, c.md5))
+	return c
+}
+
+func (c *Reader) Read(p []byte) (int, error) {
+	return c.reader.Read(p)
+}
+
+// SHA256 returns hex encoded SHA-256 checksum of the content read so far
+func (c *Reader) SHA256() string {
+	return hex.EncodeToString(c.sha256.Sum(nil))
+}
+
+// MD5 returns hex encoded MD5 checksum of the content read so far
+func (c *Reader) MD5() string {
+	return hex.EncodeToString(c.md5.Sum(nil))
+}
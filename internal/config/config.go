@@ -3,6 +3,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/saur4ig/file-storage/internal/models"
 )
 
 const (
@@ -14,6 +19,48 @@ const (
 	DB_NAME     = "DB_NAME"
 	REDIS_HOST  = "REDIS_HOST"
 	REDIS_PORT  = "REDIS_PORT"
+
+	// names of optional envs
+	STORAGE_TYPE      = "STORAGE_TYPE"
+	STORAGE_PATH      = "STORAGE_PATH"
+	UPLOAD_EXPIRATION = "UPLOAD_EXPIRATION"
+	TRASH_RETENTION   = "TRASH_RETENTION"
+	MAX_FILE_VERSIONS = "MAX_FILE_VERSIONS"
+	// TRANSACTION_TTL is how long an upload transaction stays pending unless it requests another time,
+	// TRANSACTION_MAX_TTL is the longest time it may request
+	TRANSACTION_TTL     = "TRANSACTION_TTL"
+	TRANSACTION_MAX_TTL = "TRANSACTION_MAX_TTL"
+	// RECONCILE_INTERVAL is how often folder sizes are recalculated from their content
+	RECONCILE_INTERVAL = "RECONCILE_INTERVAL"
+	// ADMIN_USER_IDS is a comma separated list of users allowed to use the admin endpoints
+	ADMIN_USER_IDS = "ADMIN_USER_IDS"
+	// NAME_CONFLICT_POLICY is one of fail, rename, replace
+	NAME_CONFLICT_POLICY = "NAME_CONFLICT_POLICY"
+
+	// names of envs required by the s3 storage
+	S3_BUCKET     = "S3_BUCKET"
+	S3_REGION     = "S3_REGION"
+	S3_ENDPOINT   = "S3_ENDPOINT"
+	S3_ACCESS_KEY = "S3_ACCESS_KEY"
+	S3_SECRET_KEY = "S3_SECRET_KEY"
+	S3_USE_SSL    = "S3_USE_SSL"
+)
+
+const (
+	// supported file storage backends
+	StorageTypeLocal = "local"
+	StorageTypeS3    = "s3"
+
+	defaultStoragePath = "data"
+	defaultS3Endpoint  = "s3.amazonaws.com"
+	defaultS3Region    = "us-east-1"
+
+	defaultUploadExpiration  = 24 * time.Hour
+	defaultTrashRetention    = 30 * 24 * time.Hour
+	defaultMaxFileVersions   = 10
+	defaultReconcileInterval = 6 * time.Hour
+	defaultTransactionTTL    = time.Hour
+	defaultTransactionMaxTTL = 24 * time.Hour
 )
 
 type DbConfig struct {
@@ -29,9 +76,81 @@ type CacheConfig struct {
 	Port string
 }
 
+// StorageConfig describes where uploaded file contents are kept
+type StorageConfig struct {
+	Type string
+	// Path is the root directory of the local storage
+	Path string
+	S3   S3Config
+}
+
+// S3Config contains connection settings of the S3 compatible storage
+type S3Config struct {
+	Bucket    string
+	Region    string
+	Endpoint  string
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// UploadConfig contains settings of the resumable uploads
+type UploadConfig struct {
+	// Expiration is the time an unfinished upload is kept after its last chunk
+	Expiration time.Duration
+}
+
+// TransactionConfig contains settings of the upload transactions
+type TransactionConfig struct {
+	// TTL is the time a transaction stays pending before it is rolled back, unless it requests another time
+	TTL time.Duration
+	// MaxTTL is the longest time a transaction may request
+	MaxTTL time.Duration
+}
+
+// TrashConfig contains settings of the trash
+type TrashConfig struct {
+	// Retention is the time a deleted item is kept in the trash before it is deleted permanently
+	Retention time.Duration
+}
+
+// VersionsConfig contains settings of the file version history
+type VersionsConfig struct {
+	// MaxVersions is the number of versions, including the current one, kept for a file
+	// unless its folder sets another limit
+	MaxVersions int
+}
+
+// ReconcileConfig contains settings of the folder size reconciliation
+type ReconcileConfig struct {
+	// Interval is the time between runs of the reconciliation in the background
+	Interval time.Duration
+}
+
+// AdminConfig contains settings of the admin endpoints
+type AdminConfig struct {
+	// UserIDs are the users allowed to use the admin endpoints
+	UserIDs []int
+}
+
+// NamingConfig contains settings of file and folder names
+type NamingConfig struct {
+	// ConflictPolicy is applied when a request puts an item into a folder with the same name already taken
+	// and it does not choose the policy itself
+	ConflictPolicy string
+}
+
 type Config struct {
-	DB    DbConfig
-	Cache CacheConfig
+	DB          DbConfig
+	Cache       CacheConfig
+	Storage     StorageConfig
+	Upload      UploadConfig
+	Transaction TransactionConfig
+	Trash       TrashConfig
+	Versions    VersionsConfig
+	Naming      NamingConfig
+	Admin       AdminConfig
+	Reconcile   ReconcileConfig
 }
 
 func LoadConfig() (*Config, error) {
@@ -40,6 +159,65 @@ func LoadConfig() (*Config, error) {
 		return nil, err
 	}
 
+	storageType := getEnv(STORAGE_TYPE, StorageTypeLocal)
+	switch storageType {
+	case StorageTypeLocal:
+	case StorageTypeS3:
+		if err = checkEnvVariables(S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported %s: %s", STORAGE_TYPE, storageType)
+	}
+
+	useSSL, err := strconv.ParseBool(getEnv(S3_USE_SSL, "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", S3_USE_SSL, err)
+	}
+
+	uploadExpiration, err := getDurationEnv(UPLOAD_EXPIRATION, defaultUploadExpiration)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionTTL, err := getDurationEnv(TRANSACTION_TTL, defaultTransactionTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	transactionMaxTTL, err := getDurationEnv(TRANSACTION_MAX_TTL, defaultTransactionMaxTTL)
+	if err != nil {
+		return nil, err
+	}
+	if transactionTTL > transactionMaxTTL {
+		return nil, fmt.Errorf("%s is longer than %s", TRANSACTION_TTL, TRANSACTION_MAX_TTL)
+	}
+
+	trashRetention, err := getDurationEnv(TRASH_RETENTION, defaultTrashRetention)
+	if err != nil {
+		return nil, err
+	}
+
+	maxFileVersions, err := getPositiveIntEnv(MAX_FILE_VERSIONS, defaultMaxFileVersions)
+	if err != nil {
+		return nil, err
+	}
+
+	reconcileInterval, err := getDurationEnv(RECONCILE_INTERVAL, defaultReconcileInterval)
+	if err != nil {
+		return nil, err
+	}
+
+	adminUserIDs, err := getIntListEnv(ADMIN_USER_IDS)
+	if err != nil {
+		return nil, err
+	}
+
+	conflictPolicy := getEnv(NAME_CONFLICT_POLICY, models.ConflictFail)
+	if !models.IsConflictPolicy(conflictPolicy) {
+		return nil, fmt.Errorf("unsupported %s: %s", NAME_CONFLICT_POLICY, conflictPolicy)
+	}
+
 	return &Config{
 		DB: DbConfig{
 			Host:     os.Getenv(DB_HOST),
@@ -52,12 +230,50 @@ func LoadConfig() (*Config, error) {
 			Host: os.Getenv(REDIS_HOST),
 			Port: os.Getenv(REDIS_PORT),
 		},
+		Storage: StorageConfig{
+			Type: storageType,
+			Path: getEnv(STORAGE_PATH, defaultStoragePath),
+			S3: S3Config{
+				Bucket:    os.Getenv(S3_BUCKET),
+				Region:    getEnv(S3_REGION, defaultS3Region),
+				Endpoint:  getEnv(S3_ENDPOINT, defaultS3Endpoint),
+				AccessKey: os.Getenv(S3_ACCESS_KEY),
+				SecretKey: os.Getenv(S3_SECRET_KEY),
+				UseSSL:    useSSL,
+			},
+		},
+		Upload: UploadConfig{
+			Expiration: uploadExpiration,
+		},
+		Transaction: TransactionConfig{
+			TTL:    transactionTTL,
+			MaxTTL: transactionMaxTTL,
+		},
+		Trash: TrashConfig{
+			Retention: trashRetention,
+		},
+		Versions: VersionsConfig{
+			MaxVersions: maxFileVersions,
+		},
+		Naming: NamingConfig{
+			ConflictPolicy: conflictPolicy,
+		},
+		Admin: AdminConfig{
+			UserIDs: adminUserIDs,
+		},
+		Reconcile: ReconcileConfig{
+			Interval: reconcileInterval,
+		},
 	}, nil
 }
 
 // if at least one env params missing - error
 func checkAllEnvVariables() error {
-	required := []string{DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME, REDIS_HOST, REDIS_PORT}
+	return checkEnvVariables(DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME, REDIS_HOST, REDIS_PORT)
+}
+
+// checks that all provided envs are set
+func checkEnvVariables(required ...string) error {
 	for _, str := range required {
 		if _, ok := os.LookupEnv(str); !ok {
 			return fmt.Errorf("%s is missing", str)
@@ -65,3 +281,57 @@ func checkAllEnvVariables() error {
 	}
 	return nil
 }
+
+// returns the env value or fallback, if env is not set
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// returns the env parsed as a positive duration (e.g. "90m") or fallback, if env is not set
+func getDurationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s", key, value)
+	}
+	return duration, nil
+}
+
+// returns the env parsed as a positive integer or fallback, if env is not set
+func getPositiveIntEnv(key string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid %s: %s", key, value)
+	}
+	return number, nil
+}
+
+// returns the env parsed as a comma separated list of integers, empty if env is not set
+func getIntListEnv(key string) ([]int, error) {
+	var numbers []int
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %s", key, value)
+		}
+		numbers = append(numbers, number)
+	}
+	return numbers, nil
+}
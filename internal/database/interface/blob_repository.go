@@ -0,0 +1,20 @@
+package _interface
+
+import (
+	"database/sql"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// BlobRepository - reference counting of the stored objects shared by files with the same content
+type BlobRepository interface {
+	// AcquireBlob adds a reference to the blob with the checksum of the provided one, the blob is created
+	// if it does not exist yet, returns the storage key of the object which has to be used by the file
+	AcquireBlob(tx *sql.Tx, blob *models.Blob) (storageKey string, err error)
+	// AddBlobReferences adds references to the existing blobs, refs maps checksums to the numbers of new references
+	AddBlobReferences(tx *sql.Tx, refs map[string]int) error
+	// ReleaseBlob removes a reference to the blob, when the last reference is gone the blob is deleted
+	// and the storage key of its object is returned, otherwise the storage key is empty
+	ReleaseBlob(tx *sql.Tx, checksum string) (storageKey string, err error)
+	GetStorageStats() (*models.StorageStats, error)
+}
@@ -6,9 +6,15 @@ import (
 	"github.com/saur4ig/file-storage/internal/models"
 )
 
-// FolderSizeCache - basic representation of the folders size cache
+// FolderSizeCache - basic representation of the folders size cache, a cached size includes files
+// of unfinished upload transactions as soon as they are uploaded, unlike the size in the database
 type FolderSizeCache interface {
 	GetFolderSize(ctx context.Context, folderID int64) (int64, error)
 	SetOrUpdateFolderSize(ctx context.Context, folderID int64, size int64) error
 	GetMultipleFolders(ctx context.Context, keys []string) ([]models.FolderSizeSimplified, error)
+	// DecreaseFolderSizes subtracts the sizes from the cached sizes, folders which are not cached are skipped
+	DecreaseFolderSizes(ctx context.Context, folders []models.FolderSizeSimplified) error
+	// RefreshFolderSizes overwrites the cached sizes which differ from the provided ones
+	// and returns the replaced values, folders which are not cached are skipped
+	RefreshFolderSizes(ctx context.Context, folders []models.FolderSizeSimplified) ([]models.FolderSizeSimplified, error)
 }
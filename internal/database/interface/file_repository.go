@@ -10,6 +10,40 @@ import (
 type FileRepository interface {
 	CreateFile(tx *sql.Tx, file *models.File) error
 	GetFileByID(id int64) (*models.File, error)
+	// LockFile returns the file and locks it until the end of the transaction
+	LockFile(tx *sql.Tx, id int64) (*models.File, error)
+	// UpdateFileContent makes the content of the file its new current version
+	UpdateFileContent(tx *sql.Tx, file *models.File) error
+	// ListFolderFiles returns a page of the files located directly in the folder sorted by opts
+	ListFolderFiles(folderID int64, opts models.ListOptions) ([]models.File, error)
 	DeleteFile(tx *sql.Tx, id int64) error
-	MoveFile(tx *sql.Tx, fileID, newFolderID int64) error
+	// DeleteFolderTreeFiles deletes all files of the folder and its subfolders
+	DeleteFolderTreeFiles(tx *sql.Tx, folderID int64) ([]models.File, error)
+	// CopyFolderTreeFiles copies files of the source folders into their copies
+	CopyFolderTreeFiles(tx *sql.Tx, folders []models.FolderCopy) ([]models.File, error)
+	UpdateFileKey(tx *sql.Tx, id int64, key string) error
+	// TrashFile marks the file as deleted with the trash entry
+	TrashFile(tx *sql.Tx, id, trashID int64) error
+	// TrashFolderFiles marks files of the folders deleted with the trash entry as deleted too
+	TrashFolderFiles(tx *sql.Tx, trashID int64) error
+	// RestoreFile puts the file deleted with the trash entry into the folder under the name
+	RestoreFile(tx *sql.Tx, trashID, folderID int64, name string) error
+	RestoreFolderFiles(tx *sql.Tx, trashID int64) error
+	// DeleteTrashedFiles permanently deletes files of the trash entry
+	DeleteTrashedFiles(tx *sql.Tx, trashID int64) ([]models.File, error)
+	// DeleteTransactionFiles permanently deletes files uploaded in the upload transaction, except the ones in the trash
+	DeleteTransactionFiles(tx *sql.Tx, transactionID int64) ([]models.File, error)
+	// GetTransactionFiles returns files uploaded in the upload transaction
+	GetTransactionFiles(transactionID int64) ([]models.File, error)
+	// GetTransactionFolderSizes returns sizes of files uploaded in the upload transaction summed by their folders
+	GetTransactionFolderSizes(tx *sql.Tx, transactionID int64) ([]models.FolderSizeSimplified, error)
+	// GetFileByName returns the file of the folder with the name and locks it until the end of the transaction
+	GetFileByName(tx *sql.Tx, folderID int64, name string) (*models.File, error)
+	// FindFileByName returns the file of the folder with the name, an exact match is preferred to one ignoring case
+	FindFileByName(folderID int64, name string, caseInsensitive bool) (*models.File, error)
+	// GetNamesWithPrefix returns names of the folder files starting with the prefix
+	GetNamesWithPrefix(tx *sql.Tx, folderID int64, prefix string) ([]string, error)
+	// MoveFile moves the file into the new folder and sets its name there
+	MoveFile(tx *sql.Tx, fileID, newFolderID int64, name string) error
+	RenameFile(tx *sql.Tx, id int64, name string) error
 }
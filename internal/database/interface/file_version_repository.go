@@ -0,0 +1,24 @@
+package _interface
+
+import (
+	"database/sql"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// FileVersionRepository - previous versions of files, the current version is kept in the file itself
+type FileVersionRepository interface {
+	CreateFileVersion(tx *sql.Tx, version *models.FileVersion) error
+	GetFileVersion(fileID int64, version int) (*models.FileVersion, error)
+	// ListFileVersions returns versions of the file, the newest first
+	ListFileVersions(fileID int64) ([]models.FileVersion, error)
+	// DeleteOldFileVersions deletes versions of the file except the newest keep ones and returns the deleted versions
+	DeleteOldFileVersions(tx *sql.Tx, fileID int64, keep int) ([]models.FileVersion, error)
+	// DeleteFileVersions deletes all versions of the files and returns them
+	DeleteFileVersions(tx *sql.Tx, fileIDs []int64) ([]models.FileVersion, error)
+	// CopyFileVersions copies versions of the source file to its copy
+	CopyFileVersions(tx *sql.Tx, sourceID, fileID int64) ([]models.FileVersion, error)
+	// CopyFolderTreeFileVersions copies versions of the files of the source folders to the copied files
+	CopyFolderTreeFileVersions(tx *sql.Tx, folders []models.FolderCopy) ([]models.FileVersion, error)
+	UpdateFileVersionKey(tx *sql.Tx, id int64, key string) error
+}
@@ -8,18 +8,53 @@ import (
 
 // FolderRepository - base functions to work with folders in postgres db
 type FolderRepository interface {
-	CreateFolder(userID int, name string, parentID int64) (int64, error)
+	CreateFolder(tx *sql.Tx, userID int, name string, parentID int64) (int64, error)
 	GetFolderByID(id int64) (*models.Folder, error)
+	// CopyFolderTree copies the folder with all subfolders into the new parent folder, files are not copied
+	CopyFolderTree(tx *sql.Tx, folderID, newParentID int64, name string) ([]models.FolderCopy, error)
+	// GetFolderByName returns the subfolder with the name and locks it until the end of the transaction
+	GetFolderByName(tx *sql.Tx, parentID int64, name string) (*models.Folder, error)
+	// GetNamesWithPrefix returns names of the subfolders starting with the prefix
+	GetNamesWithPrefix(tx *sql.Tx, parentID int64, prefix string) ([]string, error)
+	// ResolvePath returns the deepest folder found for the names under the root folder of the user
+	ResolvePath(userID int, names []string, caseInsensitive bool) (*models.PathMatch, error)
 	GetFoldersInfo(folderID int64) ([]models.FolderSize, error)
-	// GetAllParentFolders returns all parent, and parent of parent folders
+	// WalkFolderTree calls visit for the folder of the user and its subtree down to the depth, depth-first
+	WalkFolderTree(userID int, folderID int64, opts models.TreeOptions, visit func(models.TreeNode) error) error
+	// GetFolderEntry returns the folder with the number of its direct children
+	GetFolderEntry(id int64) (*models.FolderEntry, error)
+	// ListSubfolders returns a page of the direct subfolders sorted by opts
+	ListSubfolders(folderID int64, opts models.ListOptions) ([]models.FolderEntry, error)
+	// GetAllParentFolders returns all parent, and parent of parent folders with the folder itself, the root folder first
 	GetAllParentFolders(folderID int64) ([]models.FolderSizeSimplified, error)
+	// GetBreadcrumbs returns folders from the root folder to the folder itself
+	GetBreadcrumbs(folderID int64) ([]models.Breadcrumb, error)
 	DeleteFolder(tx *sql.Tx, id int64) error
-	MoveFolder(tx *sql.Tx, folderID, newFolderID int64) error
+	// TrashFolder marks the folder with all subfolders as deleted with the trash entry
+	TrashFolder(tx *sql.Tx, id, trashID int64) error
+	// RestoreFolder brings folders of the trash entry back, the deleted folder is put into the parent folder under the name
+	RestoreFolder(tx *sql.Tx, trashID, folderID, parentID int64, name string) error
+	// DeleteTrashedFolders permanently deletes folders of the trash entry
+	DeleteTrashedFolders(tx *sql.Tx, trashID int64) error
+	// MoveFolder moves the folder into the new parent folder and sets its name there
+	MoveFolder(tx *sql.Tx, folderID, newFolderID int64, name string) error
+	RenameFolder(tx *sql.Tx, id int64, name string) error
+	// GetFolderMaxVersions returns how many versions of a file are kept in the folder, nil for the default limit
+	GetFolderMaxVersions(tx *sql.Tx, id int64) (*int, error)
+	UpdateFolderMaxVersions(id int64, maxVersions *int) error
 	// UpdateFolderSize used to update the size only for this folder with new size
 	UpdateFolderSize(id int64, newSize int64) error
-	// IncreaseFolderSize used to add the size for this and all parent folders
+	// IncreaseFolderSize used to add the size for this and all parent folders,
+	// fails with *models.QuotaError if any of them grows over its max size
 	IncreaseFolderSize(tx *sql.Tx, id int64, size int64) error
 	// DecreaseFolderSize used to reduce the size for this and all parent folders
 	DecreaseFolderSize(tx *sql.Tx, id int64, size int64) error
 	UpdateMultipleFoldersSize(tx *sql.Tx, folders []models.FolderSizeSimplified) error
+	// CheckFolderSize returns *models.QuotaError if the folder or any parent folder can't grow by the size
+	CheckFolderSize(tx *sql.Tx, id int64, size int64) error
+	// CalculateFolderSizes compares the recorded sizes of all folders of the user with the sizes of their content
+	// and locks the folders until the end of the transaction
+	CalculateFolderSizes(tx *sql.Tx, userID int) ([]models.FolderSizeCheck, error)
+	// UpdateFolderMaxSize sets the max size of the folder, nil removes the limit
+	UpdateFolderMaxSize(id int64, maxSize *int64) error
 }
@@ -1,11 +1,29 @@
 package _interface
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/saur4ig/file-storage/internal/models"
 )
 
 type TransactionRepository interface {
-	CreateTransaction(userID int, folderID int64) (int64, error)
+	// CreateTransaction starts a pending transaction in the folder, which expires at expiresAt
+	CreateTransaction(userID int, folderID int64, expiresAt time.Time) (*models.UploadTransaction, error)
 	GetTransactionByID(id int64) (*models.UploadTransaction, error)
-	UpdateTransactionStatus(id int64, status string) error
+	// GetUserTransactions returns transactions of the user matching the filter, the newest first
+	GetUserTransactions(userID int, filter models.TransactionFilter) ([]models.UploadTransaction, error)
+	// LockTransaction returns the transaction and locks it until the end of the transaction
+	LockTransaction(tx *sql.Tx, id int64) (*models.UploadTransaction, error)
+	// ShareTransaction returns the transaction and keeps its status from changing until the end of the transaction,
+	// other transactions may share it too
+	ShareTransaction(tx *sql.Tx, id int64) (*models.UploadTransaction, error)
+	// ShareFileTransaction does the same for the transaction the file was uploaded in, nil if there is no such one
+	ShareFileTransaction(tx *sql.Tx, fileID int64) (*models.UploadTransaction, error)
+	// GetExpiredTransactions returns up to limit open (pending or resumed) transactions which expired before the time
+	GetExpiredTransactions(before time.Time, limit int) ([]models.UploadTransaction, error)
+	// UpdateTransactionStatus moves the transaction of the user and the folder to the status if its current one allows
+	UpdateTransactionStatus(tx *sql.Tx, id int64, userID int, folderID int64, status string) (*models.UploadTransaction, error)
+	// CreateTransactionAudit records the rolled back transaction
+	CreateTransactionAudit(tx *sql.Tx, audit *models.TransactionAudit) error
 }
@@ -0,0 +1,20 @@
+package _interface
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// TrashRepository - entries of the deleted folders and files, which can be restored until they are purged
+type TrashRepository interface {
+	CreateTrashItem(tx *sql.Tx, item *models.TrashItem) error
+	GetTrashItem(id int64) (*models.TrashItem, error)
+	// LockTrashItem returns the entry and locks it until the end of the transaction
+	LockTrashItem(tx *sql.Tx, id int64) (*models.TrashItem, error)
+	ListTrashItems(userID int) ([]models.TrashItem, error)
+	// GetExpiredTrashItems returns entries deleted before the provided time
+	GetExpiredTrashItems(before time.Time, limit int) ([]models.TrashItem, error)
+	DeleteTrashItem(tx *sql.Tx, id int64) error
+}
@@ -0,0 +1,22 @@
+package _interface
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// UploadRepository - base functions to work with the state of resumable uploads
+type UploadRepository interface {
+	CreateUpload(upload *models.Upload) error
+	GetUploadByID(id string) (*models.Upload, error)
+	// MoveUploadOffset moves the upload offset by size and extends the expiration,
+	// if the upload offset is not equal to offset anymore - models.ErrOffsetMismatch is returned
+	MoveUploadOffset(tx *sql.Tx, id string, offset, size int64, expiresAt time.Time) (*models.Upload, error)
+	CreateUploadPart(tx *sql.Tx, part *models.UploadPart) error
+	GetUploadParts(id string) ([]models.UploadPart, error)
+	DeleteUpload(id string) error
+	// GetExpiredUploads returns uploads expired before the provided time
+	GetExpiredUploads(before time.Time, limit int) ([]models.Upload, error)
+}
@@ -0,0 +1,20 @@
+package _interface
+
+import (
+	"database/sql"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// UserRepository - storage quotas of the users
+type UserRepository interface {
+	GetUserIDs() ([]int, error)
+	GetUserQuota(userID int) (*models.UserQuota, error)
+	// LockUserQuota returns the quota and locks the user until the end of the transaction,
+	// so concurrent requests can't take the same free space
+	LockUserQuota(tx *sql.Tx, userID int) (*models.UserQuota, error)
+	GetUserSettings(userID int) (*models.UserSettings, error)
+	UpdateUserSettings(settings *models.UserSettings) error
+	// UpdateUserQuota sets the quota of the user, nil removes the limit
+	UpdateUserQuota(userID int, quota *int64) error
+}
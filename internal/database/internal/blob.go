@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// AcquireBlob creates the blob or increases the reference count of the existing one with the same content
+func (r *blobRepository) AcquireBlob(tx *sql.Tx, blob *models.Blob) (string, error) {
+	query := `
+		INSERT INTO blobs (checksum, storage_key, size, ref_count)
+		VALUES ($1, $2, $3, 1)
+		ON CONFLICT (checksum) DO UPDATE SET ref_count = blobs.ref_count + 1
+		RETURNING storage_key
+	`
+	var storageKey string
+	if err := tx.QueryRow(query, blob.Checksum, blob.StorageKey, blob.Size).Scan(&storageKey); err != nil {
+		return "", fmt.Errorf("failed to acquire blob: %w", err)
+	}
+	return storageKey, nil
+}
+
+// AddBlobReferences increases reference counts of the existing blobs by the provided numbers
+func (r *blobRepository) AddBlobReferences(tx *sql.Tx, refs map[string]int) error {
+	if len(refs) == 0 {
+		return nil
+	}
+
+	checksums := make([]string, 0, len(refs))
+	counts := make([]int64, 0, len(refs))
+	for checksum, count := range refs {
+		checksums = append(checksums, checksum)
+		counts = append(counts, int64(count))
+	}
+
+	query := `
+		UPDATE blobs b SET ref_count = b.ref_count + r.count
+		FROM unnest($1::VARCHAR[], $2::INT[]) AS r(checksum, count)
+		WHERE b.checksum = r.checksum
+	`
+	result, err := tx.Exec(query, pq.Array(checksums), pq.Array(counts))
+	if err != nil {
+		return fmt.Errorf("failed to add blob references: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to add blob references: %w", err)
+	}
+	if updated != int64(len(refs)) {
+		return fmt.Errorf("blob not found: %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+// ReleaseBlob decreases the reference count of the blob and deletes it when nothing references it anymore
+func (r *blobRepository) ReleaseBlob(tx *sql.Tx, checksum string) (string, error) {
+	query := `
+		UPDATE blobs SET ref_count = ref_count - 1
+		WHERE checksum = $1
+		RETURNING ref_count, storage_key
+	`
+	var refCount int
+	var storageKey string
+	if err := tx.QueryRow(query, checksum).Scan(&refCount, &storageKey); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("blob not found: %w", models.ErrNotFound)
+		}
+		return "", fmt.Errorf("failed to release blob: %w", err)
+	}
+
+	if refCount > 0 {
+		return "", nil
+	}
+
+	// the row is locked by the update above, so nobody could acquire it in between
+	if _, err := tx.Exec(`DELETE FROM blobs WHERE checksum = $1`, checksum); err != nil {
+		return "", fmt.Errorf("failed to delete blob: %w", err)
+	}
+	return storageKey, nil
+}
+
+// GetStorageStats returns logical size of all files with their versions and physical size of the stored objects,
+// files and versions without blobs own their objects
+func (r *blobRepository) GetStorageStats() (*models.StorageStats, error) {
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM files),
+			(SELECT COALESCE(SUM(size + versions_size), 0) FROM files),
+			(SELECT COUNT(*) FROM blobs) + (SELECT COUNT(*) FROM files WHERE blob_checksum IS NULL) +
+				(SELECT COUNT(*) FROM file_versions WHERE blob_checksum IS NULL),
+			(SELECT COALESCE(SUM(size), 0) FROM blobs) + (SELECT COALESCE(SUM(size), 0) FROM files WHERE blob_checksum IS NULL) +
+				(SELECT COALESCE(SUM(size), 0) FROM file_versions WHERE blob_checksum IS NULL)
+	`
+	stats := &models.StorageStats{}
+	if err := r.db.QueryRow(query).Scan(&stats.Files, &stats.LogicalSize, &stats.Objects, &stats.PhysicalSize); err != nil {
+		return nil, fmt.Errorf("failed to get storage stats: %w", err)
+	}
+	return stats, nil
+}
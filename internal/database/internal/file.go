@@ -5,18 +5,30 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/lib/pq"
 	"github.com/saur4ig/file-storage/internal/models"
 )
 
-// CreateFile inserts a new file record into the database
+// columns of the file, should be scanned with scanFile
+const fileColumns = `
+	id, folder_id, user_id, name, s3_url, size, transaction_id, COALESCE(checksum, ''), COALESCE(md5, ''),
+	COALESCE(blob_checksum, ''), version, versions_size, created_at, COALESCE(updated_at, created_at)
+`
+
+// CreateFile inserts a new file record into the database,
+// if the name is already taken in the folder - models.ErrNameConflict is returned
 func (r *fileRepository) CreateFile(tx *sql.Tx, file *models.File) error {
 	query := `
-		INSERT INTO files (folder_id, user_id, name, s3_url, size, transaction_id) 
-		VALUES ($1, $2, $3, $4, $5, $6) 
-		RETURNING id, created_at
+		INSERT INTO files (folder_id, user_id, name, s3_url, size, transaction_id, checksum, md5, blob_checksum, version, versions_size) 
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), GREATEST($10, 1), $11) 
+		RETURNING id, version, created_at, updated_at
 	`
-	if err := tx.QueryRow(query, file.FolderID, file.UserID, file.Name, file.S3URL, file.Size, file.TransactionID).
-		Scan(&file.ID, &file.CreatedAt); err != nil {
+	if err := tx.QueryRow(query, file.FolderID, file.UserID, file.Name, file.S3URL, file.Size, file.TransactionID, file.Checksum, file.MD5, file.BlobChecksum,
+		file.Version, file.VersionsSize).
+		Scan(&file.ID, &file.Version, &file.CreatedAt, &file.UpdatedAt); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("file %q already exists: %w", file.Name, models.ErrNameConflict)
+		}
 		return fmt.Errorf("failed to create file: %w", err)
 	}
 	return nil
@@ -24,37 +36,404 @@ func (r *fileRepository) CreateFile(tx *sql.Tx, file *models.File) error {
 
 // GetFileByID retrieves a file from the database by its id
 func (r *fileRepository) GetFileByID(id int64) (*models.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE id = $1 AND trash_id IS NULL`
+	file, err := scanFile(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("file not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve file by ID: %w", err)
+	}
+	return file, nil
+}
+
+// LockFile retrieves a file by its id and locks it until the end of the transaction
+func (r *fileRepository) LockFile(tx *sql.Tx, id int64) (*models.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE id = $1 AND trash_id IS NULL FOR UPDATE`
+	file, err := scanFile(tx.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("file not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to lock file: %w", err)
+	}
+	return file, nil
+}
+
+// UpdateFileContent replaces the current content of the file with the new version,
+// the previous content has to be saved as a version before
+func (r *fileRepository) UpdateFileContent(tx *sql.Tx, file *models.File) error {
 	query := `
-		SELECT id, folder_id, user_id, name, s3_url, size, transaction_id, created_at 
-		FROM files 
-		WHERE id = $1
+		UPDATE files SET
+			s3_url = $1, size = $2, checksum = NULLIF($3, ''), md5 = NULLIF($4, ''), blob_checksum = NULLIF($5, ''),
+			version = $6, versions_size = $7, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $8
+		RETURNING updated_at
 	`
-	file := &models.File{}
-	err := r.db.QueryRow(query, id).
-		Scan(&file.ID, &file.FolderID, &file.UserID, &file.Name, &file.S3URL, &file.Size, &file.TransactionID, &file.CreatedAt)
+	err := tx.QueryRow(query, file.S3URL, file.Size, file.Checksum, file.MD5, file.BlobChecksum, file.Version, file.VersionsSize, file.ID).
+		Scan(&file.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("file not found: %w", err)
+			return fmt.Errorf("file not found: %w", models.ErrNotFound)
 		}
-		return nil, fmt.Errorf("failed to retrieve file by ID: %w", err)
+		return fmt.Errorf("failed to update file content: %w", err)
+	}
+	return nil
+}
+
+// GetFileByName retrieves a file of the folder by its name and locks it until the end of the transaction
+func (r *fileRepository) GetFileByName(tx *sql.Tx, folderID int64, name string) (*models.File, error) {
+	query := `SELECT ` + fileColumns + ` FROM files WHERE folder_id = $1 AND name = $2 AND trash_id IS NULL FOR UPDATE`
+	file, err := scanFile(tx.QueryRow(query, folderID, name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("file not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve file by name: %w", err)
 	}
 	return file, nil
 }
 
-// DeleteFile deletes a file record from the database by its id
+// FindFileByName retrieves a file of the folder by its name. Ignoring case the exact match is preferred,
+// if there is none and several names differ only by case - models.ErrAmbiguousPath is returned
+func (r *fileRepository) FindFileByName(folderID int64, name string, caseInsensitive bool) (*models.File, error) {
+	query := `
+		SELECT ` + fileColumns + ` FROM files
+		WHERE folder_id = $1 AND trash_id IS NULL AND (name = $2 OR ($3 AND lower(name) = lower($2)))
+		ORDER BY name = $2 DESC
+		LIMIT 2
+	`
+	rows, err := r.db.Query(query, folderID, name, caseInsensitive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve file by name: %w", err)
+	}
+	defer rows.Close()
+
+	var files []*models.File
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		files = append(files, file)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating file rows: %w", err)
+	}
+
+	switch {
+	case len(files) == 0:
+		return nil, fmt.Errorf("file not found: %w", models.ErrNotFound)
+	case files[0].Name != name && len(files) > 1:
+		return nil, fmt.Errorf("several files match %q: %w", name, models.ErrAmbiguousPath)
+	}
+	return files[0], nil
+}
+
+// GetNamesWithPrefix retrieves names of the folder files starting with the prefix
+func (r *fileRepository) GetNamesWithPrefix(tx *sql.Tx, folderID int64, prefix string) ([]string, error) {
+	query := `SELECT name FROM files WHERE folder_id = $1 AND starts_with(name, $2) AND trash_id IS NULL`
+	return queryNames(tx, query, folderID, prefix)
+}
+
+// ListFolderFiles retrieves a page of the files located directly in the folder
+func (r *fileRepository) ListFolderFiles(folderID int64, opts models.ListOptions) ([]models.File, error) {
+	condition, orderBy, args := listingClauses(opts, 2)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM files 
+		WHERE folder_id = $1 AND trash_id IS NULL %s
+		%s
+		LIMIT %d
+	`, fileColumns, condition, orderBy, opts.Limit)
+
+	rows, err := r.db.Query(query, append([]interface{}{folderID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folder files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []models.File
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		files = append(files, *file)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating file rows: %w", err)
+	}
+
+	return files, nil
+}
+
+// DeleteFile deletes a file record from the database by its id,
+// if the file is already deleted - models.ErrNotFound is returned
 func (r *fileRepository) DeleteFile(tx *sql.Tx, id int64) error {
 	query := `DELETE FROM files WHERE id = $1`
-	if _, err := tx.Exec(query, id); err != nil {
+	result, err := tx.Exec(query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
 		return fmt.Errorf("failed to delete file: %w", err)
 	}
+	if deleted == 0 {
+		return fmt.Errorf("file not found: %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteFolderTreeFiles deletes files located in the folder and all its subfolders, returns the deleted files.
+// Items in the trash are left for the trash purge
+func (r *fileRepository) DeleteFolderTreeFiles(tx *sql.Tx, folderID int64) ([]models.File, error) {
+	query := `
+		DELETE FROM files
+		WHERE folder_id IN (SELECT id FROM folders WHERE path @> ARRAY[$1::BIGINT] AND trash_id IS NULL)
+			AND trash_id IS NULL
+		RETURNING id, folder_id, s3_url, size, versions_size, COALESCE(blob_checksum, '')
+	`
+	return r.queryDeletedFiles(tx, query, folderID)
+}
+
+// TrashFile marks the file as deleted with the trash entry,
+// if the file is already deleted - models.ErrNotFound is returned
+func (r *fileRepository) TrashFile(tx *sql.Tx, id, trashID int64) error {
+	query := `UPDATE files SET trash_id = $1 WHERE id = $2 AND trash_id IS NULL`
+	result, err := tx.Exec(query, trashID, id)
+	if err != nil {
+		return fmt.Errorf("failed to trash file: %w", err)
+	}
+
+	trashed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to trash file: %w", err)
+	}
+	if trashed == 0 {
+		return fmt.Errorf("file not found: %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+// TrashFolderFiles marks files of the folders deleted with the trash entry as deleted with it too
+func (r *fileRepository) TrashFolderFiles(tx *sql.Tx, trashID int64) error {
+	query := `
+		UPDATE files SET trash_id = $1
+		WHERE trash_id IS NULL AND folder_id IN (SELECT id FROM folders WHERE trash_id = $1)
+	`
+	if _, err := tx.Exec(query, trashID); err != nil {
+		return fmt.Errorf("failed to trash folder files: %w", err)
+	}
+	return nil
+}
+
+// RestoreFile brings the file deleted with the trash entry back into the folder under the provided name,
+// if the name is already taken in the folder - models.ErrNameConflict is returned
+func (r *fileRepository) RestoreFile(tx *sql.Tx, trashID, folderID int64, name string) error {
+	query := `UPDATE files SET trash_id = NULL, folder_id = $1, name = $2 WHERE trash_id = $3`
+	if _, err := tx.Exec(query, folderID, name, trashID); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("file %q already exists: %w", name, models.ErrNameConflict)
+		}
+		return fmt.Errorf("failed to restore file: %w", err)
+	}
+	return nil
+}
+
+// RestoreFolderFiles brings files deleted with the trash entry back into their folders
+func (r *fileRepository) RestoreFolderFiles(tx *sql.Tx, trashID int64) error {
+	if _, err := tx.Exec(`UPDATE files SET trash_id = NULL WHERE trash_id = $1`, trashID); err != nil {
+		return fmt.Errorf("failed to restore folder files: %w", err)
+	}
+	return nil
+}
+
+// DeleteTrashedFiles permanently deletes files deleted with the trash entry, returns the deleted files
+func (r *fileRepository) DeleteTrashedFiles(tx *sql.Tx, trashID int64) ([]models.File, error) {
+	query := `
+		DELETE FROM files
+		WHERE trash_id = $1
+		RETURNING id, folder_id, s3_url, size, versions_size, COALESCE(blob_checksum, '')
+	`
+	return r.queryDeletedFiles(tx, query, trashID)
+}
+
+// DeleteTransactionFiles permanently deletes files uploaded in the upload transaction, returns the deleted files.
+// Files in the trash are left for the trash to purge them with their entries
+func (r *fileRepository) DeleteTransactionFiles(tx *sql.Tx, transactionID int64) ([]models.File, error) {
+	query := `
+		DELETE FROM files
+		WHERE transaction_id = $1 AND trash_id IS NULL
+		RETURNING id, folder_id, s3_url, size, versions_size, COALESCE(blob_checksum, '')
+	`
+	return r.queryDeletedFiles(tx, query, transactionID)
+}
+
+// GetTransactionFiles retrieves files uploaded in the upload transaction in the upload order,
+// files in the trash are skipped
+func (r *fileRepository) GetTransactionFiles(transactionID int64) ([]models.File, error) {
+	query := `
+		SELECT ` + fileColumns + `
+		FROM files
+		WHERE transaction_id = $1 AND trash_id IS NULL
+		ORDER BY id
+	`
+	rows, err := r.db.Query(query, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []models.File
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file: %w", err)
+		}
+		files = append(files, *file)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating file rows: %w", err)
+	}
+
+	return files, nil
+}
+
+// GetTransactionFolderSizes returns sizes of files uploaded in the upload transaction summed by their folders,
+// files in the trash are not counted
+func (r *fileRepository) GetTransactionFolderSizes(tx *sql.Tx, transactionID int64) ([]models.FolderSizeSimplified, error) {
+	query := `
+		SELECT folder_id, SUM(size + versions_size)
+		FROM files
+		WHERE transaction_id = $1 AND trash_id IS NULL
+		GROUP BY folder_id
+		ORDER BY folder_id
+	`
+	rows, err := tx.Query(query, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction folder sizes: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []models.FolderSizeSimplified
+	for rows.Next() {
+		var folder models.FolderSizeSimplified
+		if err = rows.Scan(&folder.ID, &folder.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan transaction folder size: %w", err)
+		}
+		folders = append(folders, folder)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get transaction folder sizes: %w", err)
+	}
+	return folders, nil
+}
+
+// runs the delete query returning the deleted files
+func (r *fileRepository) queryDeletedFiles(tx *sql.Tx, query string, args ...interface{}) ([]models.File, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []models.File
+	for rows.Next() {
+		var file models.File
+		if err = rows.Scan(&file.ID, &file.FolderID, &file.S3URL, &file.Size, &file.VersionsSize, &file.BlobChecksum); err != nil {
+			return nil, fmt.Errorf("failed to scan deleted file: %w", err)
+		}
+		files = append(files, file)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete files: %w", err)
+	}
+	return files, nil
+}
+
+// CopyFolderTreeFiles copies files of the source folders into their copies, the copied files
+// reference the same objects as the source ones, returns the created files
+func (r *fileRepository) CopyFolderTreeFiles(tx *sql.Tx, folders []models.FolderCopy) ([]models.File, error) {
+	sourceIDs := make([]int64, len(folders))
+	ids := make([]int64, len(folders))
+	for i, folder := range folders {
+		sourceIDs[i], ids[i] = folder.SourceID, folder.ID
+	}
+
+	query := `
+		INSERT INTO files (folder_id, user_id, name, s3_url, size, checksum, md5, blob_checksum, version, versions_size, updated_at)
+		SELECT m.id, f.user_id, f.name, f.s3_url, f.size, f.checksum, f.md5, f.blob_checksum, f.version, f.versions_size, f.updated_at
+		FROM files f
+		JOIN unnest($1::BIGINT[], $2::BIGINT[]) AS m(source_id, id) ON m.source_id = f.folder_id
+		WHERE f.trash_id IS NULL
+		RETURNING ` + fileColumns
+	rows, err := tx.Query(query, pq.Array(sourceIDs), pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy folder files: %w", err)
+	}
+	defer rows.Close()
+
+	var files []models.File
+	for rows.Next() {
+		file, err := scanFile(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan copied file: %w", err)
+		}
+		files = append(files, *file)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to copy folder files: %w", err)
+	}
+	return files, nil
+}
+
+// UpdateFileKey points the file to another stored object
+func (r *fileRepository) UpdateFileKey(tx *sql.Tx, id int64, key string) error {
+	query := `UPDATE files SET s3_url = $1 WHERE id = $2`
+	if _, err := tx.Exec(query, key, id); err != nil {
+		return fmt.Errorf("failed to update file key: %w", err)
+	}
 	return nil
 }
 
-// MoveFile updates the folder_id of a file to move it to new folder
-func (r *fileRepository) MoveFile(tx *sql.Tx, fileID, newFolderID int64) error {
-	query := `UPDATE files SET folder_id = $1 WHERE id = $2`
-	if _, err := tx.Exec(query, newFolderID, fileID); err != nil {
+// MoveFile moves a file to new folder under the provided name,
+// if the name is already taken in the folder - models.ErrNameConflict is returned
+func (r *fileRepository) MoveFile(tx *sql.Tx, fileID, newFolderID int64, name string) error {
+	query := `UPDATE files SET folder_id = $1, name = $2 WHERE id = $3`
+	if _, err := tx.Exec(query, newFolderID, name, fileID); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("file %q already exists: %w", name, models.ErrNameConflict)
+		}
 		return fmt.Errorf("failed to move file: %w", err)
 	}
 	return nil
 }
+
+// RenameFile changes the name of a file,
+// if the name is already taken in the folder - models.ErrNameConflict is returned
+func (r *fileRepository) RenameFile(tx *sql.Tx, id int64, name string) error {
+	query := `UPDATE files SET name = $1 WHERE id = $2`
+	if _, err := tx.Exec(query, name, id); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("file %q already exists: %w", name, models.ErrNameConflict)
+		}
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+	return nil
+}
+
+// scans a row selected with fileColumns
+func scanFile(row interface{ Scan(dest ...any) error }) (*models.File, error) {
+	file := &models.File{}
+	err := row.Scan(&file.ID, &file.FolderID, &file.UserID, &file.Name, &file.S3URL, &file.Size, &file.TransactionID, &file.Checksum,
+		&file.MD5, &file.BlobChecksum, &file.Version, &file.VersionsSize, &file.CreatedAt, &file.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
@@ -0,0 +1,161 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// columns of the file version, should be scanned with scanFileVersion
+const fileVersionColumns = `
+	id, file_id, user_id, version, s3_url, size, COALESCE(checksum, ''), COALESCE(md5, ''),
+	COALESCE(blob_checksum, ''), created_at
+`
+
+// CreateFileVersion inserts a previous version of the file into the database
+func (r *fileVersionRepository) CreateFileVersion(tx *sql.Tx, version *models.FileVersion) error {
+	query := `
+		INSERT INTO file_versions (file_id, user_id, version, s3_url, size, checksum, md5, blob_checksum, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9)
+		RETURNING id
+	`
+	err := tx.QueryRow(query, version.FileID, version.UserID, version.Version, version.S3URL, version.Size,
+		version.Checksum, version.MD5, version.BlobChecksum, version.CreatedAt).Scan(&version.ID)
+	if err != nil {
+		return fmt.Errorf("failed to create file version: %w", err)
+	}
+	return nil
+}
+
+// GetFileVersion retrieves the previous version of the file by its number
+func (r *fileVersionRepository) GetFileVersion(fileID int64, version int) (*models.FileVersion, error) {
+	query := `SELECT ` + fileVersionColumns + ` FROM file_versions WHERE file_id = $1 AND version = $2`
+	fileVersion, err := scanFileVersion(r.db.QueryRow(query, fileID, version))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("file version not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve file version: %w", err)
+	}
+	return fileVersion, nil
+}
+
+// ListFileVersions retrieves previous versions of the file, the newest first
+func (r *fileVersionRepository) ListFileVersions(fileID int64) ([]models.FileVersion, error) {
+	query := `SELECT ` + fileVersionColumns + ` FROM file_versions WHERE file_id = $1 ORDER BY version DESC`
+	rows, err := r.db.Query(query, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list file versions: %w", err)
+	}
+	return collectFileVersions(rows)
+}
+
+// DeleteOldFileVersions deletes versions of the file except the newest keep ones, returns the deleted versions
+func (r *fileVersionRepository) DeleteOldFileVersions(tx *sql.Tx, fileID int64, keep int) ([]models.FileVersion, error) {
+	query := `
+		DELETE FROM file_versions
+		WHERE file_id = $1 AND id NOT IN (
+			SELECT id FROM file_versions WHERE file_id = $1 ORDER BY version DESC LIMIT $2
+		)
+		RETURNING ` + fileVersionColumns
+	rows, err := tx.Query(query, fileID, keep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete old file versions: %w", err)
+	}
+	return collectFileVersions(rows)
+}
+
+// DeleteFileVersions deletes all versions of the files, returns the deleted versions
+func (r *fileVersionRepository) DeleteFileVersions(tx *sql.Tx, fileIDs []int64) ([]models.FileVersion, error) {
+	if len(fileIDs) == 0 {
+		return nil, nil
+	}
+
+	query := `DELETE FROM file_versions WHERE file_id = ANY($1) RETURNING ` + fileVersionColumns
+	rows, err := tx.Query(query, pq.Array(fileIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete file versions: %w", err)
+	}
+	return collectFileVersions(rows)
+}
+
+// CopyFileVersions copies versions of the source file to its copy, the copied versions
+// reference the same objects as the source ones, returns the created versions
+func (r *fileVersionRepository) CopyFileVersions(tx *sql.Tx, sourceID, fileID int64) ([]models.FileVersion, error) {
+	query := `
+		INSERT INTO file_versions (file_id, user_id, version, s3_url, size, checksum, md5, blob_checksum, created_at)
+		SELECT $2, user_id, version, s3_url, size, checksum, md5, blob_checksum, created_at
+		FROM file_versions
+		WHERE file_id = $1
+		RETURNING ` + fileVersionColumns
+	rows, err := tx.Query(query, sourceID, fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy file versions: %w", err)
+	}
+	return collectFileVersions(rows)
+}
+
+// CopyFolderTreeFileVersions copies versions of the files located in the source folders to the copied files,
+// copies are found by their names, which are unique in the folder. Returns the created versions
+func (r *fileVersionRepository) CopyFolderTreeFileVersions(tx *sql.Tx, folders []models.FolderCopy) ([]models.FileVersion, error) {
+	sourceIDs := make([]int64, len(folders))
+	ids := make([]int64, len(folders))
+	for i, folder := range folders {
+		sourceIDs[i], ids[i] = folder.SourceID, folder.ID
+	}
+
+	query := `
+		INSERT INTO file_versions (file_id, user_id, version, s3_url, size, checksum, md5, blob_checksum, created_at)
+		SELECT c.id, v.user_id, v.version, v.s3_url, v.size, v.checksum, v.md5, v.blob_checksum, v.created_at
+		FROM unnest($1::BIGINT[], $2::BIGINT[]) AS m(source_id, id)
+		JOIN files f ON f.folder_id = m.source_id AND f.trash_id IS NULL
+		JOIN files c ON c.folder_id = m.id AND c.name = f.name AND c.trash_id IS NULL
+		JOIN file_versions v ON v.file_id = f.id
+		RETURNING ` + fileVersionColumns
+	rows, err := tx.Query(query, pq.Array(sourceIDs), pq.Array(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy file versions: %w", err)
+	}
+	return collectFileVersions(rows)
+}
+
+// UpdateFileVersionKey points the file version to another stored object
+func (r *fileVersionRepository) UpdateFileVersionKey(tx *sql.Tx, id int64, key string) error {
+	query := `UPDATE file_versions SET s3_url = $1 WHERE id = $2`
+	if _, err := tx.Exec(query, key, id); err != nil {
+		return fmt.Errorf("failed to update file version key: %w", err)
+	}
+	return nil
+}
+
+// reads all rows selected with fileVersionColumns and closes them
+func collectFileVersions(rows *sql.Rows) ([]models.FileVersion, error) {
+	defer rows.Close()
+
+	var versions []models.FileVersion
+	for rows.Next() {
+		version, err := scanFileVersion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan file version: %w", err)
+		}
+		versions = append(versions, *version)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating file version rows: %w", err)
+	}
+	return versions, nil
+}
+
+// scans a row selected with fileVersionColumns
+func scanFileVersion(row interface{ Scan(dest ...any) error }) (*models.FileVersion, error) {
+	version := &models.FileVersion{}
+	err := row.Scan(&version.ID, &version.FileID, &version.UserID, &version.Version, &version.S3URL, &version.Size,
+		&version.Checksum, &version.MD5, &version.BlobChecksum, &version.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return version, nil
+}
@@ -6,40 +6,261 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/lib/pq"
 	"github.com/saur4ig/file-storage/internal/models"
 )
 
-// CreateFolder creates a folder and returns its id, if successful.
-func (r *folderRepository) CreateFolder(userID int, name string, parentID int64) (int64, error) {
-	query := `INSERT INTO folders (user_id, name, parent_folder_id) VALUES ($1, $2, $3) RETURNING id`
+// CreateFolder creates a folder and returns its id, if successful. The path of the folder continues the path
+// of the parent folder, if the parent folder does not exist - models.ErrNotFound is returned.
+// If the name is already taken in the parent folder - models.ErrNameConflict is returned
+func (r *folderRepository) CreateFolder(tx *sql.Tx, userID int, name string, parentID int64) (int64, error) {
+	// the id is taken from the sequence in advance, so it ends the path
+	query := `
+		WITH new_folder AS (
+			SELECT nextval(pg_get_serial_sequence('folders', 'id')) AS id
+		)
+		INSERT INTO folders (id, user_id, name, parent_folder_id, path)
+		SELECT n.id, $1, $2, p.id, p.path || n.id
+		FROM new_folder n, folders p
+		WHERE p.id = $3
+		RETURNING id
+	`
 	var folderID int64
-	err := r.db.QueryRow(query, userID, name, parentID).Scan(&folderID)
+	err := tx.QueryRow(query, userID, name, parentID).Scan(&folderID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("parent folder not found: %w", models.ErrNotFound)
+		}
+		if isUniqueViolation(err) {
+			return 0, fmt.Errorf("folder %q already exists: %w", name, models.ErrNameConflict)
+		}
 		return 0, fmt.Errorf("failed to create folder: %w", err)
 	}
 	return folderID, nil
 }
 
+// CopyFolderTree copies the folder with all its subfolders into the new parent folder under the provided name,
+// sizes are copied as well, returns the copied folders linked to their sources.
+// If the name is already taken in the new parent folder - models.ErrNameConflict is returned
+func (r *folderRepository) CopyFolderTree(tx *sql.Tx, folderID, newParentID int64, name string) ([]models.FolderCopy, error) {
+	// ids of the copies are taken from the sequence in advance, so children can reference their copied parents.
+	// Paths of the copies continue the path of the new parent with the copied ids of the source path
+	query := `
+		WITH tree AS (
+			SELECT id, user_id, name, parent_folder_id, size, max_versions, max_size,
+				path[array_position(path, $1::BIGINT):] AS path
+			FROM folders
+			WHERE path @> ARRAY[$1::BIGINT] AND trash_id IS NULL
+		), mapping AS (
+			SELECT id AS source_id, nextval(pg_get_serial_sequence('folders', 'id')) AS id FROM tree
+		), parent AS (
+			SELECT path FROM folders WHERE id = $2
+		), copied AS (
+			INSERT INTO folders (id, user_id, name, parent_folder_id, size, max_versions, max_size, path)
+			SELECT m.id, t.user_id,
+				CASE WHEN t.id = $1 THEN $3 ELSE t.name END,
+				CASE WHEN t.id = $1 THEN $2 ELSE p.id END,
+				t.size, t.max_versions, t.max_size,
+				parent.path || ARRAY(
+					SELECT pm.id FROM unnest(t.path) WITH ORDINALITY AS u(id, n)
+					JOIN mapping pm ON pm.source_id = u.id
+					ORDER BY u.n
+				)
+			FROM tree t
+			CROSS JOIN parent
+			JOIN mapping m ON m.source_id = t.id
+			LEFT JOIN mapping p ON p.source_id = t.parent_folder_id
+			RETURNING id
+		)
+		SELECT m.source_id, m.id FROM mapping m JOIN copied c ON c.id = m.id
+	`
+	rows, err := tx.Query(query, folderID, newParentID, name)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("folder %q already exists: %w", name, models.ErrNameConflict)
+		}
+		return nil, fmt.Errorf("failed to copy folders: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []models.FolderCopy
+	for rows.Next() {
+		var folder models.FolderCopy
+		if err = rows.Scan(&folder.SourceID, &folder.ID); err != nil {
+			return nil, fmt.Errorf("failed to scan copied folder: %w", err)
+		}
+		folders = append(folders, folder)
+	}
+
+	if err = rows.Err(); err != nil {
+		if isUniqueViolation(err) {
+			return nil, fmt.Errorf("folder %q already exists: %w", name, models.ErrNameConflict)
+		}
+		return nil, fmt.Errorf("failed to copy folders: %w", err)
+	}
+	if len(folders) == 0 {
+		return nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+	}
+
+	return folders, nil
+}
+
 // GetFolderByID retrieves all data of a folder by its id.
 func (r *folderRepository) GetFolderByID(id int64) (*models.Folder, error) {
-	query := `SELECT id, user_id, name, parent_folder_id, size, created_at, updated_at FROM folders WHERE id = $1`
+	query := `
+		SELECT id, user_id, name, parent_folder_id, size, max_size, created_at, updated_at
+		FROM folders
+		WHERE id = $1 AND trash_id IS NULL
+	`
 	folder := &models.Folder{}
-	err := r.db.QueryRow(query, id).Scan(&folder.ID, &folder.UserID, &folder.Name, &folder.ParentFolderID, &folder.Size, &folder.CreatedAt, &folder.UpdatedAt)
+	err := r.db.QueryRow(query, id).Scan(&folder.ID, &folder.UserID, &folder.Name, &folder.ParentFolderID, &folder.Size, &folder.MaxSize, &folder.CreatedAt, &folder.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("folder not found: %w", err)
+			return nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
 		}
 		return nil, fmt.Errorf("failed to retrieve folder by ID: %w", err)
 	}
 	return folder, nil
 }
 
+// GetFolderByName retrieves a subfolder of the parent folder by its name and locks it until the end of the transaction
+func (r *folderRepository) GetFolderByName(tx *sql.Tx, parentID int64, name string) (*models.Folder, error) {
+	query := `
+		SELECT id, user_id, name, parent_folder_id, size, max_size, created_at, updated_at
+		FROM folders
+		WHERE parent_folder_id = $1 AND name = $2 AND trash_id IS NULL
+		FOR UPDATE
+	`
+	folder := &models.Folder{}
+	err := tx.QueryRow(query, parentID, name).
+		Scan(&folder.ID, &folder.UserID, &folder.Name, &folder.ParentFolderID, &folder.Size, &folder.MaxSize, &folder.CreatedAt, &folder.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve folder by name: %w", err)
+	}
+	return folder, nil
+}
+
+// GetNamesWithPrefix retrieves names of the subfolders starting with the prefix
+func (r *folderRepository) GetNamesWithPrefix(tx *sql.Tx, parentID int64, prefix string) ([]string, error) {
+	query := `SELECT name FROM folders WHERE parent_folder_id = $1 AND starts_with(name, $2) AND trash_id IS NULL`
+	return queryNames(tx, query, parentID, prefix)
+}
+
+// folder columns with the number of direct children, used by the folder listing
+const folderEntryColumns = `
+	id, user_id, name, parent_folder_id, size, max_size, created_at, updated_at,
+	(SELECT COUNT(*) FROM files WHERE folder_id = f.id AND trash_id IS NULL) AS file_count,
+	(SELECT COUNT(*) FROM folders c WHERE c.parent_folder_id = f.id AND c.trash_id IS NULL) AS folder_count
+`
+
+// GetFolderEntry retrieves a folder with the number of its files and subfolders
+func (r *folderRepository) GetFolderEntry(id int64) (*models.FolderEntry, error) {
+	query := `SELECT ` + folderEntryColumns + ` FROM folders f WHERE id = $1 AND trash_id IS NULL`
+	folder, err := scanFolderEntry(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve folder by ID: %w", err)
+	}
+	return folder, nil
+}
+
+// ListSubfolders retrieves a page of the direct subfolders of the folder
+func (r *folderRepository) ListSubfolders(folderID int64, opts models.ListOptions) ([]models.FolderEntry, error) {
+	condition, orderBy, args := listingClauses(opts, 2)
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM folders f
+		WHERE parent_folder_id = $1 AND trash_id IS NULL %s
+		%s
+		LIMIT %d
+	`, folderEntryColumns, condition, orderBy, opts.Limit)
+
+	rows, err := r.db.Query(query, append([]interface{}{folderID}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subfolders: %w", err)
+	}
+	defer rows.Close()
+
+	var folders []models.FolderEntry
+	for rows.Next() {
+		folder, err := scanFolderEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan subfolder: %w", err)
+		}
+		folders = append(folders, *folder)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating subfolder rows: %w", err)
+	}
+
+	return folders, nil
+}
+
+// ResolvePath walks the names down from the root folder of the user with a single statement and returns
+// the deepest folder found. Ignoring case a name may match several folders, the branch matching all names exactly
+// is preferred, if there is none and several branches go equally deep - models.ErrAmbiguousPath is returned
+func (r *folderRepository) ResolvePath(userID int, names []string, caseInsensitive bool) (*models.PathMatch, error) {
+	query := `
+		WITH RECURSIVE walk AS (
+			SELECT id, 0 AS depth, TRUE AS exact
+			FROM folders
+			WHERE user_id = $1 AND parent_folder_id IS NULL AND trash_id IS NULL
+			UNION ALL
+			SELECT f.id, w.depth + 1, w.exact AND f.name = ($2::TEXT[])[w.depth + 1]
+			FROM walk w
+			JOIN folders f ON f.parent_folder_id = w.id AND f.trash_id IS NULL
+			WHERE w.depth < cardinality($2::TEXT[])
+				AND (f.name = ($2::TEXT[])[w.depth + 1] OR ($3 AND lower(f.name) = lower(($2::TEXT[])[w.depth + 1])))
+		)
+		SELECT id, depth, exact FROM walk
+		WHERE depth = (SELECT MAX(depth) FROM walk)
+		ORDER BY exact DESC
+		LIMIT 2
+	`
+	rows, err := r.db.Query(query, userID, pq.Array(names), caseInsensitive)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []models.PathMatch
+	var exact bool
+	for rows.Next() {
+		var match models.PathMatch
+		var matchExact bool
+		if err = rows.Scan(&match.FolderID, &match.Depth, &matchExact); err != nil {
+			return nil, fmt.Errorf("failed to scan path folder: %w", err)
+		}
+		if len(matches) == 0 {
+			exact = matchExact
+		}
+		matches = append(matches, match)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating path folders: %w", err)
+	}
+
+	switch {
+	case len(matches) == 0:
+		return nil, fmt.Errorf("root folder not found: %w", models.ErrNotFound)
+	case !exact && len(matches) > 1:
+		return nil, fmt.Errorf("several folders match the path: %w", models.ErrAmbiguousPath)
+	}
+	return &matches[0], nil
+}
+
 // GetFoldersInfo retrieves the folder and it all parent subfolders sizes
 func (r *folderRepository) GetFoldersInfo(folderID int64) ([]models.FolderSize, error) {
 	query := `
-		SELECT id, name, size FROM folders WHERE id = $1
+		SELECT id, name, size FROM folders WHERE id = $1 AND trash_id IS NULL
 		UNION
-		SELECT id, name, size FROM folders WHERE parent_folder_id = $1
+		SELECT id, name, size FROM folders WHERE parent_folder_id = $1 AND trash_id IS NULL
 	`
 	rows, err := r.db.Query(query, folderID)
 	if err != nil {
@@ -63,21 +284,81 @@ func (r *folderRepository) GetFoldersInfo(folderID int64) ([]models.FolderSize,
 	return folders, nil
 }
 
-// GetAllParentFolders retrieves all parent folders up to the root folder
-func (r *folderRepository) GetAllParentFolders(folderID int64) ([]models.FolderSizeSimplified, error) {
+// WalkFolderTree streams the folder with its subfolders down to the depth and optionally their files
+// with a single recursive query, nodes are passed to visit in the order they should be nested in.
+// The file count of a folder includes files of all its subfolders, also below the depth.
+// If the folder doesn't exist or belongs to another user - models.ErrNotFound is returned
+func (r *folderRepository) WalkFolderTree(userID int, folderID int64, opts models.TreeOptions, visit func(models.TreeNode) error) error {
+	// every node is sorted by the names of its folders, so a folder precedes its files,
+	// which precede its subfolders with their own content
 	query := `
-		WITH RECURSIVE parent_folders AS (
-			SELECT id, size, parent_folder_id
+		WITH RECURSIVE tree AS (
+			SELECT id, name, size, 0 AS depth, ARRAY[]::TEXT[] AS names
 			FROM folders
-			WHERE id = $1
+			WHERE id = $1 AND user_id = $2 AND trash_id IS NULL
 			UNION ALL
-			SELECT f.id, f.size, f.parent_folder_id
-			FROM folders f
-			INNER JOIN parent_folders pf ON f.id = pf.parent_folder_id
+			SELECT c.id, c.name, c.size, t.depth + 1, t.names || c.name
+			FROM tree t
+			JOIN folders c ON c.parent_folder_id = t.id AND c.trash_id IS NULL
+			WHERE t.depth < $3
+		),
+		file_counts AS (
+			SELECT a.id, COUNT(*) AS file_count
+			FROM folders d
+			JOIN files fi ON fi.folder_id = d.id AND fi.trash_id IS NULL
+			CROSS JOIN LATERAL unnest(d.path) AS a(id)
+			WHERE d.path @> ARRAY[$1::BIGINT] AND d.trash_id IS NULL
+			GROUP BY a.id
 		)
+		SELECT type, id, name, size, depth, file_count
+		FROM (
+			SELECT 'folder' AS type, t.id, t.name, t.size, t.depth, COALESCE(fc.file_count, 0) AS file_count,
+				t.names, 0 AS kind
+			FROM tree t
+			LEFT JOIN file_counts fc ON fc.id = t.id
+			UNION ALL
+			SELECT 'file', fi.id, fi.name, fi.size, t.depth + 1, 0, t.names, 1
+			FROM tree t
+			JOIN files fi ON fi.folder_id = t.id AND fi.trash_id IS NULL
+			WHERE $4 AND t.depth < $3
+		) nodes
+		ORDER BY names, kind, name
+	`
+	rows, err := r.db.Query(query, folderID, userID, opts.Depth, opts.Files)
+	if err != nil {
+		return fmt.Errorf("failed to walk folder tree: %w", err)
+	}
+	defer rows.Close()
+
+	found := false
+	for rows.Next() {
+		var node models.TreeNode
+		if err := rows.Scan(&node.Type, &node.ID, &node.Name, &node.Size, &node.Depth, &node.FileCount); err != nil {
+			return fmt.Errorf("failed to scan tree node: %w", err)
+		}
+		found = true
+		if err := visit(node); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating tree rows: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("folder %d not found: %w", folderID, models.ErrNotFound)
+	}
+
+	return nil
+}
+
+// GetAllParentFolders retrieves all parent folders up to the root folder, ordered by depth from the root
+func (r *folderRepository) GetAllParentFolders(folderID int64) ([]models.FolderSizeSimplified, error) {
+	query := `
 		SELECT id, size
-		FROM parent_folders
-		ORDER BY id
+		FROM folders
+		WHERE id = ANY((SELECT path FROM folders WHERE id = $1))
+		ORDER BY cardinality(path)
 	`
 	rows, err := r.db.Query(query, folderID)
 	if err != nil {
@@ -102,25 +383,155 @@ func (r *folderRepository) GetAllParentFolders(folderID int64) ([]models.FolderS
 	return folders, nil
 }
 
+// GetBreadcrumbs retrieves folders of the path from the root folder to the folder itself,
+// if the folder doesn't exist - models.ErrNotFound is returned
+func (r *folderRepository) GetBreadcrumbs(folderID int64) ([]models.Breadcrumb, error) {
+	query := `
+		SELECT id, name
+		FROM folders
+		WHERE id = ANY((SELECT path FROM folders WHERE id = $1))
+		ORDER BY cardinality(path)
+	`
+	rows, err := r.db.Query(query, folderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve breadcrumbs: %w", err)
+	}
+	defer rows.Close()
+
+	var breadcrumbs []models.Breadcrumb
+	for rows.Next() {
+		var breadcrumb models.Breadcrumb
+		if err := rows.Scan(&breadcrumb.ID, &breadcrumb.Name); err != nil {
+			return nil, fmt.Errorf("failed to scan breadcrumb: %w", err)
+		}
+		breadcrumbs = append(breadcrumbs, breadcrumb)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating breadcrumb rows: %w", err)
+	}
+	if len(breadcrumbs) == 0 {
+		return nil, fmt.Errorf("folder %d not found: %w", folderID, models.ErrNotFound)
+	}
+
+	return breadcrumbs, nil
+}
+
 // DeleteFolder removes folder and all contents inside
 func (r *folderRepository) DeleteFolder(tx *sql.Tx, id int64) error {
-	// recursively delete the folder data
-	return r.recursiveDeleteFolder(tx, id)
+	return r.deleteFolderTree(tx, id)
 }
 
-// MoveFolder moves a folder to another folder, ensuring no cycles are created
-func (r *folderRepository) MoveFolder(tx *sql.Tx, folderID, newFolderID int64) error {
+// TrashFolder marks the folder with all subfolders as deleted with the trash entry, subfolders which are
+// already in the trash keep their own entries. If the folder is already deleted - models.ErrNotFound is returned
+func (r *folderRepository) TrashFolder(tx *sql.Tx, id, trashID int64) error {
+	query := `UPDATE folders SET trash_id = $2 WHERE path @> ARRAY[$1::BIGINT] AND trash_id IS NULL`
+	result, err := tx.Exec(query, id, trashID)
+	if err != nil {
+		return fmt.Errorf("failed to trash folder: %w", err)
+	}
+
+	trashed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to trash folder: %w", err)
+	}
+	if trashed == 0 {
+		return fmt.Errorf("folder not found: %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+// RestoreFolder brings folders deleted with the trash entry back, the deleted folder itself is put
+// into the parent folder under the provided name.
+// If the name is already taken in the parent folder - models.ErrNameConflict is returned
+func (r *folderRepository) RestoreFolder(tx *sql.Tx, trashID, folderID, parentID int64, name string) error {
+	query := `
+		UPDATE folders SET
+			trash_id = NULL,
+			parent_folder_id = CASE WHEN id = $2 THEN $3 ELSE parent_folder_id END,
+			name = CASE WHEN id = $2 THEN $4 ELSE name END
+		WHERE trash_id = $1
+	`
+	if _, err := tx.Exec(query, trashID, folderID, parentID, name); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("folder %q already exists: %w", name, models.ErrNameConflict)
+		}
+		return fmt.Errorf("failed to restore folder: %w", err)
+	}
+	return r.updateTreePaths(tx, folderID, parentID)
+}
+
+// DeleteTrashedFolders permanently deletes folders deleted with the trash entry
+func (r *folderRepository) DeleteTrashedFolders(tx *sql.Tx, trashID int64) error {
+	if _, err := tx.Exec(`DELETE FROM folders WHERE trash_id = $1`, trashID); err != nil {
+		return fmt.Errorf("failed to delete trashed folders: %w", err)
+	}
+	return nil
+}
+
+// MoveFolder moves a folder to another folder under the provided name, ensuring no cycles are created,
+// paths of the folder and its subfolders are updated in the same transaction.
+// If the name is already taken in the new parent folder - models.ErrNameConflict is returned
+func (r *folderRepository) MoveFolder(tx *sql.Tx, folderID, newFolderID int64, name string) error {
 	// check if moving folder creates a cycle
 	if err := r.checkForCycle(tx, folderID, newFolderID); err != nil {
 		return err
 	}
 
 	// update the folder's parent_folder_id
-	_, err := tx.Exec(`UPDATE folders SET parent_folder_id = $1 WHERE id = $2`, newFolderID, folderID)
+	_, err := tx.Exec(`UPDATE folders SET parent_folder_id = $1, name = $2 WHERE id = $3`, newFolderID, name, folderID)
 	if err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("folder %q already exists: %w", name, models.ErrNameConflict)
+		}
 		return fmt.Errorf("failed to move folder: %w", err)
 	}
 
+	return r.updateTreePaths(tx, folderID, newFolderID)
+}
+
+// RenameFolder changes the name of a folder,
+// if the name is already taken in the parent folder - models.ErrNameConflict is returned
+func (r *folderRepository) RenameFolder(tx *sql.Tx, id int64, name string) error {
+	query := `UPDATE folders SET name = $1, updated_at = NOW() WHERE id = $2`
+	if _, err := tx.Exec(query, name, id); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("folder %q already exists: %w", name, models.ErrNameConflict)
+		}
+		return fmt.Errorf("failed to rename folder: %w", err)
+	}
+	return nil
+}
+
+// GetFolderMaxVersions retrieves how many versions of a file are kept in the folder,
+// nil if the folder uses the default limit
+func (r *folderRepository) GetFolderMaxVersions(tx *sql.Tx, id int64) (*int, error) {
+	var maxVersions *int
+	err := tx.QueryRow(`SELECT max_versions FROM folders WHERE id = $1 AND trash_id IS NULL`, id).Scan(&maxVersions)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve folder max versions: %w", err)
+	}
+	return maxVersions, nil
+}
+
+// UpdateFolderMaxVersions sets how many versions of a file are kept in the folder, nil resets it to the default limit
+func (r *folderRepository) UpdateFolderMaxVersions(id int64, maxVersions *int) error {
+	query := `UPDATE folders SET max_versions = $1, updated_at = NOW() WHERE id = $2 AND trash_id IS NULL`
+	result, err := r.db.Exec(query, maxVersions, id)
+	if err != nil {
+		return fmt.Errorf("failed to update folder max versions: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update folder max versions: %w", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("folder not found: %w", models.ErrNotFound)
+	}
 	return nil
 }
 
@@ -134,31 +545,51 @@ func (r *folderRepository) UpdateFolderSize(id, newSize int64) error {
 	return nil
 }
 
-// IncreaseFolderSize increases a folder size and the size of all parent folders
+// IncreaseFolderSize increases a folder size and the size of all parent folders,
+// if any of them grows over its max size - *models.QuotaError is returned
 func (r *folderRepository) IncreaseFolderSize(tx *sql.Tx, id int64, size int64) error {
-	// update folder size
-	query := `UPDATE folders SET size = size + $1, updated_at = NOW() WHERE id = $2`
-	if _, err := tx.Exec(query, size, id); err != nil {
-		return fmt.Errorf("failed to increase folder size: %w", err)
-	}
+	return r.changeFolderSizes(tx, id, size)
+}
+
+// DecreaseFolderSize decreases a folder size and propagates the change to all parent folders
+func (r *folderRepository) DecreaseFolderSize(tx *sql.Tx, id, size int64) error {
+	return r.changeFolderSizes(tx, id, -size)
+}
 
-	// propagate the size difference to parent folders
-	err := r.updateParentFolderSizes(tx, id, size)
+// CheckFolderSize checks that the folder and all its parent folders can grow by the size without changing them
+func (r *folderRepository) CheckFolderSize(tx *sql.Tx, id, size int64) error {
+	query := `
+		SELECT id, size, max_size FROM folders
+		WHERE id = ANY((SELECT path FROM folders WHERE id = $1))
+			AND max_size IS NOT NULL AND size + $2 > max_size
+		ORDER BY cardinality(path) DESC
+		LIMIT 1
+	`
+	var folderID, folderSize, maxSize int64
+	err := tx.QueryRow(query, id, size).Scan(&folderID, &folderSize, &maxSize)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to check folder size: %w", err)
 	}
-	return nil
+	return &models.QuotaError{FolderID: folderID, Quota: maxSize, Used: folderSize, Requested: size}
 }
 
-// DecreaseFolderSize decreases a folder size and propagates the change to all parent folders
-func (r *folderRepository) DecreaseFolderSize(tx *sql.Tx, id, size int64) error {
-	query := `UPDATE folders SET size = size - $1, updated_at = NOW() WHERE id = $2`
-	if _, err := tx.Exec(query, size, id); err != nil {
-		return fmt.Errorf("failed to decrease folder size: %w", err)
+// UpdateFolderMaxSize sets the max size of the folder, nil removes the limit
+func (r *folderRepository) UpdateFolderMaxSize(id int64, maxSize *int64) error {
+	query := `UPDATE folders SET max_size = $1, updated_at = NOW() WHERE id = $2 AND trash_id IS NULL`
+	result, err := r.db.Exec(query, maxSize, id)
+	if err != nil {
+		return fmt.Errorf("failed to update folder max size: %w", err)
 	}
 
-	if err := r.updateParentFolderSizes(tx, id, -size); err != nil {
-		return err
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update folder max size: %w", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("folder not found: %w", models.ErrNotFound)
 	}
 	return nil
 }
@@ -192,74 +623,166 @@ func (r *folderRepository) UpdateMultipleFoldersSize(tx *sql.Tx, folders []model
 	return nil
 }
 
-// propagates size changes to all parent folders recursively
-func (r *folderRepository) updateParentFolderSizes(tx *sql.Tx, folderID, sizeDifference int64) error {
-	for {
-		var parentFolderID sql.NullInt64
-		err := tx.QueryRow(`SELECT parent_folder_id FROM folders WHERE id = $1`, folderID).Scan(&parentFolderID)
-		if err != nil {
-			return fmt.Errorf("failed to retrieve parent folder ID: %w", err)
-		}
+// CalculateFolderSizes recalculates sizes of all folders of the user from their files, every file is counted
+// by all folders of its path. A folder size counts the files and subfolders deleted together with it, so the folders in the trash
+// keep the size they are restored with. Files of unfinished upload transactions are not counted,
+// they are added to the folder sizes when the transaction completes.
+// The folders stay locked until the end of the transaction, so no size changes between the calculation and the fix
+func (r *folderRepository) CalculateFolderSizes(tx *sql.Tx, userID int) ([]models.FolderSizeCheck, error) {
+	if _, err := tx.Exec(`SELECT id FROM folders WHERE user_id = $1 ORDER BY id FOR UPDATE`, userID); err != nil {
+		return nil, fmt.Errorf("failed to lock folders: %w", err)
+	}
 
-		if !parentFolderID.Valid {
-			break
-		}
+	query := `
+		WITH content AS (
+			SELECT fi.folder_id, fi.trash_id, SUM(fi.size + fi.versions_size) AS size
+			FROM files fi
+			LEFT JOIN upload_transactions ut ON ut.id = fi.transaction_id
+			WHERE fi.user_id = $1 AND (ut.status IS NULL OR ut.status NOT IN ('pending', 'paused', 'resumed'))
+			GROUP BY fi.folder_id, fi.trash_id
+		),
+		totals AS (
+			SELECT a.id, SUM(c.size) AS size
+			FROM content c
+			JOIN folders d ON d.id = c.folder_id AND d.user_id = $1
+			JOIN folders a ON a.id = ANY(d.path) AND a.user_id = $1 AND a.trash_id IS NOT DISTINCT FROM c.trash_id
+			GROUP BY a.id
+		),
+		in_transaction AS (
+			SELECT DISTINCT a.id
+			FROM upload_transactions ut
+			JOIN folders d ON d.id = ut.folder_id AND d.user_id = $1
+			JOIN folders a ON a.id = ANY(d.path) AND a.user_id = $1
+			WHERE ut.user_id = $1 AND ut.status IN ('pending', 'paused', 'resumed')
+		)
+		SELECT f.id, COALESCE(f.size, 0), COALESCE(t.size, 0), f.id IN (SELECT id FROM in_transaction)
+		FROM folders f
+		LEFT JOIN totals t ON t.id = f.id
+		WHERE f.user_id = $1
+		ORDER BY f.id
+	`
+	rows, err := tx.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate folder sizes: %w", err)
+	}
+	defer rows.Close()
 
-		query := `UPDATE folders SET size = size + $1, updated_at = NOW() WHERE id = $2`
-		if _, err = tx.Exec(query, sizeDifference, parentFolderID.Int64); err != nil {
-			return fmt.Errorf("failed to update parent folder size: %w", err)
+	var checks []models.FolderSizeCheck
+	for rows.Next() {
+		var check models.FolderSizeCheck
+		if err = rows.Scan(&check.ID, &check.Recorded, &check.Actual, &check.InTransaction); err != nil {
+			return nil, fmt.Errorf("failed to scan folder size: %w", err)
 		}
-
-		folderID = parentFolderID.Int64
+		checks = append(checks, check)
 	}
-
-	return nil
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating folder sizes: %w", err)
+	}
+	return checks, nil
 }
 
-// recursiveDeleteFolder recursively deletes a folder and its subfolders
-func (r *folderRepository) recursiveDeleteFolder(tx *sql.Tx, folderID int64) error {
-	subfolders, err := r.GetFoldersInfo(folderID)
+// adds the size difference to the folder and all its parent folders with a single statement, whatever the depth.
+// The folders are locked in the order of their IDs, so concurrent changes of the same branch do not deadlock,
+// and stay locked until the end of the transaction, so concurrent transactions check max sizes
+// against the sizes committed before them
+func (r *folderRepository) changeFolderSizes(tx *sql.Tx, folderID, sizeDifference int64) error {
+	query := `
+		WITH locked AS (
+			SELECT id FROM folders
+			WHERE id = ANY((SELECT path FROM folders WHERE id = $2))
+			ORDER BY id
+			FOR UPDATE
+		)
+		UPDATE folders SET size = size + $1, updated_at = NOW()
+		WHERE id IN (SELECT id FROM locked)
+		RETURNING id, size, max_size, cardinality(path)
+	`
+	rows, err := tx.Query(query, sizeDifference, folderID)
 	if err != nil {
-		return fmt.Errorf("failed to retrieve subfolders for deletion: %w", err)
+		return fmt.Errorf("failed to update folder sizes: %w", err)
 	}
+	defer rows.Close()
 
-	for _, subfolder := range subfolders {
-		if subfolder.ID == folderID {
-			continue
+	var updated int
+	var exceeded *models.QuotaError
+	exceededDepth := 0
+	for rows.Next() {
+		var id, size int64
+		var maxSize sql.NullInt64
+		var depth int
+		if err = rows.Scan(&id, &size, &maxSize, &depth); err != nil {
+			return fmt.Errorf("failed to scan folder size: %w", err)
 		}
-		if err := r.recursiveDeleteFolder(tx, subfolder.ID); err != nil {
-			return err
+		updated++
+
+		// a shrinking folder is never rejected, even if it is still over the limit,
+		// the closest exceeded folder is reported
+		if sizeDifference > 0 && maxSize.Valid && size > maxSize.Int64 && depth > exceededDepth {
+			exceeded = &models.QuotaError{
+				FolderID:  id,
+				Quota:     maxSize.Int64,
+				Used:      size - sizeDifference,
+				Requested: sizeDifference,
+			}
+			exceededDepth = depth
 		}
 	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating updated folders: %w", err)
+	}
+
+	if updated == 0 {
+		return fmt.Errorf("failed to update folder size: %w", sql.ErrNoRows)
+	}
+	if exceeded != nil {
+		return exceeded
+	}
+	return nil
+}
 
-	query := `DELETE FROM folders WHERE id = $1`
+// deletes the folder and its subfolders with a single statement, subfolders which are already
+// in the trash are left for the trash purge
+func (r *folderRepository) deleteFolderTree(tx *sql.Tx, folderID int64) error {
+	query := `DELETE FROM folders WHERE path @> ARRAY[$1::BIGINT] AND (id = $1 OR trash_id IS NULL)`
 	if _, err := tx.Exec(query, folderID); err != nil {
 		return fmt.Errorf("failed to delete folder: %w", err)
 	}
-
 	return nil
 }
 
-// prevents creating a cycle in the folder hierarchy by ensuring no folder can be moved into one of its descendants
+// prevents creating a cycle in the folder hierarchy by ensuring no folder can be moved into one of its descendants,
+// the folder must not be in the path of the new parent folder
 func (r *folderRepository) checkForCycle(tx *sql.Tx, folderID, newParentFolderID int64) error {
-	currentID := newParentFolderID
-	for currentID != 0 {
-		if currentID == folderID {
-			return errors.New("moving folder would create a cycle")
-		}
-
-		var parentID sql.NullInt64
-		err := tx.QueryRow(`SELECT parent_folder_id FROM folders WHERE id = $1`, currentID).Scan(&parentID)
-		if err != nil {
-			return fmt.Errorf("failed to retrieve parent folder ID for cycle check: %w", err)
-		}
-
-		if !parentID.Valid {
-			break
-		}
-
-		currentID = parentID.Int64
+	var cycle bool
+	err := tx.QueryRow(`SELECT $2 = ANY(path) FROM folders WHERE id = $1`, newParentFolderID, folderID).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve parent folder path for cycle check: %w", err)
+	}
+	if cycle {
+		return errors.New("moving folder would create a cycle")
 	}
+	return nil
+}
 
+// updateTreePaths makes the paths of the folder and all its subfolders continue the path of its new parent folder
+func (r *folderRepository) updateTreePaths(tx *sql.Tx, folderID, parentID int64) error {
+	query := `
+		UPDATE folders SET path = (SELECT path FROM folders WHERE id = $2) || path[array_position(path, $1::BIGINT):]
+		WHERE path @> ARRAY[$1::BIGINT]
+	`
+	if _, err := tx.Exec(query, folderID, parentID); err != nil {
+		return fmt.Errorf("failed to update folder paths: %w", err)
+	}
 	return nil
 }
+
+// scans a row selected with folderEntryColumns
+func scanFolderEntry(row interface{ Scan(dest ...any) error }) (*models.FolderEntry, error) {
+	folder := &models.FolderEntry{}
+	err := row.Scan(&folder.ID, &folder.UserID, &folder.Name, &folder.ParentFolderID, &folder.Size, &folder.MaxSize,
+		&folder.CreatedAt, &folder.UpdatedAt, &folder.FileCount, &folder.FolderCount)
+	if err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
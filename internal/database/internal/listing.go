@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// listingClauses returns keyset pagination condition and ordering of the folder listing query,
+// the condition starts with AND and uses arguments from $nextArg, ties are broken by id
+func listingClauses(opts models.ListOptions, nextArg int) (condition, orderBy string, args []interface{}) {
+	column := "name"
+	switch opts.Sort {
+	case models.SortBySize:
+		column = "size"
+	case models.SortByCreatedAt:
+		column = "created_at"
+	}
+
+	direction, comparison := "ASC", ">"
+	if opts.Desc {
+		direction, comparison = "DESC", "<"
+	}
+	orderBy = fmt.Sprintf("ORDER BY %[1]s %[2]s, id %[2]s", column, direction)
+
+	if opts.After == nil || opts.After.ID == 0 {
+		return "", orderBy, nil
+	}
+
+	var value interface{}
+	switch opts.Sort {
+	case models.SortBySize:
+		value = opts.After.Size
+	case models.SortByCreatedAt:
+		value = opts.After.CreatedAt
+	default:
+		value = opts.After.Name
+	}
+	condition = fmt.Sprintf("AND (%s, id) %s ($%d, $%d)", column, comparison, nextArg, nextArg+1)
+	return condition, orderBy, []interface{}{value, opts.After.ID}
+}
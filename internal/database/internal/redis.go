@@ -15,6 +15,14 @@ import (
 
 const folderKeyPrefix = "folder_id:"
 
+// decreases the folder size only if it is cached, folders which are not cached get the size from the database
+const decreaseCachedSizeScript = `
+	if redis.call("EXISTS", KEYS[1]) == 1 then
+		return redis.call("DECRBY", KEYS[1], ARGV[1])
+	end
+	return 0
+`
+
 // GetFolderSize retrieves the size of a folder from Redis
 func (rc *redisCache) GetFolderSize(ctx context.Context, folderID int64) (int64, error) {
 	sizeStr, err := rc.client.Get(ctx, fmt.Sprintf("%s%d", folderKeyPrefix, folderID)).Result()
@@ -83,6 +91,61 @@ func (rc *redisCache) GetMultipleFolders(ctx context.Context, keys []string) ([]
 	return folders, nil
 }
 
+// DecreaseFolderSizes subtracts the sizes from the cached sizes of the folders in a single round trip,
+// folders which are not cached are skipped
+func (rc *redisCache) DecreaseFolderSizes(ctx context.Context, folders []models.FolderSizeSimplified) error {
+	if len(folders) == 0 {
+		return nil
+	}
+
+	pipe := rc.client.Pipeline()
+	for _, folder := range folders {
+		pipe.Eval(ctx, decreaseCachedSizeScript, []string{fmt.Sprintf("%s%d", folderKeyPrefix, folder.ID)}, folder.Size)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to decrease folder sizes: %w", err)
+	}
+	return nil
+}
+
+// RefreshFolderSizes overwrites the cached sizes which differ from the provided ones and returns the replaced values.
+// Folders which are not cached are skipped, they are cached when a transaction needs them
+func (rc *redisCache) RefreshFolderSizes(ctx context.Context, folders []models.FolderSizeSimplified) ([]models.FolderSizeSimplified, error) {
+	if len(folders) == 0 {
+		return nil, nil
+	}
+
+	keys := make([]string, len(folders))
+	for i, folder := range folders {
+		keys[i] = fmt.Sprintf("%s%d", folderKeyPrefix, folder.ID)
+	}
+	cached, err := rc.GetMultipleFolders(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	sizes := make(map[int64]int64, len(folders))
+	for _, folder := range folders {
+		sizes[folder.ID] = folder.Size
+	}
+
+	var stale []models.FolderSizeSimplified
+	pipe := rc.client.Pipeline()
+	for _, folder := range cached {
+		if size := sizes[folder.ID]; size != folder.Size {
+			pipe.Set(ctx, fmt.Sprintf("%s%d", folderKeyPrefix, folder.ID), size, 0)
+			stale = append(stale, folder)
+		}
+	}
+	if len(stale) == 0 {
+		return nil, nil
+	}
+	if _, err = pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("failed to refresh folder sizes: %w", err)
+	}
+	return stale, nil
+}
+
 // sets the folder size if it is not already set
 func (rc *redisCache) setSize(ctx context.Context, key string, size int64) error {
 	return rc.client.Set(ctx, key, size, 0).Err()
@@ -23,6 +23,26 @@ type fileRepository struct {
 	db *sql.DB
 }
 
+type uploadRepository struct {
+	db *sql.DB
+}
+
+type blobRepository struct {
+	db *sql.DB
+}
+
+type trashRepository struct {
+	db *sql.DB
+}
+
+type fileVersionRepository struct {
+	db *sql.DB
+}
+
+type userRepository struct {
+	db *sql.DB
+}
+
 func NewRedisCache(client *redis.Client) _interface.FolderSizeCache {
 	return &redisCache{client: client}
 }
@@ -38,3 +58,23 @@ func NewTransactionRepository(db *sql.DB) _interface.TransactionRepository {
 func NewFileRepository(db *sql.DB) _interface.FileRepository {
 	return &fileRepository{db: db}
 }
+
+func NewUploadRepository(db *sql.DB) _interface.UploadRepository {
+	return &uploadRepository{db: db}
+}
+
+func NewBlobRepository(db *sql.DB) _interface.BlobRepository {
+	return &blobRepository{db: db}
+}
+
+func NewTrashRepository(db *sql.DB) _interface.TrashRepository {
+	return &trashRepository{db: db}
+}
+
+func NewFileVersionRepository(db *sql.DB) _interface.FileVersionRepository {
+	return &fileVersionRepository{db: db}
+}
+
+func NewUserRepository(db *sql.DB) _interface.UserRepository {
+	return &userRepository{db: db}
+}
@@ -4,60 +4,236 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/lib/pq"
 	"github.com/saur4ig/file-storage/internal/models"
 )
 
-// CreateTransaction inserts a new upload transaction into the database and returns the created transaction ID
-func (r *transactionRepository) CreateTransaction(userID int, folderID int64) (int64, error) {
-	const pendingStatus = "pending"
-	var transactionID int64
+// columns of the transaction with the totals of its files, should be scanned with scanTransaction
+const transactionColumns = `
+	id, user_id, folder_id, status, created_at, updated_at, expires_at, paused_at,
+	(SELECT COUNT(*) FROM files WHERE transaction_id = upload_transactions.id AND trash_id IS NULL),
+	(SELECT COALESCE(SUM(size + versions_size), 0) FROM files
+	 WHERE transaction_id = upload_transactions.id AND trash_id IS NULL)
+`
+
+// CreateTransaction inserts a new upload transaction into the database and returns it
+func (r *transactionRepository) CreateTransaction(userID int, folderID int64, expiresAt time.Time) (*models.UploadTransaction, error) {
 	query := `
-		INSERT INTO upload_transactions (user_id, folder_id, status) 
-		VALUES ($1, $2, $3) 
-		RETURNING id
-	`
-	err := r.db.QueryRow(query, userID, folderID, pendingStatus).Scan(&transactionID)
+		INSERT INTO upload_transactions (user_id, folder_id, status, expires_at) 
+		VALUES ($1, $2, $3, $4) 
+		RETURNING ` + transactionColumns
+	transaction, err := scanTransaction(r.db.QueryRow(query, userID, folderID, models.TransactionPending, expiresAt))
 	if err != nil {
-		return 0, fmt.Errorf("failed to create transaction: %w", err)
+		return nil, fmt.Errorf("failed to create transaction: %w", err)
 	}
-	return transactionID, nil
+	return transaction, nil
 }
 
 // GetTransactionByID retrieves an upload transaction by its id
 func (r *transactionRepository) GetTransactionByID(id int64) (*models.UploadTransaction, error) {
 	query := `
-		SELECT id, user_id, folder_id, status, created_at, updated_at 
+		SELECT ` + transactionColumns + `
 		FROM upload_transactions 
 		WHERE id = $1
 	`
-	tx := &models.UploadTransaction{}
-	err := r.db.QueryRow(query, id).Scan(
-		&tx.ID,
-		&tx.UserID,
-		&tx.FolderID,
-		&tx.Status,
-		&tx.CreatedAt,
-		&tx.UpdatedAt,
-	)
+	transaction, err := scanTransaction(r.db.QueryRow(query, id))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // No transaction found
 		}
 		return nil, fmt.Errorf("failed to retrieve transaction by ID: %w", err)
 	}
-	return tx, nil
+	return transaction, nil
+}
+
+// GetUserTransactions retrieves transactions of the user matching the filter, the newest first
+func (r *transactionRepository) GetUserTransactions(userID int, filter models.TransactionFilter) ([]models.UploadTransaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM upload_transactions
+		WHERE user_id = $1 AND ($2::TEXT = '' OR status = $2) AND ($3::BIGINT = 0 OR folder_id = $3)
+		ORDER BY created_at DESC, id DESC
+	`
+	rows, err := r.db.Query(query, userID, filter.Status, filter.FolderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []models.UploadTransaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, *transaction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction rows: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// LockTransaction retrieves an upload transaction by its id and locks it until the end of the transaction,
+// if there is no such transaction - models.ErrNotFound is returned
+func (r *transactionRepository) LockTransaction(tx *sql.Tx, id int64) (*models.UploadTransaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM upload_transactions 
+		WHERE id = $1
+		FOR UPDATE
+	`
+	transaction, err := scanTransaction(tx.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("transaction %d not found: %w", id, models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to lock transaction: %w", err)
+	}
+	return transaction, nil
+}
+
+// ShareTransaction retrieves an upload transaction by its id and keeps its status from changing until the end
+// of the transaction, if there is no such transaction - models.ErrNotFound is returned
+func (r *transactionRepository) ShareTransaction(tx *sql.Tx, id int64) (*models.UploadTransaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM upload_transactions 
+		WHERE id = $1
+		FOR SHARE
+	`
+	transaction, err := scanTransaction(tx.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("transaction %d not found: %w", id, models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to share transaction: %w", err)
+	}
+	return transaction, nil
 }
 
-// UpdateTransactionStatus updates the status of a transaction
-func (r *transactionRepository) UpdateTransactionStatus(id int64, status string) error {
+// ShareFileTransaction retrieves the upload transaction the file was uploaded in and keeps its status from changing
+// until the end of the transaction, returns nil if the file was not uploaded in a transaction or doesn't exist
+func (r *transactionRepository) ShareFileTransaction(tx *sql.Tx, fileID int64) (*models.UploadTransaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM upload_transactions 
+		WHERE id = (SELECT transaction_id FROM files WHERE id = $1)
+		FOR SHARE
+	`
+	transaction, err := scanTransaction(tx.QueryRow(query, fileID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to share transaction of file(%d): %w", fileID, err)
+	}
+	return transaction, nil
+}
+
+// GetExpiredTransactions retrieves open transactions which expired before the time, the oldest first
+func (r *transactionRepository) GetExpiredTransactions(before time.Time, limit int) ([]models.UploadTransaction, error) {
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM upload_transactions
+		WHERE status = ANY($1) AND expires_at < $2
+		ORDER BY expires_at
+		LIMIT $3
+	`
+	rows, err := r.db.Query(query, pq.Array(models.OpenTransactionStatuses), before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve expired transactions: %w", err)
+	}
+	defer rows.Close()
+
+	var transactions []models.UploadTransaction
+	for rows.Next() {
+		transaction, err := scanTransaction(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan transaction: %w", err)
+		}
+		transactions = append(transactions, *transaction)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating transaction rows: %w", err)
+	}
+
+	return transactions, nil
+}
+
+// UpdateTransactionStatus moves the transaction of the user and the folder to the status, the current status
+// is compared with the ones allowed to move to it in the same statement. The ttl stops while the transaction
+// is paused, so its expiry moves by the pause when it leaves the paused status. If there is no such transaction -
+// models.ErrNotFound is returned, if its status does not allow the transition - *models.TransactionStatusError
+func (r *transactionRepository) UpdateTransactionStatus(
+	tx *sql.Tx, id int64, userID int, folderID int64, status string,
+) (*models.UploadTransaction, error) {
 	query := `
 		UPDATE upload_transactions 
-		SET status = $1, updated_at = NOW() 
-		WHERE id = $2
+		SET status = $4, updated_at = NOW(),
+			expires_at = CASE WHEN paused_at IS NULL THEN expires_at ELSE expires_at + (NOW() - paused_at) END,
+			paused_at = CASE WHEN $4 = 'paused' THEN NOW() END
+		WHERE id = $1 AND user_id = $2 AND folder_id = $3 AND status = ANY($5)
+		RETURNING ` + transactionColumns
+	sources := models.TransactionSourceStatuses(status)
+	transaction, err := scanTransaction(tx.QueryRow(query, id, userID, folderID, status, pq.Array(sources)))
+	if err == nil {
+		return transaction, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to update transaction status: %w", err)
+	}
+
+	// the transaction either doesn't exist or its status doesn't allow the transition
+	var current string
+	query = `SELECT status FROM upload_transactions WHERE id = $1 AND user_id = $2 AND folder_id = $3`
+	err = tx.QueryRow(query, id, userID, folderID).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("transaction %d not found: %w", id, models.ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction status: %w", err)
+	}
+	return nil, &models.TransactionStatusError{ID: id, Status: current, Requested: status}
+}
+
+// CreateTransactionAudit inserts the audit record of the transaction
+func (r *transactionRepository) CreateTransactionAudit(tx *sql.Tx, audit *models.TransactionAudit) error {
+	query := `
+		INSERT INTO upload_transaction_audit (transaction_id, user_id, folder_id, action, file_count, size)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
 	`
-	if _, err := r.db.Exec(query, status, id); err != nil {
-		return fmt.Errorf("failed to update transaction status: %w", err)
+	err := tx.QueryRow(query, audit.TransactionID, audit.UserID, audit.FolderID, audit.Action, audit.FileCount, audit.Size).
+		Scan(&audit.ID, &audit.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create transaction audit: %w", err)
 	}
 	return nil
 }
+
+// scans all transaction columns from a row
+func scanTransaction(row interface{ Scan(dest ...any) error }) (*models.UploadTransaction, error) {
+	transaction := &models.UploadTransaction{}
+	err := row.Scan(
+		&transaction.ID,
+		&transaction.UserID,
+		&transaction.FolderID,
+		&transaction.Status,
+		&transaction.CreatedAt,
+		&transaction.UpdatedAt,
+		&transaction.ExpiresAt,
+		&transaction.PausedAt,
+		&transaction.FileCount,
+		&transaction.TotalSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
@@ -0,0 +1,109 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// columns of the trash entry, should be scanned with scanTrashItem
+const trashColumns = `id, user_id, item_type, item_id, name, parent_folder_id, size, deleted_at`
+
+// CreateTrashItem inserts a new trash entry into the database
+func (r *trashRepository) CreateTrashItem(tx *sql.Tx, item *models.TrashItem) error {
+	query := `
+		INSERT INTO trash (user_id, item_type, item_id, name, parent_folder_id, size)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, deleted_at
+	`
+	err := tx.QueryRow(query, item.UserID, item.ItemType, item.ItemID, item.Name, item.ParentFolderID, item.Size).
+		Scan(&item.ID, &item.DeletedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create trash item: %w", err)
+	}
+	return nil
+}
+
+// GetTrashItem retrieves a trash entry by its id
+func (r *trashRepository) GetTrashItem(id int64) (*models.TrashItem, error) {
+	query := `SELECT ` + trashColumns + ` FROM trash WHERE id = $1`
+	item, err := scanTrashItem(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("trash item not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve trash item: %w", err)
+	}
+	return item, nil
+}
+
+// LockTrashItem retrieves a trash entry by its id and locks it until the end of the transaction,
+// so it can't be restored and purged at the same time
+func (r *trashRepository) LockTrashItem(tx *sql.Tx, id int64) (*models.TrashItem, error) {
+	query := `SELECT ` + trashColumns + ` FROM trash WHERE id = $1 FOR UPDATE`
+	item, err := scanTrashItem(tx.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("trash item not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to lock trash item: %w", err)
+	}
+	return item, nil
+}
+
+// ListTrashItems retrieves all trash entries of the user, the recently deleted go first
+func (r *trashRepository) ListTrashItems(userID int) ([]models.TrashItem, error) {
+	query := `SELECT ` + trashColumns + ` FROM trash WHERE user_id = $1 ORDER BY deleted_at DESC, id DESC`
+	return r.queryTrashItems(query, userID)
+}
+
+// GetExpiredTrashItems retrieves trash entries deleted before the provided time
+func (r *trashRepository) GetExpiredTrashItems(before time.Time, limit int) ([]models.TrashItem, error) {
+	query := `SELECT ` + trashColumns + ` FROM trash WHERE deleted_at < $1 ORDER BY deleted_at LIMIT $2`
+	return r.queryTrashItems(query, before, limit)
+}
+
+// DeleteTrashItem removes a trash entry, the deleted items should be already restored or purged
+func (r *trashRepository) DeleteTrashItem(tx *sql.Tx, id int64) error {
+	if _, err := tx.Exec(`DELETE FROM trash WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete trash item: %w", err)
+	}
+	return nil
+}
+
+func (r *trashRepository) queryTrashItems(query string, args ...interface{}) ([]models.TrashItem, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve trash items: %w", err)
+	}
+	defer rows.Close()
+
+	var items []models.TrashItem
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %w", err)
+		}
+		items = append(items, *item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating trash rows: %w", err)
+	}
+
+	return items, nil
+}
+
+// scans a row selected with trashColumns
+func scanTrashItem(row interface{ Scan(dest ...any) error }) (*models.TrashItem, error) {
+	item := &models.TrashItem{}
+	err := row.Scan(&item.ID, &item.UserID, &item.ItemType, &item.ItemID, &item.Name, &item.ParentFolderID, &item.Size,
+		&item.DeletedAt)
+	if err != nil {
+		return nil, err
+	}
+	return item, nil
+}
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// CreateUpload inserts a new resumable upload into the database
+func (r *uploadRepository) CreateUpload(upload *models.Upload) error {
+	query := `
+		INSERT INTO uploads (id, user_id, folder_id, name, length, metadata, on_conflict, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING upload_offset, parts, created_at, updated_at
+	`
+	err := r.db.QueryRow(query, upload.ID, upload.UserID, upload.FolderID, upload.Name, upload.Length, upload.Metadata, upload.OnConflict,
+		upload.ExpiresAt).
+		Scan(&upload.Offset, &upload.Parts, &upload.CreatedAt, &upload.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create upload: %w", err)
+	}
+	return nil
+}
+
+// GetUploadByID retrieves an upload by its id
+func (r *uploadRepository) GetUploadByID(id string) (*models.Upload, error) {
+	query := `
+		SELECT id, user_id, folder_id, name, length, upload_offset, parts, metadata, on_conflict, expires_at, created_at, updated_at
+		FROM uploads
+		WHERE id = $1
+	`
+	upload, err := scanUpload(r.db.QueryRow(query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("upload not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve upload by ID: %w", err)
+	}
+	return upload, nil
+}
+
+// MoveUploadOffset adds size to the upload offset, if it is still equal to offset.
+// The offset is compared and updated in a single statement, so concurrent chunks can't be both accepted
+func (r *uploadRepository) MoveUploadOffset(tx *sql.Tx, id string, offset, size int64, expiresAt time.Time) (*models.Upload, error) {
+	query := `
+		UPDATE uploads
+		SET upload_offset = upload_offset + $1, parts = parts + 1, expires_at = $2, updated_at = NOW()
+		WHERE id = $3 AND upload_offset = $4
+		RETURNING id, user_id, folder_id, name, length, upload_offset, parts, metadata, on_conflict, expires_at, created_at, updated_at
+	`
+	upload, err := scanUpload(tx.QueryRow(query, size, expiresAt, id, offset))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, models.ErrOffsetMismatch
+		}
+		return nil, fmt.Errorf("failed to update upload offset: %w", err)
+	}
+	return upload, nil
+}
+
+// CreateUploadPart inserts a received part of the upload
+func (r *uploadRepository) CreateUploadPart(tx *sql.Tx, part *models.UploadPart) error {
+	query := `INSERT INTO upload_parts (upload_id, part_number, s3_url, size) VALUES ($1, $2, $3, $4)`
+	if _, err := tx.Exec(query, part.UploadID, part.PartNumber, part.S3URL, part.Size); err != nil {
+		return fmt.Errorf("failed to create upload part: %w", err)
+	}
+	return nil
+}
+
+// GetUploadParts returns all parts of the upload in the order they were received
+func (r *uploadRepository) GetUploadParts(id string) ([]models.UploadPart, error) {
+	query := `
+		SELECT upload_id, part_number, s3_url, size
+		FROM upload_parts
+		WHERE upload_id = $1
+		ORDER BY part_number
+	`
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve upload parts: %w", err)
+	}
+	defer rows.Close()
+
+	var parts []models.UploadPart
+	for rows.Next() {
+		var part models.UploadPart
+		if err := rows.Scan(&part.UploadID, &part.PartNumber, &part.S3URL, &part.Size); err != nil {
+			return nil, fmt.Errorf("failed to scan upload part: %w", err)
+		}
+		parts = append(parts, part)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating upload part rows: %w", err)
+	}
+
+	return parts, nil
+}
+
+// DeleteUpload deletes the upload with all its parts
+func (r *uploadRepository) DeleteUpload(id string) error {
+	if _, err := r.db.Exec(`DELETE FROM uploads WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	return nil
+}
+
+// GetExpiredUploads retrieves uploads which expired before the provided time
+func (r *uploadRepository) GetExpiredUploads(before time.Time, limit int) ([]models.Upload, error) {
+	query := `
+		SELECT id, user_id, folder_id, name, length, upload_offset, parts, metadata, on_conflict, expires_at, created_at, updated_at
+		FROM uploads
+		WHERE expires_at < $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+	rows, err := r.db.Query(query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve expired uploads: %w", err)
+	}
+	defer rows.Close()
+
+	var uploads []models.Upload
+	for rows.Next() {
+		upload, err := scanUpload(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan upload: %w", err)
+		}
+		uploads = append(uploads, *upload)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating upload rows: %w", err)
+	}
+
+	return uploads, nil
+}
+
+// scans all upload columns from a row
+func scanUpload(row interface{ Scan(dest ...any) error }) (*models.Upload, error) {
+	upload := &models.Upload{}
+	err := row.Scan(
+		&upload.ID,
+		&upload.UserID,
+		&upload.FolderID,
+		&upload.Name,
+		&upload.Length,
+		&upload.Offset,
+		&upload.Parts,
+		&upload.Metadata,
+		&upload.OnConflict,
+		&upload.ExpiresAt,
+		&upload.CreatedAt,
+		&upload.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
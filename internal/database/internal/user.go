@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// usage of the user: sizes of the root folders and files uploaded in unfinished (pending, paused or resumed) transactions,
+// which are not counted by folder sizes yet
+const userUsageQuery = `
+	SELECT
+		(SELECT COALESCE(SUM(size), 0) FROM folders
+		 WHERE user_id = $1 AND parent_folder_id IS NULL AND trash_id IS NULL),
+		(SELECT COALESCE(SUM(f.size + f.versions_size), 0)
+		 FROM upload_transactions t
+		 JOIN files f ON f.transaction_id = t.id AND f.user_id = t.user_id
+		 WHERE t.user_id = $1 AND t.status IN ('pending', 'paused', 'resumed') AND f.trash_id IS NULL)
+`
+
+// GetUserIDs returns IDs of all users
+func (r *userRepository) GetUserIDs() ([]int, error) {
+	rows, err := r.db.Query(`SELECT id FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve users: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating users: %w", err)
+	}
+	return ids, nil
+}
+
+// GetUserQuota retrieves the quota of the user with the current usage
+func (r *userRepository) GetUserQuota(userID int) (*models.UserQuota, error) {
+	quota := &models.UserQuota{UserID: userID}
+	err := r.db.QueryRow(`SELECT quota FROM users WHERE id = $1`, userID).Scan(&quota.Quota)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve user quota: %w", err)
+	}
+
+	if err = r.db.QueryRow(userUsageQuery, userID).Scan(&quota.Used, &quota.Pending); err != nil {
+		return nil, fmt.Errorf("failed to retrieve user usage: %w", err)
+	}
+	return quota, nil
+}
+
+// LockUserQuota retrieves the quota of the user and locks the user until the end of the transaction.
+// The usage is read after the lock is taken, so it includes changes of the transactions which held it before
+func (r *userRepository) LockUserQuota(tx *sql.Tx, userID int) (*models.UserQuota, error) {
+	quota := &models.UserQuota{UserID: userID}
+	err := tx.QueryRow(`SELECT quota FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&quota.Quota)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to lock user quota: %w", err)
+	}
+
+	if err = tx.QueryRow(userUsageQuery, userID).Scan(&quota.Used, &quota.Pending); err != nil {
+		return nil, fmt.Errorf("failed to retrieve user usage: %w", err)
+	}
+	return quota, nil
+}
+
+// GetUserSettings retrieves the settings of the user
+func (r *userRepository) GetUserSettings(userID int) (*models.UserSettings, error) {
+	settings := &models.UserSettings{UserID: userID}
+	err := r.db.QueryRow(`SELECT case_insensitive_paths FROM users WHERE id = $1`, userID).
+		Scan(&settings.CaseInsensitivePaths)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("user not found: %w", models.ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to retrieve user settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateUserSettings saves the settings of the user
+func (r *userRepository) UpdateUserSettings(settings *models.UserSettings) error {
+	result, err := r.db.Exec(
+		`UPDATE users SET case_insensitive_paths = $2 WHERE id = $1`,
+		settings.UserID, settings.CaseInsensitivePaths,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update user settings: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update user settings: %w", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("user not found: %w", models.ErrNotFound)
+	}
+	return nil
+}
+
+// UpdateUserQuota sets the quota of the user
+func (r *userRepository) UpdateUserQuota(userID int, quota *int64) error {
+	result, err := r.db.Exec(`UPDATE users SET quota = $2 WHERE id = $1`, userID, quota)
+	if err != nil {
+		return fmt.Errorf("failed to update user quota: %w", err)
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to update user quota: %w", err)
+	}
+	if updated == 0 {
+		return fmt.Errorf("user not found: %w", models.ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,8 @@
+-- Drop the upload_parts table
+DROP TABLE IF EXISTS upload_parts;
+
+-- Drop the uploads table
+DROP TABLE IF EXISTS uploads;
+
+-- Drop indexes if they exist
+DROP INDEX IF EXISTS idx_upload_expires;
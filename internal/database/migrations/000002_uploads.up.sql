@@ -0,0 +1,34 @@
+-- Create uploads table to keep the state of resumable (tus) uploads
+CREATE TABLE uploads (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    folder_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    length BIGINT NOT NULL CHECK (length >= 0),
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    parts INT NOT NULL DEFAULT 0,
+    metadata TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (upload_offset <= length)
+);
+
+-- Create upload_parts table, every received chunk is stored as a separate object until the upload is finished
+CREATE TABLE upload_parts (
+    upload_id VARCHAR(64) NOT NULL,
+    part_number INT NOT NULL,
+    s3_url TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    PRIMARY KEY (upload_id, part_number)
+);
+
+-- Add foreign key constraints
+ALTER TABLE uploads ADD CONSTRAINT fk_uploads_user
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+ALTER TABLE upload_parts ADD CONSTRAINT fk_upload_parts_upload
+    FOREIGN KEY (upload_id) REFERENCES uploads(id) ON DELETE CASCADE;
+
+-- Index to find expired uploads
+CREATE INDEX idx_upload_expires ON uploads(expires_at);
@@ -0,0 +1,3 @@
+-- Drop content checksums of files
+ALTER TABLE files DROP COLUMN IF EXISTS md5;
+ALTER TABLE files DROP COLUMN IF EXISTS checksum;
@@ -0,0 +1,3 @@
+-- Add content checksums to files, NULL for the files uploaded before checksums were calculated
+ALTER TABLE files ADD COLUMN checksum VARCHAR(64);
+ALTER TABLE files ADD COLUMN md5 VARCHAR(32);
@@ -0,0 +1,4 @@
+-- Drop blobs, files keep pointing to the objects by s3_url
+ALTER TABLE files DROP CONSTRAINT IF EXISTS fk_files_blob;
+ALTER TABLE files DROP COLUMN IF EXISTS blob_checksum;
+DROP TABLE IF EXISTS blobs;
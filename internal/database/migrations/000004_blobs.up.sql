@@ -0,0 +1,30 @@
+-- Create blobs table, every stored object is shared by all files with the same content
+CREATE TABLE blobs (
+    checksum VARCHAR(64) PRIMARY KEY,
+    storage_key TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    ref_count INT NOT NULL DEFAULT 1 CHECK (ref_count >= 0),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Files referencing a blob, NULL for the files which own their objects
+ALTER TABLE files ADD COLUMN blob_checksum VARCHAR(64);
+
+-- Files uploaded with checksums before deduplication: one object per checksum becomes a blob,
+-- files with other copies of the same content keep owning them
+INSERT INTO blobs (checksum, storage_key, size, ref_count)
+SELECT checksum, MIN(s3_url), MIN(size), 0
+FROM files
+WHERE checksum IS NOT NULL
+GROUP BY checksum;
+
+UPDATE files f SET blob_checksum = f.checksum
+FROM blobs b
+WHERE b.checksum = f.checksum AND b.storage_key = f.s3_url;
+
+UPDATE blobs b SET ref_count = (SELECT COUNT(*) FROM files f WHERE f.blob_checksum = b.checksum);
+
+ALTER TABLE files ADD CONSTRAINT fk_files_blob
+    FOREIGN KEY (blob_checksum) REFERENCES blobs(checksum);
+
+CREATE INDEX idx_file_blob ON files(blob_checksum);
@@ -0,0 +1,8 @@
+-- Drop the folder listing indexes
+DROP INDEX IF EXISTS idx_file_folder_created;
+DROP INDEX IF EXISTS idx_file_folder_size;
+DROP INDEX IF EXISTS idx_file_folder_name;
+
+DROP INDEX IF EXISTS idx_folder_parent_created;
+DROP INDEX IF EXISTS idx_folder_parent_size;
+DROP INDEX IF EXISTS idx_folder_parent_name;
@@ -0,0 +1,8 @@
+-- Indexes for the keyset pagination of the folder listing, one per sort key
+CREATE INDEX idx_folder_parent_name ON folders(parent_folder_id, name, id);
+CREATE INDEX idx_folder_parent_size ON folders(parent_folder_id, size, id);
+CREATE INDEX idx_folder_parent_created ON folders(parent_folder_id, created_at, id);
+
+CREATE INDEX idx_file_folder_name ON files(folder_id, name, id);
+CREATE INDEX idx_file_folder_size ON files(folder_id, size, id);
+CREATE INDEX idx_file_folder_created ON files(folder_id, created_at, id);
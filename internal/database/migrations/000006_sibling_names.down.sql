@@ -0,0 +1,4 @@
+-- Drop sibling names uniqueness, renamed duplicates keep their new names
+ALTER TABLE uploads DROP COLUMN IF EXISTS on_conflict;
+ALTER TABLE files DROP CONSTRAINT IF EXISTS uq_files_sibling_name;
+ALTER TABLE folders DROP CONSTRAINT IF EXISTS uq_folders_sibling_name;
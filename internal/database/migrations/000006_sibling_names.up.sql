@@ -0,0 +1,24 @@
+-- Names of existing duplicates get their id as a suffix, so the unique constraints can be created
+UPDATE folders f SET name = f.name || ' (' || f.id || ')'
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, parent_folder_id, name ORDER BY id) AS n
+    FROM folders
+    WHERE parent_folder_id IS NOT NULL
+) d
+WHERE d.id = f.id AND d.n > 1;
+
+UPDATE files f SET name = f.name || ' (' || f.id || ')'
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, folder_id, name ORDER BY id) AS n
+    FROM files
+) d
+WHERE d.id = f.id AND d.n > 1;
+
+-- Names of subfolders and of files are unique within their parent folder,
+-- user_id is a part of the constraints because the tables are partitioned by it
+ALTER TABLE folders ADD CONSTRAINT uq_folders_sibling_name UNIQUE (user_id, parent_folder_id, name);
+ALTER TABLE files ADD CONSTRAINT uq_files_sibling_name UNIQUE (user_id, folder_id, name);
+
+-- Name conflict policy applied when a resumable upload is saved as a file
+ALTER TABLE uploads ADD COLUMN on_conflict VARCHAR(16) NOT NULL DEFAULT 'fail'
+    CHECK (on_conflict IN ('fail', 'rename', 'replace'));
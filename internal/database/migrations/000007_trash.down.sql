@@ -0,0 +1,24 @@
+-- Items still in the trash are deleted permanently, blobs lose their references
+UPDATE blobs b SET ref_count = b.ref_count - t.refs
+FROM (
+    SELECT blob_checksum, COUNT(*) AS refs
+    FROM files
+    WHERE trash_id IS NOT NULL AND blob_checksum IS NOT NULL
+    GROUP BY blob_checksum
+) t
+WHERE b.checksum = t.blob_checksum;
+
+DELETE FROM files WHERE trash_id IS NOT NULL;
+DELETE FROM folders WHERE trash_id IS NOT NULL;
+DELETE FROM blobs WHERE ref_count = 0;
+
+DROP INDEX IF EXISTS uq_files_sibling_name;
+DROP INDEX IF EXISTS uq_folders_sibling_name;
+
+ALTER TABLE folders ADD CONSTRAINT uq_folders_sibling_name UNIQUE (user_id, parent_folder_id, name);
+ALTER TABLE files ADD CONSTRAINT uq_files_sibling_name UNIQUE (user_id, folder_id, name);
+
+ALTER TABLE files DROP COLUMN IF EXISTS trash_id;
+ALTER TABLE folders DROP COLUMN IF EXISTS trash_id;
+
+DROP TABLE IF EXISTS trash;
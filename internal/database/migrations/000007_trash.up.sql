@@ -0,0 +1,29 @@
+-- Trash entries, every entry is a folder or a file deleted by the user together with all its content
+CREATE TABLE trash (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    item_type VARCHAR(16) NOT NULL CHECK (item_type IN ('folder', 'file')),
+    item_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    -- the folder the item is restored to, if it still exists
+    parent_folder_id BIGINT NOT NULL,
+    size BIGINT NOT NULL,
+    deleted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_trash_user ON trash(user_id, deleted_at);
+CREATE INDEX idx_trash_deleted_at ON trash(deleted_at);
+
+-- Deleted folders and files reference the trash entry they are deleted with, NULL for the live ones
+ALTER TABLE folders ADD COLUMN trash_id BIGINT;
+ALTER TABLE files ADD COLUMN trash_id BIGINT;
+
+CREATE INDEX idx_folder_trash ON folders(trash_id) WHERE trash_id IS NOT NULL;
+CREATE INDEX idx_file_trash ON files(trash_id) WHERE trash_id IS NOT NULL;
+
+-- Names of the deleted items can be taken by new ones
+ALTER TABLE folders DROP CONSTRAINT uq_folders_sibling_name;
+ALTER TABLE files DROP CONSTRAINT uq_files_sibling_name;
+
+CREATE UNIQUE INDEX uq_folders_sibling_name ON folders(user_id, parent_folder_id, name) WHERE trash_id IS NULL;
+CREATE UNIQUE INDEX uq_files_sibling_name ON files(user_id, folder_id, name) WHERE trash_id IS NULL;
@@ -0,0 +1,25 @@
+-- Previous versions are dropped, their blobs lose the references
+UPDATE blobs b SET ref_count = b.ref_count - v.count
+FROM (SELECT blob_checksum, COUNT(*) AS count FROM file_versions WHERE blob_checksum IS NOT NULL GROUP BY blob_checksum) v
+WHERE b.checksum = v.blob_checksum;
+
+-- folder sizes don't count them anymore, every ancestor of the file loses its versions size
+WITH RECURSIVE versions AS (
+    SELECT folder_id AS id, SUM(versions_size) AS size FROM files WHERE versions_size > 0 GROUP BY folder_id
+), ancestors AS (
+    SELECT id, size FROM versions
+    UNION ALL
+    SELECT f.parent_folder_id, a.size FROM ancestors a JOIN folders f ON f.id = a.id WHERE f.parent_folder_id IS NOT NULL
+)
+UPDATE folders f SET size = f.size - a.size
+FROM (SELECT id, SUM(size) AS size FROM ancestors GROUP BY id) a
+WHERE f.id = a.id;
+
+DROP TABLE file_versions;
+DELETE FROM blobs WHERE ref_count = 0;
+
+ALTER TABLE folders DROP COLUMN max_versions;
+
+ALTER TABLE files DROP COLUMN updated_at;
+ALTER TABLE files DROP COLUMN versions_size;
+ALTER TABLE files DROP COLUMN version;
@@ -0,0 +1,28 @@
+-- Previous versions of files, the current version is kept in the file itself
+CREATE TABLE file_versions (
+    id BIGSERIAL PRIMARY KEY,
+    file_id BIGINT NOT NULL,
+    user_id INT NOT NULL,
+    version INT NOT NULL,
+    s3_url TEXT NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64),
+    md5 VARCHAR(32),
+    -- the blob with the content, NULL for the versions which own their objects
+    blob_checksum VARCHAR(64) REFERENCES blobs(checksum),
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (file_id, version)
+);
+
+CREATE INDEX idx_file_version_blob ON file_versions(blob_checksum);
+
+-- Number of the current version and the total size of the retained previous ones,
+-- folder sizes count both the current content and all retained versions
+ALTER TABLE files ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE files ADD COLUMN versions_size BIGINT NOT NULL DEFAULT 0;
+-- the time the current version was uploaded
+ALTER TABLE files ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+UPDATE files SET updated_at = created_at;
+
+-- How many versions of a file, including the current one, are kept in the folder, NULL for the configured default
+ALTER TABLE folders ADD COLUMN max_versions INT CHECK (max_versions > 0);
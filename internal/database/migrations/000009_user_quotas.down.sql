@@ -0,0 +1,4 @@
+DROP INDEX idx_file_transaction;
+DROP INDEX idx_upload_transaction_user;
+
+ALTER TABLE users DROP COLUMN quota;
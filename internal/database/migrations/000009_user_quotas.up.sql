@@ -0,0 +1,6 @@
+-- Storage quota of the user in bytes, NULL for unlimited
+ALTER TABLE users ADD COLUMN quota BIGINT CHECK (quota >= 0);
+
+-- Files uploaded in unfinished transactions are counted by the quota before folder sizes include them
+CREATE INDEX idx_upload_transaction_user ON upload_transactions(user_id) WHERE status = 'pending';
+CREATE INDEX idx_file_transaction ON files(transaction_id) WHERE transaction_id IS NOT NULL;
@@ -0,0 +1,2 @@
+-- Max size of the folder with all its content in bytes, NULL for unlimited
+ALTER TABLE folders ADD COLUMN max_size BIGINT CHECK (max_size >= 0);
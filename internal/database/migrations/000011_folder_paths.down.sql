@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_folder_path;
+
+ALTER TABLE folders DROP COLUMN path;
@@ -0,0 +1,17 @@
+-- IDs of the folders from the root down to the folder itself, parents and subfolders of a folder
+-- are selected by the path instead of walking parent_folder_id one level at a time
+ALTER TABLE folders ADD COLUMN path BIGINT[];
+
+WITH RECURSIVE paths AS (
+    SELECT id, ARRAY[id] AS path FROM folders WHERE parent_folder_id IS NULL
+    UNION ALL
+    SELECT f.id, p.path || f.id FROM folders f JOIN paths p ON f.parent_folder_id = p.id
+)
+UPDATE folders f SET path = p.path FROM paths p WHERE f.id = p.id;
+
+-- folders whose parent no longer exists become roots of their own trees
+UPDATE folders SET path = ARRAY[id] WHERE path IS NULL;
+
+ALTER TABLE folders ALTER COLUMN path SET NOT NULL;
+
+CREATE INDEX idx_folder_path ON folders USING GIN (path);
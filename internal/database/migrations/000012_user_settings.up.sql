@@ -0,0 +1,2 @@
+-- Whether paths of the user resolve folder and file names ignoring case
+ALTER TABLE users ADD COLUMN case_insensitive_paths BOOLEAN NOT NULL DEFAULT FALSE;
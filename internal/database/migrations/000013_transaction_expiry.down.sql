@@ -0,0 +1,4 @@
+DROP TABLE upload_transaction_audit;
+
+DROP INDEX idx_upload_transaction_expires;
+ALTER TABLE upload_transactions DROP COLUMN expires_at;
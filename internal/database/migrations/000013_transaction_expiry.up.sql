@@ -0,0 +1,19 @@
+-- Pending upload transactions expire, expired ones are rolled back by the sweeper.
+-- Transactions started before get an hour from their start
+ALTER TABLE upload_transactions ADD COLUMN expires_at TIMESTAMP;
+UPDATE upload_transactions SET expires_at = COALESCE(created_at, NOW()) + INTERVAL '1 hour';
+ALTER TABLE upload_transactions ALTER COLUMN expires_at SET NOT NULL;
+CREATE INDEX idx_upload_transaction_expires ON upload_transactions(expires_at) WHERE status = 'pending';
+
+-- Transactions ended by the service itself, with the files removed together with them
+CREATE TABLE upload_transaction_audit (
+    id BIGSERIAL PRIMARY KEY,
+    transaction_id BIGINT NOT NULL,
+    user_id INT NOT NULL,
+    folder_id BIGINT NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    file_count INT NOT NULL,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_upload_transaction_audit_transaction ON upload_transaction_audit(transaction_id);
@@ -0,0 +1,7 @@
+DROP INDEX idx_upload_transaction_user;
+CREATE INDEX idx_upload_transaction_user ON upload_transactions(user_id) WHERE status = 'pending';
+
+UPDATE upload_transactions SET status = 'pending', updated_at = NOW() WHERE status = 'paused';
+ALTER TABLE upload_transactions DROP CONSTRAINT upload_transactions_status_check;
+ALTER TABLE upload_transactions ADD CONSTRAINT upload_transactions_status_check
+    CHECK (status IN ('pending', 'completed', 'failed'));
@@ -0,0 +1,7 @@
+-- Paused transactions are unfinished as pending ones, files uploaded in them are counted by the quota
+ALTER TABLE upload_transactions DROP CONSTRAINT upload_transactions_status_check;
+ALTER TABLE upload_transactions ADD CONSTRAINT upload_transactions_status_check
+    CHECK (status IN ('pending', 'paused', 'completed', 'failed'));
+
+DROP INDEX idx_upload_transaction_user;
+CREATE INDEX idx_upload_transaction_user ON upload_transactions(user_id) WHERE status IN ('pending', 'paused');
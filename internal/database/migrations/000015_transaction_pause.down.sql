@@ -0,0 +1 @@
+ALTER TABLE upload_transactions DROP COLUMN paused_at;
@@ -0,0 +1,2 @@
+-- The ttl of a paused transaction doesn't run, its expiry moves by the time it was paused for when it is resumed
+ALTER TABLE upload_transactions ADD COLUMN paused_at TIMESTAMP;
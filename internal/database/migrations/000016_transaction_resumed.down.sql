@@ -0,0 +1,10 @@
+DROP INDEX idx_upload_transaction_expires;
+CREATE INDEX idx_upload_transaction_expires ON upload_transactions(expires_at) WHERE status = 'pending';
+
+DROP INDEX idx_upload_transaction_user;
+CREATE INDEX idx_upload_transaction_user ON upload_transactions(user_id) WHERE status IN ('pending', 'paused');
+
+UPDATE upload_transactions SET status = 'pending', updated_at = NOW() WHERE status = 'resumed';
+ALTER TABLE upload_transactions DROP CONSTRAINT upload_transactions_status_check;
+ALTER TABLE upload_transactions ADD CONSTRAINT upload_transactions_status_check
+    CHECK (status IN ('pending', 'paused', 'completed', 'failed'));
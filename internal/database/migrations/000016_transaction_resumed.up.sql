@@ -0,0 +1,10 @@
+-- Resumed transactions are open as pending ones: they accept uploads, expire and are counted by the quota
+ALTER TABLE upload_transactions DROP CONSTRAINT upload_transactions_status_check;
+ALTER TABLE upload_transactions ADD CONSTRAINT upload_transactions_status_check
+    CHECK (status IN ('pending', 'paused', 'resumed', 'completed', 'failed'));
+
+DROP INDEX idx_upload_transaction_user;
+CREATE INDEX idx_upload_transaction_user ON upload_transactions(user_id) WHERE status IN ('pending', 'paused', 'resumed');
+
+DROP INDEX idx_upload_transaction_expires;
+CREATE INDEX idx_upload_transaction_expires ON upload_transactions(expires_at) WHERE status IN ('pending', 'resumed');
@@ -23,3 +23,23 @@ func NewTransactionRepository(db *sql.DB) _interface.TransactionRepository {
 func NewFileRepository(db *sql.DB) _interface.FileRepository {
 	return internal.NewFileRepository(db)
 }
+
+func NewUploadRepository(db *sql.DB) _interface.UploadRepository {
+	return internal.NewUploadRepository(db)
+}
+
+func NewBlobRepository(db *sql.DB) _interface.BlobRepository {
+	return internal.NewBlobRepository(db)
+}
+
+func NewTrashRepository(db *sql.DB) _interface.TrashRepository {
+	return internal.NewTrashRepository(db)
+}
+
+func NewFileVersionRepository(db *sql.DB) _interface.FileVersionRepository {
+	return internal.NewFileVersionRepository(db)
+}
+
+func NewUserRepository(db *sql.DB) _interface.UserRepository {
+	return internal.NewUserRepository(db)
+}
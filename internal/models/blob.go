@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// Blob represents a stored object shared by all files with the same content.
+type Blob struct {
+	// Checksum is hex encoded SHA-256 of the content, it identifies the blob
+	Checksum   string    `db:"checksum"`
+	StorageKey string    `db:"storage_key"`
+	Size       int64     `db:"size"`
+	RefCount   int       `db:"ref_count"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// StorageStats describes how much space files take before and after deduplication
+type StorageStats struct {
+	Files int64
+	// LogicalSize is the total size of all files with their versions, as it is reported for folders
+	LogicalSize int64
+	// Objects is the number of objects in the file storage
+	Objects int64
+	// PhysicalSize is the total size of all objects in the file storage
+	PhysicalSize int64
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when the requested record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrOffsetMismatch is returned when a chunk is sent for the wrong offset of the upload
+	ErrOffsetMismatch = errors.New("upload offset mismatch")
+	// ErrUploadExpired is returned when the upload is not finished in time
+	ErrUploadExpired = errors.New("upload expired")
+	// ErrTransactionStatus is returned when the status of an upload transaction does not allow the request
+	ErrTransactionStatus = errors.New("invalid transaction status")
+	// ErrInvalidTTL is returned when a transaction is requested to live longer than allowed
+	ErrInvalidTTL = errors.New("invalid ttl")
+	// ErrNameConflict is returned when a folder already contains an item with the same name
+	ErrNameConflict = errors.New("name already exists")
+	// ErrInvalidName is returned when a file or folder name can't be used
+	ErrInvalidName = errors.New("invalid name")
+	// ErrAmbiguousPath is returned when a path ignoring case matches several items, which differ only by case
+	ErrAmbiguousPath = errors.New("ambiguous path")
+	// ErrInvalidDestination is returned when a folder is copied into itself or its subfolder
+	ErrInvalidDestination = errors.New("invalid destination")
+	// ErrParentGone is returned when an item is restored from the trash, but the folder it was deleted from is gone
+	ErrParentGone = errors.New("parent folder is gone")
+	// ErrRootFolder is returned when the root folder is requested to be deleted or moved
+	ErrRootFolder = errors.New("root folder can't be deleted or moved")
+	// ErrQuotaExceeded is returned when the user has no free space left for the request
+	ErrQuotaExceeded = errors.New("storage quota exceeded")
+	// ErrFolderSizeExceeded is returned when a folder or its ancestor would grow over its max size
+	ErrFolderSizeExceeded = errors.New("folder size limit exceeded")
+)
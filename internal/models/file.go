@@ -1,17 +1,44 @@
 package models
 
 import (
+	"mime"
+	"path"
 	"time"
 )
 
 // File represents a file stored in the system.
 type File struct {
-	ID            int64     `db:"id"`
-	FolderID      int64     `db:"folder_id"`
-	UserID        int       `db:"user_id"`
-	Name          string    `db:"name"`
-	S3URL         string    `db:"s3_url"`
-	Size          int64     `db:"size"`
-	TransactionID *int64    `db:"transaction_id"`
-	CreatedAt     time.Time `db:"created_at"`
+	ID            int64  `db:"id"`
+	FolderID      int64  `db:"folder_id"`
+	UserID        int    `db:"user_id"`
+	Name          string `db:"name"`
+	S3URL         string `db:"s3_url"`
+	Size          int64  `db:"size"`
+	TransactionID *int64 `db:"transaction_id"`
+	// Checksum is hex encoded SHA-256 of the content, empty if unknown
+	Checksum string `db:"checksum"`
+	// MD5 is hex encoded MD5 of the content, empty if unknown
+	MD5 string `db:"md5"`
+	// BlobChecksum is the checksum of the shared blob stored at S3URL, empty if the file owns its object
+	BlobChecksum string `db:"blob_checksum"`
+	// Version is the number of the current version, it starts with 1 and grows with every new one
+	Version int `db:"version"`
+	// VersionsSize is the total size of the retained previous versions
+	VersionsSize int64     `db:"versions_size"`
+	CreatedAt    time.Time `db:"created_at"`
+	// UpdatedAt is the time the current version was uploaded
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// TotalSize returns the size of the current content and all retained versions, folder sizes count it
+func (f *File) TotalSize() int64 {
+	return f.Size + f.VersionsSize
+}
+
+// ContentType returns the content type of the file by the extension of its name
+func ContentType(name string) string {
+	if ct := mime.TypeByExtension(path.Ext(name)); ct != "" {
+		return ct
+	}
+	return "application/octet-stream"
 }
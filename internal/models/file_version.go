@@ -0,0 +1,38 @@
+package models
+
+import (
+	"time"
+)
+
+// FileVersion represents a previous content of the file, the current one is kept in the file itself
+type FileVersion struct {
+	ID      int64  `db:"id"`
+	FileID  int64  `db:"file_id"`
+	UserID  int    `db:"user_id"`
+	Version int    `db:"version"`
+	S3URL   string `db:"s3_url"`
+	Size    int64  `db:"size"`
+	// Checksum is hex encoded SHA-256 of the content, empty if unknown
+	Checksum string `db:"checksum"`
+	// MD5 is hex encoded MD5 of the content, empty if unknown
+	MD5 string `db:"md5"`
+	// BlobChecksum is the checksum of the shared blob stored at S3URL, empty if the version owns its object
+	BlobChecksum string `db:"blob_checksum"`
+	// CreatedAt is the time the version was uploaded
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// CurrentVersion returns the current content of the file as a version
+func (f *File) CurrentVersion() FileVersion {
+	return FileVersion{
+		FileID:       f.ID,
+		UserID:       f.UserID,
+		Version:      f.Version,
+		S3URL:        f.S3URL,
+		Size:         f.Size,
+		Checksum:     f.Checksum,
+		MD5:          f.MD5,
+		BlobChecksum: f.BlobChecksum,
+		CreatedAt:    f.UpdatedAt,
+	}
+}
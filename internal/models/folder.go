@@ -11,6 +11,7 @@ type Folder struct {
 	Name           string    `db:"name"`
 	ParentFolderID *int64    `db:"parent_folder_id"`
 	Size           int64     `db:"size"`
+	MaxSize        *int64    `db:"max_size"` // nil if the folder size is not limited
 	CreatedAt      time.Time `db:"created_at"`
 	UpdatedAt      time.Time `db:"updated_at"`
 }
@@ -27,3 +28,9 @@ type FolderSizeSimplified struct {
 	ID   int64 `db:"id"`
 	Size int64 `db:"size"`
 }
+
+// FolderCopy links a copied folder to its source folder
+type FolderCopy struct {
+	SourceID int64 `db:"source_id"`
+	ID       int64 `db:"id"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// types of the folder listing items
+const (
+	ItemTypeFolder = "folder"
+	ItemTypeFile   = "file"
+)
+
+// sort keys of the folder listing
+const (
+	SortByName      = "name"
+	SortBySize      = "size"
+	SortByCreatedAt = "created_at"
+)
+
+// ListOptions describes which page of the folder listing is requested
+type ListOptions struct {
+	// Type limits the listing to folders or files, empty for both
+	Type  string
+	Sort  string
+	Desc  bool
+	Limit int
+	// After is the position of the last item of the previous page, nil for the first page
+	After *ListCursor
+}
+
+// ListCursor is the position of an item in the folder listing, folders are always listed before files.
+// Only the field of the sort key is set, a cursor without ID points to the beginning of its type
+type ListCursor struct {
+	Type      string
+	ID        int64
+	Name      string
+	Size      int64
+	CreatedAt time.Time
+}
+
+// FolderEntry is a folder with the number of its direct children
+type FolderEntry struct {
+	Folder
+	FileCount   int64 `db:"file_count"`
+	FolderCount int64 `db:"folder_count"`
+}
+
+// FolderListing is a page of the folder content
+type FolderListing struct {
+	Folder  FolderEntry
+	Folders []FolderEntry
+	Files   []File
+	// Next is the position to continue the listing from, nil if it is the last page
+	Next *ListCursor
+}
@@ -0,0 +1,16 @@
+package models
+
+// policies of handling an item put into a folder which already contains an item with the same name
+const (
+	// ConflictFail rejects the operation
+	ConflictFail = "fail"
+	// ConflictRename adds a suffix to the name, like "report (1).pdf"
+	ConflictRename = "rename"
+	// ConflictReplace removes the existing item
+	ConflictReplace = "replace"
+)
+
+// IsConflictPolicy checks that the policy is supported
+func IsConflictPolicy(policy string) bool {
+	return policy == ConflictFail || policy == ConflictRename || policy == ConflictReplace
+}
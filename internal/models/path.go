@@ -0,0 +1,20 @@
+package models
+
+// PathItem is the folder or the file found by its path, only one of them is set
+type PathItem struct {
+	Folder *Folder
+	File   *File
+}
+
+// Breadcrumb is a folder on the way from the root folder of the user
+type Breadcrumb struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+// PathMatch is the deepest folder found for the names of a path
+type PathMatch struct {
+	FolderID int64
+	// Depth is the number of the path names resolved, 0 for the root folder
+	Depth int
+}
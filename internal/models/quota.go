@@ -0,0 +1,57 @@
+package models
+
+import (
+	"fmt"
+)
+
+// UserQuota describes how much the user can store and how much is stored already
+type UserQuota struct {
+	UserID int `db:"id"`
+	// Quota is the max number of bytes the user can store, nil if the storage is not limited
+	Quota *int64 `db:"quota"`
+	// Used is the size of the root folders of the user
+	Used int64 `db:"-"`
+	// Pending is the size of files uploaded in unfinished transactions, folder sizes include them
+	// only when the transaction completes
+	Pending int64 `db:"-"`
+}
+
+// Check returns *QuotaError if size more bytes do not fit into the quota
+func (q *UserQuota) Check(size int64) error {
+	if q.Quota == nil || size <= 0 {
+		return nil
+	}
+	if q.Used+q.Pending+size > *q.Quota {
+		return &QuotaError{Quota: *q.Quota, Used: q.Used + q.Pending, Requested: size}
+	}
+	return nil
+}
+
+// QuotaError is returned when the stored data would exceed the quota of the user or the max size of a folder
+type QuotaError struct {
+	// FolderID is the folder which would grow over its max size, 0 if the quota of the user is exceeded
+	FolderID  int64
+	Quota     int64
+	Used      int64
+	Requested int64
+}
+
+func (e *QuotaError) Error() string {
+	message := fmt.Sprintf("%d bytes requested, %d of %d bytes used: %s", e.Requested, e.Used, e.Quota, e.Unwrap())
+	if e.FolderID != 0 {
+		return fmt.Sprintf("folder(%d): %s", e.FolderID, message)
+	}
+	return message
+}
+
+func (e *QuotaError) Unwrap() error {
+	if e.FolderID != 0 {
+		return ErrFolderSizeExceeded
+	}
+	return ErrQuotaExceeded
+}
+
+// TooLarge is true if the request does not fit into the quota even when nothing else is stored
+func (e *QuotaError) TooLarge() bool {
+	return e.Requested > e.Quota
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"fmt"
+)
+
+const (
+	// places where a folder size is recorded
+	SizeSourceDatabase = "database"
+	SizeSourceCache    = "cache"
+)
+
+// FolderSizeCheck is the recorded size of a folder compared with the size of its content
+type FolderSizeCheck struct {
+	ID       int64
+	Recorded int64
+	Actual   int64
+	// InTransaction is set when the folder contains the folder of an unfinished upload transaction,
+	// the cached size of such folder includes files which are not counted yet
+	InTransaction bool
+}
+
+// SizeDiscrepancy is a recorded folder size which differed from the size of the folder content
+type SizeDiscrepancy struct {
+	FolderID int64
+	UserID   int
+	// Source is where the wrong size was recorded, one of SizeSourceDatabase, SizeSourceCache
+	Source   string
+	Recorded int64
+	Actual   int64
+}
+
+func (d SizeDiscrepancy) String() string {
+	return fmt.Sprintf("folder(%d) of user(%d): %s size %d, actual %d",
+		d.FolderID, d.UserID, d.Source, d.Recorded, d.Actual)
+}
+
+// ReconcileReport describes a run of the folder size reconciliation
+type ReconcileReport struct {
+	Users   int
+	Folders int
+	// Discrepancies are the sizes which were fixed
+	Discrepancies []SizeDiscrepancy
+}
@@ -1,16 +1,120 @@
 package models
 
 import (
+	"fmt"
 	"time"
 )
 
+// statuses of the upload transaction
+const (
+	TransactionPending   = "pending"
+	TransactionPaused    = "paused"
+	TransactionResumed   = "resumed"
+	TransactionCompleted = "completed"
+	TransactionFailed    = "failed"
+)
+
+// statuses a transaction may move to from its status, completed and failed transactions are final
+var transactionTransitions = map[string][]string{
+	TransactionPending: {TransactionCompleted, TransactionFailed, TransactionPaused},
+	TransactionPaused:  {TransactionResumed, TransactionFailed},
+	TransactionResumed: {TransactionCompleted, TransactionFailed, TransactionPaused},
+}
+
+// OpenTransactionStatuses are the statuses of transactions which accept uploads and whose ttl runs
+var OpenTransactionStatuses = []string{TransactionPending, TransactionResumed}
+
+// IsTransactionStatus reports whether the status is one of the statuses of the upload transaction
+func IsTransactionStatus(status string) bool {
+	switch status {
+	case TransactionPending, TransactionPaused, TransactionResumed, TransactionCompleted, TransactionFailed:
+		return true
+	}
+	return false
+}
+
+// TransactionSourceStatuses returns the statuses a transaction may move to the status from
+func TransactionSourceStatuses(status string) []string {
+	var sources []string
+	for from, targets := range transactionTransitions {
+		for _, to := range targets {
+			if to == status {
+				sources = append(sources, from)
+			}
+		}
+	}
+	return sources
+}
+
+// actions recorded in the transaction audit
+const (
+	// TransactionAuditExpired is recorded when the sweeper rolls back a transaction which outlived its ttl
+	TransactionAuditExpired = "expired"
+	// TransactionAuditStopped is recorded when the client stops a transaction
+	TransactionAuditStopped = "stopped"
+)
+
 // UploadTransaction represents a file upload transaction.
 type UploadTransaction struct {
 	ID        int64     `db:"id"`
 	UserID    int       `db:"user_id"`
 	FolderID  int64     `db:"folder_id"`
-	TotalSize int64     `db:"total_size"`
 	Status    string    `db:"status"`
 	CreatedAt time.Time `db:"created_at"`
 	UpdatedAt time.Time `db:"updated_at"`
+	// ExpiresAt is the time the open transaction is rolled back at
+	ExpiresAt time.Time `db:"expires_at"`
+	// PausedAt is the time the transaction was paused at, nil unless it is paused
+	PausedAt *time.Time `db:"paused_at"`
+	// FileCount and TotalSize are the number and the size with versions of the files uploaded in the transaction,
+	// files in the trash are not counted
+	FileCount int   `db:"file_count"`
+	TotalSize int64 `db:"total_size"`
+}
+
+// Open reports whether the transaction accepts uploads, it is pending or resumed
+func (t *UploadTransaction) Open() bool {
+	return t.Status == TransactionPending || t.Status == TransactionResumed
+}
+
+// Unfinished reports whether files uploaded in the transaction are not counted by the folder sizes yet
+func (t *UploadTransaction) Unfinished() bool {
+	return t.Open() || t.Status == TransactionPaused
+}
+
+// TransactionFilter selects transactions of a user, empty fields match any transaction
+type TransactionFilter struct {
+	Status   string
+	FolderID int64
+}
+
+// TransactionAudit records a rolled back transaction with the files removed together with it
+type TransactionAudit struct {
+	ID            int64     `db:"id"`
+	TransactionID int64     `db:"transaction_id"`
+	UserID        int       `db:"user_id"`
+	FolderID      int64     `db:"folder_id"`
+	Action        string    `db:"action"`
+	FileCount     int       `db:"file_count"`
+	Size          int64     `db:"size"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// TransactionStatusError is returned when the status of the transaction does not allow the request
+type TransactionStatusError struct {
+	ID     int64
+	Status string
+	// Requested is the status the transaction was requested to move to, empty if files were uploaded in it
+	Requested string
+}
+
+func (e *TransactionStatusError) Error() string {
+	if e.Requested == "" {
+		return fmt.Sprintf("transaction(%d) is %s, files can't be uploaded in it: %s", e.ID, e.Status, e.Unwrap())
+	}
+	return fmt.Sprintf("transaction(%d) is %s, can't become %s: %s", e.ID, e.Status, e.Requested, e.Unwrap())
+}
+
+func (e *TransactionStatusError) Unwrap() error {
+	return ErrTransactionStatus
 }
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// TrashItem represents a deleted folder or file, the content of a folder is deleted together with it
+type TrashItem struct {
+	ID     int64 `db:"id"`
+	UserID int   `db:"user_id"`
+	// ItemType is ItemTypeFolder or ItemTypeFile
+	ItemType string `db:"item_type"`
+	ItemID   int64  `db:"item_id"`
+	Name     string `db:"name"`
+	// ParentFolderID is the folder the item was deleted from
+	ParentFolderID int64     `db:"parent_folder_id"`
+	Size           int64     `db:"size"`
+	DeletedAt      time.Time `db:"deleted_at"`
+	// ExpiresAt is the time after which the item is deleted permanently
+	ExpiresAt time.Time `db:"-"`
+}
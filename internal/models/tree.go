@@ -0,0 +1,23 @@
+package models
+
+// TreeOptions describes how much of the folder tree is walked
+type TreeOptions struct {
+	// Depth is the number of levels walked below the folder, 0 for the folder itself
+	Depth int
+	// Files adds files of the folders above the depth limit
+	Files bool
+}
+
+// TreeNode is a folder or a file of the folder tree. Nodes are walked depth-first, every folder
+// is followed by its files and then by its subfolders, both sorted by name
+type TreeNode struct {
+	// Type is ItemTypeFolder or ItemTypeFile
+	Type string
+	ID   int64
+	Name string
+	Size int64
+	// Depth is the level below the walked folder, 0 for the folder itself
+	Depth int
+	// FileCount is the number of files in the folder with all its subfolders, 0 for files
+	FileCount int64
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Upload represents a resumable upload which is not finished yet.
+type Upload struct {
+	ID       string `db:"id"`
+	UserID   int    `db:"user_id"`
+	FolderID int64  `db:"folder_id"`
+	Name     string `db:"name"`
+	// Length is the size of the whole file in bytes
+	Length int64 `db:"length"`
+	// Offset is the number of already received bytes
+	Offset int64 `db:"upload_offset"`
+	Parts  int   `db:"parts"`
+	// Metadata is the Upload-Metadata header as it was sent by the client
+	Metadata string `db:"metadata"`
+	// OnConflict is the name conflict policy applied when the upload is saved as a file
+	OnConflict string    `db:"on_conflict"`
+	ExpiresAt  time.Time `db:"expires_at"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+// UploadPart represents a received chunk of the upload
+type UploadPart struct {
+	UploadID   string `db:"upload_id"`
+	PartNumber int    `db:"part_number"`
+	S3URL      string `db:"s3_url"`
+	Size       int64  `db:"size"`
+}
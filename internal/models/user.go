@@ -0,0 +1,8 @@
+package models
+
+// UserSettings are the preferences of the user
+type UserSettings struct {
+	UserID int `db:"id"`
+	// CaseInsensitivePaths makes paths match folder and file names ignoring case
+	CaseInsensitivePaths bool `db:"case_insensitive_paths"`
+}
@@ -0,0 +1,61 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// BreadcrumbResponse represents a folder on the way from the root folder to an item
+type BreadcrumbResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+// Location represents where the item is, it is set only in metadata responses
+type Location struct {
+	// Breadcrumbs are the parent folders of the item, the root folder first
+	Breadcrumbs []BreadcrumbResponse `json:"breadcrumbs,omitempty"`
+	// Path is the slash separated path of the item under the root folder, "/" for the root folder itself
+	Path string `json:"path,omitempty"`
+}
+
+// returns the location of the folder, the root folder has no breadcrumbs
+func (h *Handler) folderLocation(folderID int64) (Location, error) {
+	breadcrumbs, err := h.folderService.GetBreadcrumbs(folderID)
+	if err != nil {
+		return Location{}, err
+	}
+	last := len(breadcrumbs) - 1
+	if last == 0 {
+		return newLocation(nil, ""), nil
+	}
+	return newLocation(breadcrumbs[:last], breadcrumbs[last].Name), nil
+}
+
+// returns the location of the file, its folder is the last breadcrumb
+func (h *Handler) fileLocation(file *models.File) (Location, error) {
+	breadcrumbs, err := h.folderService.GetBreadcrumbs(file.FolderID)
+	if err != nil {
+		return Location{}, err
+	}
+	return newLocation(breadcrumbs, file.Name), nil
+}
+
+// builds the location of the item with the name from its parent folders, the name of the root folder
+// is not a part of the path
+func newLocation(parents []models.Breadcrumb, name string) Location {
+	location := Location{Breadcrumbs: make([]BreadcrumbResponse, 0, len(parents))}
+	names := make([]string, 0, len(parents)+1)
+	for i, parent := range parents {
+		location.Breadcrumbs = append(location.Breadcrumbs, BreadcrumbResponse{ID: parent.ID, Name: parent.Name})
+		if i > 0 {
+			names = append(names, parent.Name)
+		}
+	}
+	if name != "" {
+		names = append(names, name)
+	}
+	location.Path = "/" + strings.Join(names, "/")
+	return location
+}
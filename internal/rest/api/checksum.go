@@ -0,0 +1,86 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// checksums of the content supplied by the client, hex encoded, empty if not provided
+type checksums struct {
+	sha256 string
+	md5    string
+}
+
+// reads the expected content checksums from the Digest (RFC 3230) and Content-MD5 headers,
+// algorithms other than SHA-256 and MD5 are ignored
+func expectedChecksums(r *http.Request) (checksums, error) {
+	var expected checksums
+
+	for _, digest := range r.Header.Values("Digest") {
+		for _, value := range strings.Split(digest, ",") {
+			algorithm, encoded, ok := strings.Cut(strings.TrimSpace(value), "=")
+			if !ok {
+				return checksums{}, fmt.Errorf("invalid digest: %q", value)
+			}
+
+			var size int
+			var target *string
+			switch strings.ToLower(algorithm) {
+			case "sha-256":
+				size, target = 32, &expected.sha256
+			case "md5":
+				size, target = 16, &expected.md5
+			default:
+				continue
+			}
+
+			sum, err := decodeChecksum(encoded, size)
+			if err != nil {
+				return checksums{}, fmt.Errorf("invalid %s digest: %w", algorithm, err)
+			}
+			*target = sum
+		}
+	}
+
+	if contentMD5 := r.Header.Get("Content-MD5"); contentMD5 != "" {
+		sum, err := decodeChecksum(contentMD5, 16)
+		if err != nil {
+			return checksums{}, fmt.Errorf("invalid Content-MD5: %w", err)
+		}
+		if expected.md5 != "" && expected.md5 != sum {
+			return checksums{}, errors.New("Digest and Content-MD5 headers do not match")
+		}
+		expected.md5 = sum
+	}
+
+	return expected, nil
+}
+
+// checks that the stored file has the expected checksums
+func (c checksums) verify(file *models.File) error {
+	if c.sha256 != "" && c.sha256 != file.Checksum {
+		return fmt.Errorf("sha-256 mismatch: expected %s, got %s", c.sha256, file.Checksum)
+	}
+	if c.md5 != "" && c.md5 != file.MD5 {
+		return fmt.Errorf("md5 mismatch: expected %s, got %s", c.md5, file.MD5)
+	}
+	return nil
+}
+
+// decodes a base64 checksum of the given size in bytes to hex
+func decodeChecksum(encoded string, size int) (string, error) {
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return "", err
+	}
+	if len(sum) != size {
+		return "", fmt.Errorf("expected %d bytes, got %d", size, len(sum))
+	}
+	return hex.EncodeToString(sum), nil
+}
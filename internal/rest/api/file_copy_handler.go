@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// CopyFile copies a file into another folder
+// @Summary      Copy a file
+// @Description  Copies a specified file into the folder based on provided folder ID, the content is shared with the source file
+// @Tags         file
+// @Param        folder_id   path      int64            true  "Folder ID"
+// @Param        file_id     path      int64            true  "File ID"
+// @Param        user_id     header    int              true  "User ID"
+// @Param        copyFile    body      CopyFileRequest  true  "Request payload containing the destination folder ID"
+// @Param        on_conflict query     string           false "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
+// @Produce      json
+// @Success      201  {object}  FileResponse   "File successfully copied"
+// @Failure      400  {object}  ErrorResponse  "Invalid input parameters"
+// @Failure      404  {object}  ErrorResponse  "File or folder not found"
+// @Failure      409  {object}  ErrorResponse  "File with the same name already exists in the folder"
+// @Failure      413  {object}  QuotaErrorResponse  "Copy is larger than the storage quota"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
+// @Router       /v1/folders/{folder_id}/files/{file_id}/copy [post]
+func (h *Handler) CopyFile() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.copyFile(w, r)
+	})
+}
+
+// CopyFileRequest represents the request payload to copy a file
+type CopyFileRequest struct {
+	NewFolderID int64 `json:"new_folder_id"`
+}
+
+func (h *Handler) copyFile(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
+	file := h.loadFolderFile(w, r)
+	if file == nil {
+		return
+	}
+
+	// read request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		log.Info().Msgf("Failed to read body: %s", err.Error())
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	// decode the JSON data into struct
+	var data CopyFileRequest
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+		return
+	}
+
+	// copy file and re-calculate sizes
+	copied, err := h.fileService.CopyFile(file.ID, data.NewFolderID, policy)
+	if err != nil {
+		log.Warn().Msgf("Failed to copy file(%d) to %d: %s", file.ID, data.NewFolderID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "File or folder not found")
+			return
+		}
+		if nameErrorResponse(w, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
+		FailedResponse(w, http.StatusInternalServerError, "Failed to copy file")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusCreated, newFileResponse(copied))
+}
@@ -1,15 +1,17 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
 )
 
-// DeleteFile removes user file by id
+// DeleteFile moves user file to the trash by id
 // @Summary      Remove a file
-// @Description  Deletes a file from the specified folder by file ID.
+// @Description  Moves a file from the specified folder to the trash by file ID.
 // @Tags         file
 // @Param        folder_id   path      int64  true  "Folder ID"
 // @Param        file_id      path      int64  true  "File ID"
@@ -17,6 +19,7 @@ import (
 // @Produce      json
 // @Success      204  {object}  nil   "No Content"
 // @Failure      400  {object}  ErrorResponse "Invalid folder_id or file_id"
+// @Failure      404  {object}  ErrorResponse "File not found"
 // @Failure      500  {object}  ErrorResponse "Internal Server Error"
 // @Router       /v1/folders/{folder_id}/files/{file_id} [delete]
 func (h *Handler) DeleteFile() http.Handler {
@@ -34,6 +37,10 @@ func (h *Handler) deleteFile(w http.ResponseWriter, r *http.Request) {
 
 	err = h.fileService.DeleteFile(fileID)
 	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "File not found")
+			return
+		}
 		log.Warn().Msgf("failed to remove file(%d): %s", fileID, err.Error())
 		FailedResponse(w, http.StatusInternalServerError, "Failed to delete file")
 		return
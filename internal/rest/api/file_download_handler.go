@@ -0,0 +1,76 @@
+package api
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"mime"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// DownloadFile streams the file content from the storage
+// @Summary      Download a file
+// @Description  Streams the file content. Supports partial downloads with the Range header
+// @Description  and conditional requests with If-None-Match, If-Modified-Since and If-Range headers.
+// @Tags         file
+// @Param        folder_id          path      int64   true   "Folder ID"
+// @Param        file_id            path      int64   true   "File ID"
+// @Param        user_id            header    int     true   "User ID"
+// @Param        Range              header    string  false  "Requested byte ranges, e.g. bytes=0-1023"
+// @Param        If-None-Match      header    string  false  "ETag of the cached file"
+// @Param        If-Modified-Since  header    string  false  "Date of the cached file"
+// @Produce      octet-stream
+// @Success      200  {file}    file           "File content"
+// @Success      206  {file}    file           "Requested part of the file content"
+// @Success      304  {object}  nil            "File is not modified"
+// @Failure      400  {object}  ErrorResponse  "Invalid folder_id or file_id"
+// @Failure      404  {object}  ErrorResponse  "File not found"
+// @Failure      416  {string}  string         "Requested range not satisfiable"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/files/{file_id}/content [get]
+func (h *Handler) DownloadFile() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.downloadFile(w, r)
+	})
+}
+
+func (h *Handler) downloadFile(w http.ResponseWriter, r *http.Request) {
+	file := h.loadFolderFile(w, r)
+	if file == nil {
+		return
+	}
+
+	h.serveContent(w, r, file.Name, file.CurrentVersion())
+}
+
+// streams the content of the file version, name defines the content type and the name of the downloaded file
+func (h *Handler) serveContent(w http.ResponseWriter, r *http.Request, name string, version models.FileVersion) {
+	content, err := h.storage.OpenFile(version.S3URL)
+	if err != nil {
+		log.Warn().Msgf("failed to open version %d of file(%d): %s", version.Version, version.FileID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to download file")
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", models.ContentType(name))
+	if disposition := mime.FormatMediaType("attachment", map[string]string{"filename": name}); disposition != "" {
+		w.Header().Set("Content-Disposition", disposition)
+	}
+	w.Header().Set("ETag", contentETag(version))
+
+	// ServeContent handles Range, If-Range, If-None-Match and If-Modified-Since headers
+	// and sets Content-Length, Accept-Ranges and Last-Modified for us
+	http.ServeContent(w, r, name, version.CreatedAt, content)
+}
+
+// returns a strong ETag of the content, the checksum is used when it is known,
+// otherwise the object key, stored objects are never modified, so it identifies the content too
+func contentETag(version models.FileVersion) string {
+	if version.Checksum != "" {
+		return `"` + version.Checksum + `"`
+	}
+	return fmt.Sprintf(`"%x"`, sha256.Sum256([]byte(version.S3URL)))
+}
@@ -1,21 +1,26 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
 )
 
 // GetFile returns file data by it`s id
 // @Summary      Get a file
+// @Description  Returns metadata of the file with its breadcrumbs and path, use the content endpoint to download the file itself.
 // @Tags         file
 // @Param        folder_id   path      int64  true  "Folder ID"
 // @Param        file_id      path      int64  true  "File ID"
 // @Param        user_id     header    int    true "User ID"
 // @Produce      json
-// @Success      204  {object}  nil   "No Content"
+// @Success      200  {object}  FileResponse  "File metadata"
 // @Failure      400  {object}  ErrorResponse "Invalid file_id"
+// @Failure      404  {object}  ErrorResponse "File not found"
 // @Failure      500  {object}  ErrorResponse "Internal Server Error"
 // @Router       /v1/folders/{folder_id}/files/{file_id} [get]
 func (h *Handler) GetFile() http.Handler {
@@ -24,19 +29,87 @@ func (h *Handler) GetFile() http.Handler {
 	})
 }
 
+// FileResponse represents the file metadata
+type FileResponse struct {
+	ID       int64  `json:"id"`
+	FolderID int64  `json:"folder_id"`
+	Name     string `json:"name"`
+	Size     int64  `json:"size"`
+	// Version is the number of the current version, it grows with every replacing upload
+	Version int `json:"version"`
+	// VersionsSize is the total size of the previous versions kept for the file
+	VersionsSize int64 `json:"versions_size"`
+	// Checksum is hex encoded SHA-256 of the content, empty for files uploaded before checksums were calculated
+	Checksum  string    `json:"checksum,omitempty"`
+	MD5       string    `json:"md5,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Location
+}
+
 func (h *Handler) getFile(w http.ResponseWriter, r *http.Request) {
+	file := h.loadFolderFile(w, r)
+	if file == nil {
+		return
+	}
+
+	response := newFileResponse(file)
+	location, err := h.fileLocation(file)
+	if err != nil {
+		log.Warn().Msgf("failed to get location of file(%d): %s", file.ID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get file")
+		return
+	}
+	response.Location = location
+
+	SuccessfulResponse(w, http.StatusOK, response)
+}
+
+func newFileResponse(file *models.File) FileResponse {
+	return FileResponse{
+		ID:           file.ID,
+		FolderID:     file.FolderID,
+		Name:         file.Name,
+		Size:         file.Size,
+		Version:      file.Version,
+		VersionsSize: file.VersionsSize,
+		Checksum:     file.Checksum,
+		MD5:          file.MD5,
+		CreatedAt:    file.CreatedAt,
+		UpdatedAt:    file.UpdatedAt,
+	}
+}
+
+// loads the file by the path values and checks that it is located in the requested folder,
+// if something is wrong - writes the failed response and returns nil
+func (h *Handler) loadFolderFile(w http.ResponseWriter, r *http.Request) *models.File {
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return nil
+	}
+
 	fileID, err := strconv.ParseInt(r.PathValue("file_id"), 10, 64)
 	if err != nil {
 		FailedResponse(w, http.StatusBadRequest, "Invalid file_id")
-		return
+		return nil
 	}
 
 	file, err := h.fileService.GetFile(fileID)
 	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "File not found")
+			return nil
+		}
 		log.Warn().Msgf("failed to get file(%d): %s", fileID, err.Error())
 		FailedResponse(w, http.StatusInternalServerError, "Failed to get file")
-		return
+		return nil
+	}
+
+	if file.FolderID != folderID {
+		FailedResponse(w, http.StatusNotFound, "File not found")
+		return nil
 	}
 
-	SuccessfulResponse(w, http.StatusNoContent, file)
+	return file
 }
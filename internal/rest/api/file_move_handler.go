@@ -2,11 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/saur4ig/file-storage/internal/models"
 )
 
 // MoveFile moves a file to a new folder
@@ -17,10 +20,15 @@ import (
 // @Param        file_id      path      int64  true  "File ID"
 // @Param        user_id     header    int    true  "User ID"
 // @Param        moveFile    body      MoveFileRequest true  "Request payload containing the new folder ID"
+// @Param        on_conflict query     string  false  "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
 // @Produce      json
 // @Success      200  {object}  nil   "File successfully moved"
 // @Failure      400  {object}  ErrorResponse "Invalid input parameters"
+// @Failure      404  {object}  ErrorResponse "File or folder not found"
+// @Failure      409  {object}  ErrorResponse "File with the same name already exists in the new folder"
+// @Failure      413  {object}  QuotaErrorResponse  "Moved item is larger than the folder size limit"
 // @Failure      500  {object}  ErrorResponse "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Folder size limit exceeded"
 // @Router       /v1/folders/{folder_id}/files/{file_id}/move [put]
 func (h *Handler) MoveFile() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -46,6 +54,12 @@ func (h *Handler) moveFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
 	// read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -64,9 +78,19 @@ func (h *Handler) moveFile(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// move file and re-calculate sizes
-	err = h.fileService.MoveFile(fileID, folderID, data.NewFolderID)
+	err = h.fileService.MoveFile(fileID, folderID, data.NewFolderID, policy)
 	if err != nil {
 		log.Warn().Msgf("Failed to move file(%d) to %d: %s", fileID, data.NewFolderID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "File or folder not found")
+			return
+		}
+		if nameErrorResponse(w, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
 		FailedResponse(w, http.StatusInternalServerError, "Failed to move file")
 		return
 	}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// RenameFile changes the name of the requested file
+// @Summary      Rename a file
+// @Description  Changes the name of a specified file, the name must be unique in the folder
+// @Tags         file
+// @Param        folder_id    path      int64          true  "Folder ID"
+// @Param        file_id      path      int64          true  "File ID"
+// @Param        user_id      header    int            true  "User ID"
+// @Param        renameFile   body      RenameRequest  true  "New file name"
+// @Param        on_conflict  query     string         false "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
+// @Produce      json
+// @Success      200  {object}  FileResponse   "File successfully renamed"
+// @Failure      400  {object}  ErrorResponse  "Invalid input parameters or name"
+// @Failure      404  {object}  ErrorResponse  "File not found"
+// @Failure      409  {object}  ErrorResponse  "File with the same name already exists"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/files/{file_id} [patch]
+func (h *Handler) RenameFile() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.renameFile(w, r)
+	})
+}
+
+func (h *Handler) renameFile(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
+	file := h.loadFolderFile(w, r)
+	if file == nil {
+		return
+	}
+
+	data, ok := readRenameRequest(w, r)
+	if !ok {
+		return
+	}
+
+	renamed, err := h.fileService.RenameFile(file.ID, data.Name, policy)
+	if err != nil {
+		log.Info().Msgf("Failed to rename file(%d): %s", file.ID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "File not found")
+			return
+		}
+		if nameErrorResponse(w, err) {
+			return
+		}
+		FailedResponse(w, http.StatusInternalServerError, "Failed to rename file")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newFileResponse(renamed))
+}
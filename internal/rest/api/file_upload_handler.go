@@ -2,25 +2,39 @@ package api
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/checksum"
+	"github.com/saur4ig/file-storage/internal/models"
 	"github.com/saur4ig/file-storage/internal/rest/middleware"
 )
 
-// UploadFile uploads a file to S3 and saves the metadata in the database
+// UploadFile uploads a file to the file storage and saves the metadata in the database
 // @Summary      Upload a file
-// @Description  Uploads a file to S3 storage and saves the file details in the database. It also updates the folder size cache in there is no transaction
+// @Description  Uploads a file to the file storage and saves the file details in the database. It also updates the folder size cache. A file uploaded in a transaction is added to the folder sizes when the transaction completes, the transaction has to be pending or resumed and started for the folder
 // @Tags         file
 // @Param        user_id         header    int     true  "User ID"
 // @Param        folder_id       path      int64   true  "Folder ID"
 // @Param        file             formData  file     true  "File to upload"
+// @Param        transaction_id  header    int64   false "ID of the pending or resumed transaction of the folder to upload the file in"
+// @Param        Digest          header    string  false "Expected checksum of the file content, e.g. sha-256=<base64>"
+// @Param        Content-MD5     header    string  false "Expected base64 encoded MD5 of the file content"
+// @Param        on_conflict     query     string  false "Name conflict policy, a new version of the existing file by default"  Enums(fail, rename, replace)
 // @Accept       multipart/form-data
 // @Produce      json
-// @Success      201  {object}  nil                 "File successfully uploaded"
-// @Failure      400  {object}  ErrorResponse       "Invalid input parameters or file upload failed"
+// @Success      201  {object}  FileResponse        "File successfully uploaded"
+// @Failure      400  {object}  ErrorResponse       "Invalid input parameters, file upload failed or checksum mismatch"
+// @Failure      404  {object}  ErrorResponse       "Transaction not found"
+// @Failure      409  {object}  ErrorResponse       "File with the same name already exists or transaction is neither pending nor resumed"
+// @Failure      413  {object}  QuotaErrorResponse  "File is larger than the storage quota"
 // @Failure      500  {object}  ErrorResponse       "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
 // @Router       /v1/folders/{folder_id}/files [post]
 func (h *Handler) UploadFile() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -37,51 +51,138 @@ func (h *Handler) uploadFile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	policy, err := h.uploadConflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
 	// Get transaction from headers
 	var transactionID *int64
 	transactionIDStr := r.Header.Get("transaction_id")
 	if transactionIDStr != "" {
 		id, err := strconv.ParseInt(transactionIDStr, 10, 64)
-		if err == nil {
-			transactionID = &id
+		if err != nil {
+			FailedResponse(w, http.StatusBadRequest, "Invalid transaction_id")
+			return
 		}
+		transactionID = &id
 	}
 
-	// Get file
-	file, header, err := r.FormFile("file")
+	h.saveUploadedFile(w, r, &models.File{
+		FolderID:      folderID,
+		UserID:        userID,
+		TransactionID: transactionID,
+	}, func(file *models.File) (int64, error) {
+		return h.fileService.UploadFile(file, policy)
+	})
+}
+
+// stores the file part of the multipart request and saves the file into its folder, the name of the uploaded file
+// is used unless the file has one, writes the response
+func (h *Handler) saveUploadedFile(w http.ResponseWriter, r *http.Request, file *models.File, save func(*models.File) (int64, error)) {
+	// Get file part, it is streamed to the storage without buffering the whole request
+	part, err := fileFormPart(r, "file")
 	if err != nil {
 		log.Info().Msgf("Failed to get file from request: %s", err.Error())
 		FailedResponse(w, http.StatusBadRequest, "Error occurred on file processing")
 		return
 	}
-	defer file.Close()
+	defer part.Close()
 
-	name := header.Filename
-	size := header.Size // in bytes
+	if file.Name == "" {
+		file.Name = part.FileName()
+	}
 
-	// Always nil - because s3 real logic not implemented
-	fileURL, err := h.s3.UploadFile(nil, name)
+	// Checksums expected by the client, they are verified after the content is stored
+	expected, err := expectedChecksums(r)
 	if err != nil {
-		FailedResponse(w, http.StatusBadRequest, "Error occurred on file saving")
+		log.Info().Msgf("Invalid checksum header: %s", err.Error())
+		FailedResponse(w, http.StatusBadRequest, "Invalid checksum header")
+		return
+	}
+
+	// Store file content, the size of multipart part is unknown until it is read
+	sums := checksum.NewReader(part)
+	fileKey, size, err := h.storage.UploadFile(sums, -1, file.Name)
+	if err != nil {
+		log.Warn().Msgf("Failed to store file: %s", err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Error occurred on file saving")
+		return
+	}
+
+	file.S3URL = fileKey
+	file.Size = size
+	file.Checksum = sums.SHA256()
+	file.MD5 = sums.MD5()
+	if err = expected.verify(file); err != nil {
+		log.Info().Msgf("Uploaded file is corrupted: %s", err.Error())
+		h.removeStoredFile(fileKey)
+		FailedResponse(w, http.StatusBadRequest, "Checksum mismatch")
 		return
 	}
 
 	// Save file in db and update
-	err = h.fileService.UploadFile(folderID, userID, name, fileURL, size, transactionID)
+	added, err := save(file)
 	if err != nil {
 		log.Info().Msgf("Failed to save file to db: %s", err.Error())
+		h.removeStoredFile(fileKey)
+		if nameErrorResponse(w, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
+		if file.TransactionID != nil && transactionErrorResponse(w, *file.TransactionID, err) {
+			return
+		}
+		if errors.Is(err, models.ErrAmbiguousPath) {
+			FailedResponse(w, http.StatusConflict, "Path is ambiguous")
+			return
+		}
 		FailedResponse(w, http.StatusInternalServerError, "Error occurred on file saving")
 		return
 	}
 
-	// Update folder cache
+	// Update folder cache, a new version may replace the oldest ones of the file
 	ctx := context.Background()
-	err = h.rc.SetOrUpdateFolderSize(ctx, folderID, size)
+	err = h.rc.SetOrUpdateFolderSize(ctx, file.FolderID, added)
 	if err != nil {
 		log.Info().Msgf("Failed to save file to cache: %s", err.Error())
 		FailedResponse(w, http.StatusInternalServerError, "Error occurred on file caching")
 		return
 	}
 
-	SuccessfulResponse(w, http.StatusCreated, nil)
+	SuccessfulResponse(w, http.StatusCreated, newFileResponse(file))
+}
+
+// removes an object which is not referenced by any file record, failures are only logged
+func (h *Handler) removeStoredFile(fileKey string) {
+	if err := h.storage.DeleteFile(fileKey); err != nil {
+		log.Warn().Msgf("Failed to remove stored file(%s): %s", fileKey, err.Error())
+	}
+}
+
+// returns the first file part of the multipart request with the given form name,
+// parts before it are skipped
+func fileFormPart(r *http.Request, formName string) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("no %q file in the request", formName)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if part.FormName() == formName && part.FileName() != "" {
+			return part, nil
+		}
+		part.Close()
+	}
 }
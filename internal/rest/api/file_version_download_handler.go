@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// DownloadFileVersion streams the content of the file version from the storage
+// @Summary      Download a file version
+// @Description  Streams the content of the file version. Supports the same Range and conditional headers as the file download.
+// @Tags         file
+// @Param        folder_id          path      int64   true   "Folder ID"
+// @Param        file_id            path      int64   true   "File ID"
+// @Param        version            path      int     true   "Version number"
+// @Param        user_id            header    int     true   "User ID"
+// @Param        Range              header    string  false  "Requested byte ranges, e.g. bytes=0-1023"
+// @Param        If-None-Match      header    string  false  "ETag of the cached version"
+// @Param        If-Modified-Since  header    string  false  "Date of the cached version"
+// @Produce      octet-stream
+// @Success      200  {file}    file           "Version content"
+// @Success      206  {file}    file           "Requested part of the version content"
+// @Success      304  {object}  nil            "Version is not modified"
+// @Failure      400  {object}  ErrorResponse  "Invalid folder_id, file_id or version"
+// @Failure      404  {object}  ErrorResponse  "File or version not found"
+// @Failure      416  {string}  string         "Requested range not satisfiable"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/files/{file_id}/versions/{version}/content [get]
+func (h *Handler) DownloadFileVersion() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.downloadFileVersion(w, r)
+	})
+}
+
+func (h *Handler) downloadFileVersion(w http.ResponseWriter, r *http.Request) {
+	number, ok := readVersionNumber(w, r)
+	if !ok {
+		return
+	}
+
+	file := h.loadFolderFile(w, r)
+	if file == nil {
+		return
+	}
+
+	version, err := h.fileService.GetFileVersion(file.ID, number)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Version not found")
+			return
+		}
+		log.Warn().Msgf("failed to get version %d of file(%d): %s", number, file.ID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to download file")
+		return
+	}
+
+	h.serveContent(w, r, file.Name, *version)
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// RestoreFileVersion makes the content of the previous version current again
+// @Summary      Restore a file version
+// @Description  Uploads the content of the version as the new current version of the file, so no version is lost.
+// @Description  Restoring the current version changes nothing.
+// @Tags         file
+// @Param        folder_id   path      int64  true  "Folder ID"
+// @Param        file_id     path      int64  true  "File ID"
+// @Param        version     path      int    true  "Version number"
+// @Param        user_id     header    int    true  "User ID"
+// @Produce      json
+// @Success      200  {object}  FileResponse   "Version successfully restored"
+// @Failure      400  {object}  ErrorResponse  "Invalid folder_id, file_id or version"
+// @Failure      404  {object}  ErrorResponse  "File or version not found"
+// @Failure      413  {object}  QuotaErrorResponse  "Version is larger than the storage quota"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
+// @Router       /v1/folders/{folder_id}/files/{file_id}/versions/{version}/restore [post]
+func (h *Handler) RestoreFileVersion() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.restoreFileVersion(w, r)
+	})
+}
+
+func (h *Handler) restoreFileVersion(w http.ResponseWriter, r *http.Request) {
+	number, ok := readVersionNumber(w, r)
+	if !ok {
+		return
+	}
+
+	file := h.loadFolderFile(w, r)
+	if file == nil {
+		return
+	}
+
+	restored, err := h.fileService.RestoreFileVersion(file.ID, number)
+	if err != nil {
+		log.Info().Msgf("Failed to restore version %d of file(%d): %s", number, file.ID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Version not found")
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
+		FailedResponse(w, http.StatusInternalServerError, "Failed to restore file version")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newFileResponse(restored))
+}
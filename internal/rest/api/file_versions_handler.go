@@ -0,0 +1,84 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// ListFileVersions returns the version history of the file
+// @Summary      List file versions
+// @Description  Returns all kept versions of the file, newest first. The first one is the current content of the file.
+// @Tags         file
+// @Param        folder_id   path      int64  true  "Folder ID"
+// @Param        file_id     path      int64  true  "File ID"
+// @Param        user_id     header    int    true  "User ID"
+// @Produce      json
+// @Success      200  {array}   FileVersionResponse  "File versions"
+// @Failure      400  {object}  ErrorResponse        "Invalid folder_id or file_id"
+// @Failure      404  {object}  ErrorResponse        "File not found"
+// @Failure      500  {object}  ErrorResponse        "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/files/{file_id}/versions [get]
+func (h *Handler) ListFileVersions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.listFileVersions(w, r)
+	})
+}
+
+// FileVersionResponse represents a version of the file content
+type FileVersionResponse struct {
+	Version int   `json:"version"`
+	Size    int64 `json:"size"`
+	// Checksum is hex encoded SHA-256 of the content, empty for versions uploaded before checksums were calculated
+	Checksum string `json:"checksum,omitempty"`
+	MD5      string `json:"md5,omitempty"`
+	// Current is true for the version which is the content of the file now
+	Current   bool      `json:"current"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (h *Handler) listFileVersions(w http.ResponseWriter, r *http.Request) {
+	file := h.loadFolderFile(w, r)
+	if file == nil {
+		return
+	}
+
+	versions, err := h.fileService.ListFileVersions(file.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "File not found")
+			return
+		}
+		log.Warn().Msgf("failed to list versions of file(%d): %s", file.ID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to list file versions")
+		return
+	}
+
+	response := make([]FileVersionResponse, 0, len(versions))
+	for _, version := range versions {
+		response = append(response, FileVersionResponse{
+			Version:   version.Version,
+			Size:      version.Size,
+			Checksum:  version.Checksum,
+			MD5:       version.MD5,
+			Current:   version.Version == file.Version,
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	SuccessfulResponse(w, http.StatusOK, response)
+}
+
+// reads the version number from the path, if it is invalid - writes the failed response and returns false
+func readVersionNumber(w http.ResponseWriter, r *http.Request) (int, bool) {
+	version, err := strconv.Atoi(r.PathValue("version"))
+	if err != nil || version <= 0 {
+		FailedResponse(w, http.StatusBadRequest, "Invalid version")
+		return 0, false
+	}
+	return version, true
+}
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// CopyFolder copies the requested folder with all its content into another folder
+// @Summary      Copy a folder
+// @Description  Copies a specified folder with all subfolders and files into the new parent folder, the content of files is shared with the source
+// @Tags         folder
+// @Param        user_id     header    int                true  "User ID"
+// @Param        folder_id   path      int64              true  "Folder ID"
+// @Param        copyFolder  body      CopyFolderRequest  true  "New parent folder ID"
+// @Param        on_conflict query     string             false "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
+// @Produce      json
+// @Success      201  {object}  FolderResponse  "Folder successfully copied"
+// @Failure      400  {object}  ErrorResponse   "Invalid folder_id, request body or the folder is copied into itself"
+// @Failure      404  {object}  ErrorResponse   "Folder not found"
+// @Failure      409  {object}  ErrorResponse   "Folder with the same name already exists in the new parent folder"
+// @Failure      413  {object}  QuotaErrorResponse  "Copy is larger than the storage quota"
+// @Failure      500  {object}  ErrorResponse   "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
+// @Router       /v1/folders/{folder_id}/copy [post]
+func (h *Handler) CopyFolder() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.copyFolder(w, r)
+	})
+}
+
+// CopyFolderRequest structure to handle folder copy requests
+type CopyFolderRequest struct {
+	NewFolderID int64 `json:"new_folder_id"`
+}
+
+func (h *Handler) copyFolder(w http.ResponseWriter, r *http.Request) {
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
+	// Read request body
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	// Decode the JSON data into struct
+	var data CopyFolderRequest
+	err = json.Unmarshal(body, &data)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+		return
+	}
+
+	// Copy the folder tree and re-calculate sizes
+	folder, err := h.folderService.CopyFolder(folderID, data.NewFolderID, policy)
+	if err != nil {
+		log.Info().Msgf("Failed to copy folder(%d) to %d: %s", folderID, data.NewFolderID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		if errors.Is(err, models.ErrInvalidDestination) {
+			FailedResponse(w, http.StatusBadRequest, "Folder can't be copied into itself")
+			return
+		}
+		if nameErrorResponse(w, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
+		FailedResponse(w, http.StatusInternalServerError, "Failed to copy folder")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusCreated, newFolderResponse(folder))
+}
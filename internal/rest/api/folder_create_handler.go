@@ -15,9 +15,11 @@ import (
 // @Tags         folder
 // @Param        user_id         header    int         true  "User ID"
 // @Param        requestData     body      RequestData true  "Folder creation request payload"
+// @Param        on_conflict     query     string      false "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
 // @Produce      json
 // @Success      201  {object}  NewFolderResponse "Folder successfully created"
 // @Failure      400  {object}  ErrorResponse     "Invalid request data or folder creation failed"
+// @Failure      409  {object}  ErrorResponse     "Folder with the same name already exists"
 // @Failure      500  {object}  ErrorResponse     "Internal Server Error"
 // @Router       /v1/folders [post]
 func (h *Handler) CreateFolder() http.Handler {
@@ -40,6 +42,12 @@ type NewFolderResponse struct {
 func (h *Handler) createFolder(w http.ResponseWriter, r *http.Request) {
 	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
 
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -57,9 +65,12 @@ func (h *Handler) createFolder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Create folder in database
-	folderID, err := h.folderService.CreateFolder(userID, data.Name, data.ParentFolderID)
+	folderID, err := h.folderService.CreateFolder(userID, data.Name, data.ParentFolderID, policy)
 	if err != nil {
 		log.Info().Msgf("Error on folder creation: %s", err.Error())
+		if nameErrorResponse(w, err) {
+			return
+		}
 		FailedResponse(w, http.StatusBadRequest, "Failed to create folder")
 		return
 	}
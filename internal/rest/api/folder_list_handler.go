@@ -0,0 +1,250 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+// ListFolder returns a page of the folder content
+// @Summary      List folder content
+// @Description  Returns subfolders and files of the folder page by page, subfolders are always listed before files. The breadcrumbs and the path of the folder are returned with every page. Pass next_cursor of the response to get the next page with the same sort and order.
+// @Tags         folder
+// @Param        user_id    header    int     true   "User ID"
+// @Param        folder_id  path      int64   true   "Folder ID"
+// @Param        sort       query     string  false  "Sort key"  Enums(name, size, created_at)  default(name)
+// @Param        order      query     string  false  "Sort order"  Enums(asc, desc)  default(asc)
+// @Param        type       query     string  false  "List only folders or only files"  Enums(folder, file)
+// @Param        limit      query     int     false  "Page size, up to 1000"  default(100)
+// @Param        cursor     query     string  false  "Cursor of the next page"
+// @Produce      json
+// @Success      200  {object}  FolderListResponse  "Page of the folder content"
+// @Failure      400  {object}  ErrorResponse       "Invalid query parameters"
+// @Failure      404  {object}  ErrorResponse       "Folder not found"
+// @Failure      500  {object}  ErrorResponse       "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/children [get]
+func (h *Handler) ListFolder() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.listFolder(w, r)
+	})
+}
+
+// FolderListResponse represents a page of the folder content
+type FolderListResponse struct {
+	Folder ListItem   `json:"folder"`
+	Items  []ListItem `json:"items"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor,omitempty"`
+	Location
+}
+
+// ListItem represents a folder or a file in the listing
+type ListItem struct {
+	Type      string     `json:"type"`
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Size      int64      `json:"size"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// FileCount and FolderCount are numbers of direct children, set only for folders
+	FileCount   *int64 `json:"file_count,omitempty"`
+	FolderCount *int64 `json:"folder_count,omitempty"`
+	// MaxSize is the size limit of a folder, it is not set for unlimited folders
+	MaxSize  *int64 `json:"max_size,omitempty"`
+	Checksum string `json:"checksum,omitempty"`
+}
+
+func (h *Handler) listFolder(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	opts, err := parseListOptions(r)
+	if err != nil {
+		log.Info().Msgf("Invalid listing parameters: %s", err.Error())
+		FailedResponse(w, http.StatusBadRequest, "Invalid listing parameters")
+		return
+	}
+
+	listing, err := h.folderService.ListFolder(folderID, opts)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		log.Warn().Msgf("Failed to list folder(%d): %s", folderID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to list folder")
+		return
+	}
+
+	if listing.Folder.UserID != userID {
+		FailedResponse(w, http.StatusNotFound, "Folder not found")
+		return
+	}
+
+	response := FolderListResponse{
+		Folder: folderListItem(listing.Folder),
+		Items:  make([]ListItem, 0, len(listing.Folders)+len(listing.Files)),
+	}
+	for _, folder := range listing.Folders {
+		response.Items = append(response.Items, folderListItem(folder))
+	}
+	for _, file := range listing.Files {
+		response.Items = append(response.Items, ListItem{
+			Type:      models.ItemTypeFile,
+			ID:        file.ID,
+			Name:      file.Name,
+			Size:      file.Size,
+			CreatedAt: file.CreatedAt,
+			Checksum:  file.Checksum,
+		})
+	}
+	if listing.Next != nil {
+		response.NextCursor = encodeListCursor(listing.Next, opts)
+	}
+	if response.Location, err = h.folderLocation(folderID); err != nil {
+		log.Warn().Msgf("Failed to get location of folder(%d): %s", folderID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to list folder")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, response)
+}
+
+func folderListItem(folder models.FolderEntry) ListItem {
+	return ListItem{
+		Type:        models.ItemTypeFolder,
+		ID:          folder.ID,
+		Name:        folder.Name,
+		Size:        folder.Size,
+		CreatedAt:   folder.CreatedAt,
+		UpdatedAt:   &folder.UpdatedAt,
+		FileCount:   &folder.FileCount,
+		FolderCount: &folder.FolderCount,
+		MaxSize:     folder.MaxSize,
+	}
+}
+
+// parses sort, order, type, limit and cursor query parameters
+func parseListOptions(r *http.Request) (models.ListOptions, error) {
+	query := r.URL.Query()
+	opts := models.ListOptions{
+		Sort:  models.SortByName,
+		Limit: defaultListLimit,
+	}
+
+	switch sort := query.Get("sort"); sort {
+	case "":
+	case models.SortByName, models.SortBySize, models.SortByCreatedAt:
+		opts.Sort = sort
+	default:
+		return opts, fmt.Errorf("unsupported sort: %q", sort)
+	}
+
+	switch order := query.Get("order"); order {
+	case "", "asc":
+	case "desc":
+		opts.Desc = true
+	default:
+		return opts, fmt.Errorf("unsupported order: %q", order)
+	}
+
+	switch itemType := query.Get("type"); itemType {
+	case "", models.ItemTypeFolder, models.ItemTypeFile:
+		opts.Type = itemType
+	default:
+		return opts, fmt.Errorf("unsupported type: %q", itemType)
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value < 1 || value > maxListLimit {
+			return opts, fmt.Errorf("invalid limit: %q", limit)
+		}
+		opts.Limit = value
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		after, err := decodeListCursor(cursor, opts)
+		if err != nil {
+			return opts, err
+		}
+		opts.After = after
+	}
+
+	return opts, nil
+}
+
+// listCursor is the serialized models.ListCursor, sort and order are kept
+// to reject a cursor used with other parameters than its page was requested with
+type listCursor struct {
+	Sort      string     `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	Type      string     `json:"t"`
+	ID        int64      `json:"i,omitempty"`
+	Name      string     `json:"n,omitempty"`
+	Size      int64      `json:"z,omitempty"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+}
+
+// returns an opaque cursor of the next page
+func encodeListCursor(cursor *models.ListCursor, opts models.ListOptions) string {
+	c := listCursor{
+		Sort: opts.Sort,
+		Desc: opts.Desc,
+		Type: cursor.Type,
+		ID:   cursor.ID,
+		Name: cursor.Name,
+		Size: cursor.Size,
+	}
+	if opts.Sort == models.SortByCreatedAt && cursor.ID != 0 {
+		c.CreatedAt = &cursor.CreatedAt
+	}
+
+	// marshaling of the plain struct can't fail
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parses the cursor and checks that it belongs to a listing with the same parameters
+func decodeListCursor(encoded string, opts models.ListOptions) (*models.ListCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	var c listCursor
+	if err = json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+
+	if c.Sort != opts.Sort || c.Desc != opts.Desc {
+		return nil, errors.New("cursor belongs to a listing with another sort or order")
+	}
+	if c.Type != models.ItemTypeFolder && c.Type != models.ItemTypeFile || opts.Type != "" && opts.Type != c.Type {
+		return nil, errors.New("cursor belongs to a listing of another type")
+	}
+
+	cursor := &models.ListCursor{Type: c.Type, ID: c.ID, Name: c.Name, Size: c.Size}
+	if c.CreatedAt != nil {
+		cursor.CreatedAt = *c.CreatedAt
+	}
+	return cursor, nil
+}
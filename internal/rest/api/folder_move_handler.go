@@ -2,11 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+
+	"github.com/saur4ig/file-storage/internal/models"
 )
 
 // MoveFolder changes the parent folder of the requested folder and updates all sizes
@@ -16,10 +19,15 @@ import (
 // @Param        user_id     header    int               true  "User ID"
 // @Param        folder_id   path      int64             true  "Folder ID"
 // @Param        moveFolder  body      MoveFolderRequest true  "New parent folder ID"
+// @Param        on_conflict query     string            false "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
 // @Produce      json
 // @Success      200  {object}  nil               "Folder successfully moved"
-// @Failure      400  {object}  ErrorResponse     "Invalid folder_id or request body"
+// @Failure      400  {object}  ErrorResponse     "Invalid folder_id or request body, or the root folder is moved"
+// @Failure      404  {object}  ErrorResponse     "Folder not found"
+// @Failure      409  {object}  ErrorResponse     "Folder with the same name already exists in the new parent folder"
+// @Failure      413  {object}  QuotaErrorResponse  "Moved item is larger than the folder size limit"
 // @Failure      500  {object}  ErrorResponse     "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Folder size limit exceeded"
 // @Router       /v1/folders/{folder_id}/move [put]
 func (h *Handler) MoveFolder() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -39,6 +47,12 @@ func (h *Handler) moveFolder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
 	// Read request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -56,9 +70,23 @@ func (h *Handler) moveFolder(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Move folder and re-calculate sizes
-	err = h.folderService.MoveFolder(folderID, data.NewFolderID)
+	err = h.folderService.MoveFolder(folderID, data.NewFolderID, policy)
 	if err != nil {
 		log.Info().Msgf("Failed to move folder(%d) to %d: %s", folderID, data.NewFolderID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		if errors.Is(err, models.ErrRootFolder) {
+			FailedResponse(w, http.StatusBadRequest, "Root folder can't be moved")
+			return
+		}
+		if nameErrorResponse(w, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
 		FailedResponse(w, http.StatusInternalServerError, "Failed to move folder")
 		return
 	}
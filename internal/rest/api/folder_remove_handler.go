@@ -1,21 +1,24 @@
 package api
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
 )
 
-// RemoveFolder removes a folder and updates all related sizes
+// RemoveFolder moves a folder to the trash and updates all related sizes
 // @Summary      Remove a folder
-// @Description  Deletes a specified folder and updates size calculations for all related folders
+// @Description  Moves a specified folder with all its content to the trash and updates size calculations for all related folders. The root folder can't be removed
 // @Tags         folder
 // @Param        user_id     header    int     true  "User ID"
 // @Param        folder_id   path      int64   true  "Folder ID"
 // @Produce      json
 // @Success      204  {object}  nil               "Folder successfully removed"
-// @Failure      400  {object}  ErrorResponse     "Invalid folder_id"
+// @Failure      400  {object}  ErrorResponse     "Invalid folder_id or the folder is the root folder"
+// @Failure      404  {object}  ErrorResponse     "Folder not found"
 // @Failure      500  {object}  ErrorResponse     "Failed to remove folder"
 // @Router       /v1/folders/{folder_id} [delete]
 func (h *Handler) RemoveFolder() http.Handler {
@@ -35,6 +38,14 @@ func (h *Handler) removeFolder(w http.ResponseWriter, r *http.Request) {
 	err = h.folderService.DeleteFolder(folderID)
 	if err != nil {
 		log.Info().Msgf("Failed to remove folder(%d): %s", folderID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		if errors.Is(err, models.ErrRootFolder) {
+			FailedResponse(w, http.StatusBadRequest, "Root folder can't be removed")
+			return
+		}
 		FailedResponse(w, http.StatusInternalServerError, "Failed to remove folder")
 		return
 	}
@@ -0,0 +1,118 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// RenameFolder changes the name of the requested folder
+// @Summary      Rename a folder
+// @Description  Changes the name of a specified folder, the name must be unique among its siblings
+// @Tags         folder
+// @Param        user_id      header    int            true  "User ID"
+// @Param        folder_id    path      int64          true  "Folder ID"
+// @Param        renameFolder body      RenameRequest  true  "New folder name"
+// @Param        on_conflict  query     string         false "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
+// @Produce      json
+// @Success      200  {object}  FolderResponse  "Folder successfully renamed"
+// @Failure      400  {object}  ErrorResponse   "Invalid folder_id, request body or name"
+// @Failure      404  {object}  ErrorResponse   "Folder not found"
+// @Failure      409  {object}  ErrorResponse   "Folder with the same name already exists"
+// @Failure      500  {object}  ErrorResponse   "Internal Server Error"
+// @Router       /v1/folders/{folder_id} [patch]
+func (h *Handler) RenameFolder() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.renameFolder(w, r)
+	})
+}
+
+// RenameRequest represents the request payload to rename a folder or a file
+type RenameRequest struct {
+	Name string `json:"name"`
+}
+
+// FolderResponse represents a folder
+type FolderResponse struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	ParentFolderID *int64 `json:"parent_folder_id,omitempty"`
+	Size           int64  `json:"size"`
+	// MaxSize is the size limit of the folder, it is not set for unlimited folders
+	MaxSize   *int64    `json:"max_size,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Location
+}
+
+func (h *Handler) renameFolder(w http.ResponseWriter, r *http.Request) {
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
+	data, ok := readRenameRequest(w, r)
+	if !ok {
+		return
+	}
+
+	folder, err := h.folderService.RenameFolder(folderID, data.Name, policy)
+	if err != nil {
+		log.Info().Msgf("Failed to rename folder(%d): %s", folderID, err.Error())
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		if nameErrorResponse(w, err) {
+			return
+		}
+		FailedResponse(w, http.StatusInternalServerError, "Failed to rename folder")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newFolderResponse(folder))
+}
+
+func newFolderResponse(folder *models.Folder) FolderResponse {
+	return FolderResponse{
+		ID:             folder.ID,
+		Name:           folder.Name,
+		ParentFolderID: folder.ParentFolderID,
+		Size:           folder.Size,
+		MaxSize:        folder.MaxSize,
+		CreatedAt:      folder.CreatedAt,
+		UpdatedAt:      folder.UpdatedAt,
+	}
+}
+
+// reads and decodes the rename request body,
+// if something is wrong - writes the failed response and returns false
+func readRenameRequest(w http.ResponseWriter, r *http.Request) (RenameRequest, bool) {
+	var data RenameRequest
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return data, false
+	}
+	defer r.Body.Close()
+
+	if err = json.Unmarshal(body, &data); err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+		return data, false
+	}
+	return data, true
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// SetFolderSizeLimit changes the max size of the folder
+// @Summary      Set the folder size limit
+// @Description  Limits the size of the folder with all its content, null removes the limit. Uploads, moves, copies and restores
+// @Description  which would push the folder or any of its parent folders over the limit are rejected. A limit below the current size
+// @Description  does not delete anything, but the folder can't grow anymore.
+// @Tags         folder
+// @Param        user_id      header    int                     true  "User ID"
+// @Param        folder_id    path      int64                   true  "Folder ID"
+// @Param        sizeLimit    body      FolderSizeLimitRequest  true  "Size limit"
+// @Success      204  {object}  nil            "Limit successfully changed"
+// @Failure      400  {object}  ErrorResponse  "Invalid folder_id or request body"
+// @Failure      404  {object}  ErrorResponse  "Folder not found"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/size_limit [put]
+func (h *Handler) SetFolderSizeLimit() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.setFolderSizeLimit(w, r)
+	})
+}
+
+// FolderSizeLimitRequest represents the request payload to change the size limit of the folder
+type FolderSizeLimitRequest struct {
+	// MaxSize is the max size of the folder in bytes, null removes the limit
+	MaxSize *int64 `json:"max_size"`
+}
+
+func (h *Handler) setFolderSizeLimit(w http.ResponseWriter, r *http.Request) {
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	var data FolderSizeLimitRequest
+	if err = json.Unmarshal(body, &data); err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+		return
+	}
+	if data.MaxSize != nil && *data.MaxSize < 0 {
+		FailedResponse(w, http.StatusBadRequest, "max_size can't be negative")
+		return
+	}
+
+	if err = h.folderService.SetFolderMaxSize(folderID, data.MaxSize); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		log.Warn().Msgf("failed to set max size of folder(%d): %s", folderID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to change folder size limit")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+const defaultTreeDepth = 1
+
+// FolderTree returns the folder with its nested subfolders
+// @Summary      Get folder tree
+// @Description  Returns the folder with its subfolders nested down to the depth, like 'du -d N'. Sizes are in bytes and file counts include all subfolders, also the ones below the depth. Files of the folders above the depth are added on request. The tree is streamed while it is read from the database.
+// @Tags         folder
+// @Param        user_id    header    int     true   "User ID"
+// @Param        folder_id  path      int64   true   "Folder ID"
+// @Param        depth      query     int     false  "Number of levels below the folder, 0 for the folder alone"  default(1)
+// @Param        files      query     bool    false  "Add files of the folders"  default(false)
+// @Produce      json
+// @Success      200  {object}  TreeFolderResponse  "Folder tree"
+// @Failure      400  {object}  ErrorResponse       "Invalid query parameters"
+// @Failure      404  {object}  ErrorResponse       "Folder not found"
+// @Failure      500  {object}  ErrorResponse       "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/tree [get]
+func (h *Handler) FolderTree() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.folderTree(w, r)
+	})
+}
+
+// TreeFolderResponse represents a folder of the tree with its content
+type TreeFolderResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// Size is the size of the folder with all its content in bytes
+	Size int64 `json:"size"`
+	// FileCount is the number of files in the folder with all its subfolders
+	FileCount int64 `json:"file_count"`
+	// Files are set only on request, for the folders above the depth
+	Files   []TreeFileResponse   `json:"files,omitempty"`
+	Folders []TreeFolderResponse `json:"folders,omitempty"`
+}
+
+// TreeFileResponse represents a file of the tree
+type TreeFileResponse struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+}
+
+func (h *Handler) folderTree(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	opts, err := parseTreeOptions(r)
+	if err != nil {
+		log.Info().Msgf("Invalid tree parameters: %s", err.Error())
+		FailedResponse(w, http.StatusBadRequest, "Invalid tree parameters")
+		return
+	}
+
+	tree := &treeWriter{w: w}
+	err = h.folderService.WalkFolderTree(userID, folderID, opts, tree.write)
+	if err != nil && !tree.started() {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		log.Warn().Msgf("Failed to get tree of folder(%d): %s", folderID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get folder tree")
+		return
+	}
+	if err != nil {
+		// a part of the tree is already sent, the connection is broken, so it isn't taken as the whole tree
+		log.Warn().Msgf("Failed to stream tree of folder(%d): %s", folderID, err.Error())
+		panic(http.ErrAbortHandler)
+	}
+
+	if err = tree.close(); err != nil {
+		log.Warn().Msgf("Error occured during the response: %s", err.Error())
+	}
+}
+
+// parses depth and files query parameters
+func parseTreeOptions(r *http.Request) (models.TreeOptions, error) {
+	opts := models.TreeOptions{Depth: defaultTreeDepth}
+	query := r.URL.Query()
+
+	if value := query.Get("depth"); value != "" {
+		depth, err := strconv.Atoi(value)
+		if err != nil || depth < 0 {
+			return opts, fmt.Errorf("invalid depth: %q", value)
+		}
+		opts.Depth = depth
+	}
+
+	if value := query.Get("files"); value != "" {
+		files, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, fmt.Errorf("invalid files: %q", value)
+		}
+		opts.Files = files
+	}
+
+	return opts, nil
+}
+
+// treeWriter writes the walked nodes as nested JSON objects right away, so the tree is never kept in memory.
+// A folder object stays open until a node which is not deeper than the folder comes
+type treeWriter struct {
+	w   http.ResponseWriter
+	buf *bufio.Writer
+	// open folders from the walked folder down to the last written one
+	open []treeFolder
+}
+
+// treeFolder is a folder whose object is not closed yet
+type treeFolder struct {
+	depth int
+	// array is the name of the open array of the folder content, empty if none is open
+	array string
+}
+
+func (t *treeWriter) started() bool {
+	return t.buf != nil
+}
+
+// writes the node into the content of its parent folder
+func (t *treeWriter) write(node models.TreeNode) error {
+	if !t.started() {
+		t.w.Header().Set("Content-Type", "application/json")
+		t.w.WriteHeader(http.StatusOK)
+		t.buf = bufio.NewWriter(t.w)
+	}
+
+	for len(t.open) > 0 && t.open[len(t.open)-1].depth >= node.Depth {
+		t.closeFolder()
+	}
+	if node.Depth > 0 {
+		if len(t.open) == 0 {
+			return fmt.Errorf("parent of the tree node(%d) is not written", node.ID)
+		}
+		array := "folders"
+		if node.Type == models.ItemTypeFile {
+			array = "files"
+		}
+		t.openArray(&t.open[len(t.open)-1], array)
+	}
+
+	if node.Type == models.ItemTypeFile {
+		data, err := json.Marshal(TreeFileResponse{ID: node.ID, Name: node.Name, Size: node.Size})
+		if err != nil {
+			return err
+		}
+		_, err = t.buf.Write(data)
+		return err
+	}
+
+	data, err := json.Marshal(TreeFolderResponse{
+		ID:        node.ID,
+		Name:      node.Name,
+		Size:      node.Size,
+		FileCount: node.FileCount,
+	})
+	if err != nil {
+		return err
+	}
+	// the closing brace is written after the content of the folder
+	t.open = append(t.open, treeFolder{depth: node.Depth})
+	_, err = t.buf.Write(data[:len(data)-1])
+	return err
+}
+
+// starts the array of the folder content or separates the next item in it
+func (t *treeWriter) openArray(folder *treeFolder, array string) {
+	if folder.array == array {
+		t.buf.WriteByte(',')
+		return
+	}
+	if folder.array != "" {
+		t.buf.WriteByte(']')
+	}
+	fmt.Fprintf(t.buf, `,%q:[`, array)
+	folder.array = array
+}
+
+func (t *treeWriter) closeFolder() {
+	if t.open[len(t.open)-1].array != "" {
+		t.buf.WriteByte(']')
+	}
+	t.buf.WriteByte('}')
+	t.open = t.open[:len(t.open)-1]
+}
+
+// closes all open folders and sends the rest of the tree
+func (t *treeWriter) close() error {
+	for len(t.open) > 0 {
+		t.closeFolder()
+	}
+	return t.buf.Flush()
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// SetFolderVersioning changes how many versions of its files the folder keeps
+// @Summary      Set the folder version limit
+// @Description  Sets how many versions, including the current one, are kept for each file directly in the folder.
+// @Description  null resets the limit to the configured default. Files over the new limit lose their oldest versions with the next upload.
+// @Tags         folder
+// @Param        user_id      header    int                      true  "User ID"
+// @Param        folder_id    path      int64                    true  "Folder ID"
+// @Param        versioning   body      FolderVersioningRequest  true  "Version limit"
+// @Success      204  {object}  nil            "Limit successfully changed"
+// @Failure      400  {object}  ErrorResponse  "Invalid folder_id or request body"
+// @Failure      404  {object}  ErrorResponse  "Folder not found"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/versioning [put]
+func (h *Handler) SetFolderVersioning() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.setFolderVersioning(w, r)
+	})
+}
+
+// FolderVersioningRequest represents the request payload to change the version limit of the folder
+type FolderVersioningRequest struct {
+	// MaxVersions is the number of kept versions, null for the configured default
+	MaxVersions *int `json:"max_versions"`
+}
+
+func (h *Handler) setFolderVersioning(w http.ResponseWriter, r *http.Request) {
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	var data FolderVersioningRequest
+	if err = json.Unmarshal(body, &data); err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+		return
+	}
+	if data.MaxVersions != nil && *data.MaxVersions <= 0 {
+		FailedResponse(w, http.StatusBadRequest, "max_versions must be positive")
+		return
+	}
+
+	if err = h.folderService.SetFolderMaxVersions(folderID, data.MaxVersions); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Folder not found")
+			return
+		}
+		log.Warn().Msgf("failed to set max versions of folder(%d): %s", folderID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to change folder versioning")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusNoContent, nil)
+}
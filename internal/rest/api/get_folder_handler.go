@@ -11,7 +11,7 @@ import (
 
 // GetFolder retrieves folder size information
 // @Summary      Get folder information
-// @Description  Retrieves size details of the folder specified by folder ID, similar to the 'du' command, but in JSON format
+// @Description  Retrieves size details of the folder specified by folder ID, similar to the 'du' command, but in JSON format. Only direct subfolders are returned, use the tree endpoint for deeper levels
 // @Tags         folder
 // @Param        user_id   header    int     true  "User ID"
 // @Param        folder_id path      int64   true  "Folder ID"
@@ -10,21 +10,35 @@ type Handler struct {
 	folderService      si.FolderService
 	fileService        si.FileService
 	transactionService si.TransactionService
-	s3                 si.FileStorage
+	uploadService      si.UploadService
+	trashService       si.TrashService
+	userService        si.UserService
+	storage            si.FileStorage
+	// defaultConflictPolicy is used when a request does not choose the name conflict policy
+	defaultConflictPolicy string
 }
 
 func New(
 	fs si.FolderService,
 	fileS si.FileService,
 	ts si.TransactionService,
-	s3 si.FileStorage,
+	us si.UploadService,
+	trashS si.TrashService,
+	userS si.UserService,
+	storage si.FileStorage,
 	rc _interface.FolderSizeCache,
+	defaultConflictPolicy string,
 ) *Handler {
 	return &Handler{
 		folderService:      fs,
 		fileService:        fileS,
 		transactionService: ts,
-		s3:                 s3,
+		uploadService:      us,
+		trashService:       trashS,
+		userService:        userS,
+		storage:            storage,
 		rc:                 rc,
+
+		defaultConflictPolicy: defaultConflictPolicy,
 	}
 }
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// returns the name conflict policy requested with the on_conflict query parameter or the default one
+func (h *Handler) conflictPolicy(r *http.Request) (string, error) {
+	policy := r.URL.Query().Get("on_conflict")
+	if policy == "" {
+		return h.defaultConflictPolicy, nil
+	}
+	if !models.IsConflictPolicy(policy) {
+		return "", fmt.Errorf("unsupported on_conflict: %q", policy)
+	}
+	return policy, nil
+}
+
+// returns the name conflict policy of an upload, without the on_conflict query parameter an upload
+// over an existing file adds a new version of it whatever the default policy is
+func (h *Handler) uploadConflictPolicy(r *http.Request) (string, error) {
+	if r.URL.Query().Get("on_conflict") == "" {
+		return models.ConflictReplace, nil
+	}
+	return h.conflictPolicy(r)
+}
+
+// writes the failed response if the error is caused by the item name,
+// returns false if it is some other error
+func nameErrorResponse(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrNameConflict):
+		FailedResponse(w, http.StatusConflict, "Name already exists")
+	case errors.Is(err, models.ErrInvalidName):
+		FailedResponse(w, http.StatusBadRequest, "Invalid name")
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// MakeFolderPath creates the folder at the path with all missing parents
+// @Summary      Create folders of a path
+// @Description  Returns the folder at the slash separated path under the root folder of the user, missing folders on the way are created like with "mkdir -p".
+// @Tags         path
+// @Param        user_id  header    int     true  "User ID"
+// @Param        path     path      string  true  "Slash separated path, e.g. docs/2024"
+// @Produce      json
+// @Success      200  {object}  FolderResponse  "Folder already exists"
+// @Success      201  {object}  FolderResponse  "Folder created"
+// @Failure      400  {object}  ErrorResponse   "Invalid path"
+// @Failure      409  {object}  ErrorResponse   "Path is ambiguous or a file has the name of a folder"
+// @Failure      500  {object}  ErrorResponse   "Internal Server Error"
+// @Router       /v1/paths/{path} [put]
+func (h *Handler) MakeFolderPath() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.makeFolderPath(w, r)
+	})
+}
+
+func (h *Handler) makeFolderPath(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+	path := r.PathValue("path")
+
+	folder, created, err := h.folderService.MakeFolderPath(userID, path)
+	if err != nil {
+		if pathErrorResponse(w, err) || nameErrorResponse(w, err) {
+			return
+		}
+		log.Warn().Msgf("failed to make folder path(%s) of user(%d): %s", path, userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to create folders")
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	SuccessfulResponse(w, status, newFolderResponse(folder))
+}
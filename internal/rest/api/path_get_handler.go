@@ -0,0 +1,90 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+const (
+	pathItemFolder = "folder"
+	pathItemFile   = "file"
+)
+
+// ResolvePath returns the folder or the file at the path
+// @Summary      Resolve a path
+// @Description  Returns the folder or the file at the slash separated path under the root folder of the user. A folder is preferred to a file with the same name, names match ignoring case if it is enabled in the user settings.
+// @Tags         path
+// @Param        user_id  header    int     true  "User ID"
+// @Param        path     path      string  true  "Slash separated path, e.g. docs/2024/report.pdf"
+// @Produce      json
+// @Success      200  {object}  PathResponse   "Folder or file at the path"
+// @Failure      400  {object}  ErrorResponse  "Invalid path"
+// @Failure      404  {object}  ErrorResponse  "Path not found"
+// @Failure      409  {object}  ErrorResponse  "Path matches several items ignoring case"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/paths/{path} [get]
+func (h *Handler) ResolvePath() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.resolvePath(w, r)
+	})
+}
+
+// PathResponse represents the item at a path, either the folder or the file is set
+type PathResponse struct {
+	// Type is either folder or file
+	Type   string          `json:"type"`
+	Folder *FolderResponse `json:"folder,omitempty"`
+	File   *FileResponse   `json:"file,omitempty"`
+}
+
+func (h *Handler) resolvePath(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+	path := r.PathValue("path")
+
+	item, err := h.folderService.ResolvePath(userID, path)
+	if err != nil {
+		if pathErrorResponse(w, err) {
+			return
+		}
+		log.Warn().Msgf("failed to resolve path(%s) of user(%d): %s", path, userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to resolve path")
+		return
+	}
+
+	if item.Folder != nil {
+		folder := newFolderResponse(item.Folder)
+		if folder.Location, err = h.folderLocation(item.Folder.ID); err != nil {
+			log.Warn().Msgf("failed to get location of folder(%d): %s", item.Folder.ID, err.Error())
+			FailedResponse(w, http.StatusInternalServerError, "Failed to resolve path")
+			return
+		}
+		SuccessfulResponse(w, http.StatusOK, PathResponse{Type: pathItemFolder, Folder: &folder})
+		return
+	}
+	file := newFileResponse(item.File)
+	if file.Location, err = h.fileLocation(item.File); err != nil {
+		log.Warn().Msgf("failed to get location of file(%d): %s", item.File.ID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to resolve path")
+		return
+	}
+	SuccessfulResponse(w, http.StatusOK, PathResponse{Type: pathItemFile, File: &file})
+}
+
+// writes the response for errors of path resolution, reports whether the error was handled
+func pathErrorResponse(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		FailedResponse(w, http.StatusNotFound, "Path not found")
+	case errors.Is(err, models.ErrAmbiguousPath):
+		FailedResponse(w, http.StatusConflict, "Path is ambiguous")
+	case errors.Is(err, models.ErrInvalidName):
+		FailedResponse(w, http.StatusBadRequest, "Invalid path")
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"net/http"
+	"path"
+
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// UploadFileToPath uploads a file to the path
+// @Summary      Upload a file to a path
+// @Description  Uploads a file with the last name of the slash separated path into the folder of the path under the root folder of the user, missing folders are created together with the file, a rejected upload leaves none of them. With case insensitive paths an existing file is matched ignoring case.
+// @Tags         path
+// @Param        user_id         header    int     true  "User ID"
+// @Param        path            path      string  true  "Slash separated path of the file, e.g. docs/2024/report.pdf"
+// @Param        file            formData  file    true  "File to upload"
+// @Param        Digest          header    string  false "Expected checksum of the file content, e.g. sha-256=<base64>"
+// @Param        Content-MD5     header    string  false "Expected base64 encoded MD5 of the file content"
+// @Param        on_conflict     query     string  false "Name conflict policy, a new version of the existing file by default"  Enums(fail, rename, replace)
+// @Accept       multipart/form-data
+// @Produce      json
+// @Success      201  {object}  FileResponse        "File successfully uploaded"
+// @Failure      400  {object}  ErrorResponse       "Invalid path, file upload failed or checksum mismatch"
+// @Failure      409  {object}  ErrorResponse       "Path is ambiguous or the name already exists"
+// @Failure      413  {object}  QuotaErrorResponse  "File is larger than the storage quota"
+// @Failure      500  {object}  ErrorResponse       "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
+// @Router       /v1/paths/{path} [post]
+func (h *Handler) UploadFileToPath() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.uploadFileToPath(w, r)
+	})
+}
+
+func (h *Handler) uploadFileToPath(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+	filePath := r.PathValue("path")
+
+	policy, err := h.uploadConflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
+	// the folders of the path are created when the file is saved, the name is known already for the storage
+	h.saveUploadedFile(w, r, &models.File{
+		UserID: userID,
+		Name:   path.Base("/" + filePath),
+	}, func(file *models.File) (int64, error) {
+		return h.fileService.UploadFileToPath(file, filePath, policy)
+	})
+}
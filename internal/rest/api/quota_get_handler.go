@@ -0,0 +1,109 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// GetQuota returns the storage quota of the user
+// @Summary      Get the storage quota
+// @Description  Returns the storage quota of the user and how much of it is used. The usage is the size of the root folder,
+// @Description  files uploaded in unfinished transactions are reported as pending.
+// @Tags         quota
+// @Param        user_id  header    int  true  "User ID"
+// @Produce      json
+// @Success      200  {object}  QuotaResponse  "Storage quota"
+// @Failure      404  {object}  ErrorResponse  "User not found"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/quota [get]
+func (h *Handler) GetQuota() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.getQuota(w, r)
+	})
+}
+
+// QuotaResponse represents the storage quota of the user
+type QuotaResponse struct {
+	UserID int `json:"user_id"`
+	// Quota is the max number of stored bytes, null if the storage is not limited
+	Quota *int64 `json:"quota"`
+	Used  int64  `json:"used"`
+	// Pending is the size of files uploaded in unfinished transactions
+	Pending int64 `json:"pending"`
+	// Available is the number of bytes which can be stored yet, null if the storage is not limited
+	Available *int64 `json:"available"`
+}
+
+// QuotaErrorResponse is returned when the request does not fit into the storage quota or the folder size limit
+type QuotaErrorResponse struct {
+	Error string `json:"error"`
+	// FolderID is the folder which would grow over its limit, it is not set when the storage quota is exceeded
+	FolderID  int64 `json:"folder_id,omitempty"`
+	Quota     int64 `json:"quota"`
+	Used      int64 `json:"used"`
+	Requested int64 `json:"requested"`
+}
+
+func (h *Handler) getQuota(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	quota, err := h.userService.GetUserQuota(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Warn().Msgf("failed to get quota of user(%d): %s", userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get quota")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newQuotaResponse(quota))
+}
+
+func newQuotaResponse(quota *models.UserQuota) QuotaResponse {
+	response := QuotaResponse{
+		UserID:  quota.UserID,
+		Quota:   quota.Quota,
+		Used:    quota.Used,
+		Pending: quota.Pending,
+	}
+	if quota.Quota != nil {
+		available := max(*quota.Quota-quota.Used-quota.Pending, 0)
+		response.Available = &available
+	}
+	return response
+}
+
+// writes the failed response if the request does not fit into the quota of the user or the max size of a folder,
+// returns false if it is some other error
+func quotaErrorResponse(w http.ResponseWriter, err error) bool {
+	var quotaErr *models.QuotaError
+	if !errors.As(err, &quotaErr) {
+		return false
+	}
+
+	// nothing can be deleted to make the request fit, so it is too large, otherwise the space is over
+	status, message := http.StatusInsufficientStorage, "Storage quota exceeded"
+	if quotaErr.TooLarge() {
+		status, message = http.StatusRequestEntityTooLarge, "Request is larger than the storage quota"
+	}
+	if quotaErr.FolderID != 0 {
+		message = "Folder size limit exceeded"
+		if quotaErr.TooLarge() {
+			message = "Request is larger than the folder size limit"
+		}
+	}
+	sendResponse(w, status, QuotaErrorResponse{
+		Error:     message,
+		FolderID:  quotaErr.FolderID,
+		Quota:     quotaErr.Quota,
+		Used:      quotaErr.Used,
+		Requested: quotaErr.Requested,
+	})
+	return true
+}
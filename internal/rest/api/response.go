@@ -7,8 +7,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// ErrorResponse describes why the request failed
 type ErrorResponse struct {
-	error string
+	Error string `json:"error"`
 }
 
 func sendResponse(w http.ResponseWriter, httpStatus int, body interface{}) {
@@ -33,6 +34,6 @@ func SuccessfulResponse(w http.ResponseWriter, httpStatus int, body interface{})
 
 func FailedResponse(w http.ResponseWriter, httpStatus int, message string) {
 	sendResponse(w, httpStatus, ErrorResponse{
-		error: "message",
+		Error: message,
 	})
 }
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// GetSettings returns the settings of the user
+// @Summary      Get the user settings
+// @Description  Returns the settings of the user.
+// @Tags         settings
+// @Param        user_id  header    int  true  "User ID"
+// @Produce      json
+// @Success      200  {object}  SettingsResponse  "User settings"
+// @Failure      404  {object}  ErrorResponse     "User not found"
+// @Failure      500  {object}  ErrorResponse     "Internal Server Error"
+// @Router       /v1/settings [get]
+func (h *Handler) GetSettings() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.getSettings(w, r)
+	})
+}
+
+// SettingsResponse represents the settings of the user
+type SettingsResponse struct {
+	UserID int `json:"user_id"`
+	// CaseInsensitivePaths makes paths match folder and file names ignoring case
+	CaseInsensitivePaths bool `json:"case_insensitive_paths"`
+}
+
+func (h *Handler) getSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	settings, err := h.userService.GetUserSettings(userID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Warn().Msgf("failed to get settings of user(%d): %s", userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get settings")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newSettingsResponse(settings))
+}
+
+func newSettingsResponse(settings *models.UserSettings) SettingsResponse {
+	return SettingsResponse{
+		UserID:               settings.UserID,
+		CaseInsensitivePaths: settings.CaseInsensitivePaths,
+	}
+}
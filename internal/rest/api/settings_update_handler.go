@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// UpdateSettings changes the settings of the user
+// @Summary      Update the user settings
+// @Description  Changes the settings of the user, settings missing in the request keep their values.
+// @Tags         settings
+// @Param        user_id   header    int                    true  "User ID"
+// @Param        settings  body      UpdateSettingsRequest  true  "Changed settings"
+// @Produce      json
+// @Success      200  {object}  SettingsResponse  "Settings successfully changed"
+// @Failure      400  {object}  ErrorResponse     "Invalid request body"
+// @Failure      404  {object}  ErrorResponse     "User not found"
+// @Failure      500  {object}  ErrorResponse     "Internal Server Error"
+// @Router       /v1/settings [put]
+func (h *Handler) UpdateSettings() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.updateSettings(w, r)
+	})
+}
+
+// UpdateSettingsRequest represents the request payload to change the settings of the user
+type UpdateSettingsRequest struct {
+	CaseInsensitivePaths *bool `json:"case_insensitive_paths"`
+}
+
+func (h *Handler) updateSettings(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	var data UpdateSettingsRequest
+	if err = json.Unmarshal(body, &data); err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+		return
+	}
+
+	settings, err := h.userService.GetUserSettings(userID)
+	if err == nil {
+		if data.CaseInsensitivePaths != nil {
+			settings.CaseInsensitivePaths = *data.CaseInsensitivePaths
+		}
+		settings, err = h.userService.UpdateUserSettings(settings)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Warn().Msgf("failed to update settings of user(%d): %s", userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to update settings")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newSettingsResponse(settings))
+}
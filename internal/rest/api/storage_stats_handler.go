@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// GetStorageStats returns size of all files before and after deduplication
+// @Summary      Get storage statistics
+// @Description  Returns logical size of all files (as it is reported for folders) and physical size of the stored objects, files with the same content share one object. Covers files of all users, available to admins only.
+// @Tags         storage
+// @Param        user_id   header    int     true  "User ID"
+// @Produce      json
+// @Success      200  {object}  StorageStatsResponse  "Storage statistics"
+// @Failure      403  {string}  string                "Not an admin"
+// @Failure      500  {object}  ErrorResponse         "Internal Server Error"
+// @Router       /v1/storage/stats [get]
+func (h *Handler) GetStorageStats() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.getStorageStats(w, r)
+	})
+}
+
+// StorageStatsResponse represents logical and physical usage of the storage
+type StorageStatsResponse struct {
+	Files int64 `json:"files"`
+	// LogicalSize is the total size of all files in bytes
+	LogicalSize int64 `json:"logical_size"`
+	Objects     int64 `json:"objects"`
+	// PhysicalSize is the total size of all stored objects in bytes
+	PhysicalSize int64 `json:"physical_size"`
+}
+
+func (h *Handler) getStorageStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.fileService.GetStorageStats()
+	if err != nil {
+		log.Warn().Msgf("Failed to get storage stats: %s", err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get storage stats")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, StorageStatsResponse{
+		Files:        stats.Files,
+		LogicalSize:  stats.LogicalSize,
+		Objects:      stats.Objects,
+		PhysicalSize: stats.PhysicalSize,
+	})
+}
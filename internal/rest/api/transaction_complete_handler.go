@@ -1,26 +1,27 @@
 package api
 
 import (
-	"context"
-	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
 )
 
 // CompleteTransaction completes an ongoing transaction
 // @Summary      Complete a transaction
-// @Description  Completes the specified transaction by updating its status to "completed" and updating folder sizes
+// @Description  Completes the specified pending or resumed transaction of the folder by updating its status to "completed", files uploaded in it are added to the folder sizes
 // @Tags         transaction
 // @Param        user_id         header    int     true  "User ID"
 // @Param        folder_id       path      int64   true  "Folder ID"
 // @Param        transaction_id  path      int64   true  "Transaction ID"
 // @Produce      json
-// @Success      200  {object}  nil               "Transaction successfully completed"
-// @Failure      400  {object}  ErrorResponse     "Invalid transaction_id"
-// @Failure      404  {object}  ErrorResponse     "Transaction not found"
-// @Failure      500  {object}  ErrorResponse     "Internal Server Error"
+// @Success      200  {object}  nil                 "Transaction successfully completed"
+// @Failure      400  {object}  ErrorResponse       "Invalid folder_id or transaction_id"
+// @Failure      404  {object}  ErrorResponse       "Transaction not found"
+// @Failure      409  {object}  ErrorResponse       "Transaction is neither pending nor resumed"
+// @Failure      500  {object}  ErrorResponse       "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Folder size limit exceeded"
 // @Router       /v1/folders/{folder_id}/transaction/{transaction_id}/complete [put]
 func (h *Handler) CompleteTransaction() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -29,56 +30,29 @@ func (h *Handler) CompleteTransaction() http.Handler {
 }
 
 func (h *Handler) completeTransaction(w http.ResponseWriter, r *http.Request) {
-	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
-	if err != nil {
-		FailedResponse(w, http.StatusBadRequest, "Invalid transaction_id")
-		return
-	}
-
-	// Get transaction from the db
-	transaction, err := h.transactionService.GetTransactionByID(transactionID)
-	if err != nil {
-		FailedResponse(w, http.StatusInternalServerError, "Failed to get transaction")
-		return
-	}
-	if transaction == nil {
-		FailedResponse(w, http.StatusNotFound, fmt.Sprintf("Transaction '%d' not found", transactionID))
-		return
-	}
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
 
-	// Get all parent folders affected by transaction
-	allAffectedFolders, err := h.folderService.GetAllParentFolders(transaction.FolderID)
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
 	if err != nil {
-		log.Info().Msgf("Failed to get all parents for folder(%d): %s", transaction.FolderID, err.Error())
-		FailedResponse(w, http.StatusInternalServerError, "Internal Server Error")
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
 		return
 	}
 
-	// Generate a list of keys to get all folder sizes
-	keys := make([]string, len(allAffectedFolders))
-	for i, id := range allAffectedFolders {
-		keys[i] = fmt.Sprintf("folder:%d", id)
-	}
-	ctx := context.Background()
-	// Get all folder sizes from the cache
-	foldersData, err := h.rc.GetMultipleFolders(ctx, keys)
-	if err != nil {
-		log.Info().Msgf("Failed to get folders from cache(%d): %s", transaction.FolderID, err.Error())
-		FailedResponse(w, http.StatusInternalServerError, "Internal Server Error")
-		return
-	}
-
-	// Update all folders sizes
-	err = h.folderService.UpdateMultipleFoldersSize(foldersData)
+	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
 	if err != nil {
-		log.Info().Msgf("Failed to update all sizes(%d): %s", transaction.FolderID, err.Error())
-		FailedResponse(w, http.StatusInternalServerError, "Internal Server Error")
+		FailedResponse(w, http.StatusBadRequest, "Invalid transaction_id")
 		return
 	}
 
-	// Update all sizes in the database
-	err = h.transactionService.UpdateTransactionStatus(transactionID, "completed")
+	// Add the files of the transaction to the folder sizes
+	err = h.transactionService.CompleteTransaction(userID, folderID, transactionID)
 	if err != nil {
+		if transactionErrorResponse(w, transactionID, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
 		log.Info().Msgf("Failed to complete transaction(%d): %s", transactionID, err.Error())
 		FailedResponse(w, http.StatusInternalServerError, "Failed to complete transaction")
 		return
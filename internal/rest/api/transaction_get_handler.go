@@ -0,0 +1,76 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// GetTransaction returns an upload transaction with its files
+// @Summary      Get a transaction
+// @Description  Returns the status of the upload transaction of the user, the number and the size of files uploaded in it so far and the files themselves in the upload order
+// @Tags         transaction
+// @Param        user_id         header    int     true  "User ID"
+// @Param        transaction_id  path      int64   true  "Transaction ID"
+// @Produce      json
+// @Success      200  {object}  TransactionDetailResponse  "Transaction with its files"
+// @Failure      400  {object}  ErrorResponse              "Invalid transaction_id"
+// @Failure      404  {object}  ErrorResponse              "Transaction not found"
+// @Failure      500  {object}  ErrorResponse              "Internal Server Error"
+// @Router       /v1/transactions/{transaction_id} [get]
+func (h *Handler) GetTransaction() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.getTransaction(w, r)
+	})
+}
+
+// TransactionDetailResponse represents an upload transaction with the files uploaded in it
+type TransactionDetailResponse struct {
+	TransactionResponse
+	Files []FileResponse `json:"files"`
+}
+
+func (h *Handler) getTransaction(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid transaction_id")
+		return
+	}
+
+	transaction, err := h.transactionService.GetTransactionByID(transactionID)
+	if err != nil {
+		log.Warn().Msgf("Failed to get transaction(%d): %s", transactionID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get transaction")
+		return
+	}
+	if transaction == nil || transaction.UserID != userID {
+		FailedResponse(w, http.StatusNotFound, fmt.Sprintf("Transaction '%d' not found", transactionID))
+		return
+	}
+
+	files, err := h.transactionService.GetTransactionFiles(transactionID)
+	if err != nil {
+		log.Warn().Msgf("Failed to get files of transaction(%d): %s", transactionID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get transaction")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newTransactionDetailResponse(transaction, files))
+}
+
+func newTransactionDetailResponse(transaction *models.UploadTransaction, files []models.File) TransactionDetailResponse {
+	response := TransactionDetailResponse{
+		TransactionResponse: newTransactionResponse(transaction),
+		Files:               make([]FileResponse, 0, len(files)),
+	}
+	for i := range files {
+		response.Files = append(response.Files, newFileResponse(&files[i]))
+	}
+	return response
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// ListTransactions returns upload transactions of the user
+// @Summary      List transactions
+// @Description  Returns upload transactions of the user, the newest first, with the number and the size of files uploaded in them so far
+// @Tags         transaction
+// @Param        user_id    header    int     true   "User ID"
+// @Param        status     query     string  false  "Status of the transactions"  Enums(pending, paused, resumed, completed, failed)
+// @Param        folder_id  query     int64   false  "Folder the transactions were started for"
+// @Produce      json
+// @Success      200  {array}   TransactionResponse  "Transactions"
+// @Failure      400  {object}  ErrorResponse        "Invalid status or folder_id"
+// @Failure      500  {object}  ErrorResponse        "Internal Server Error"
+// @Router       /v1/transactions [get]
+func (h *Handler) ListTransactions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.listTransactions(w, r)
+	})
+}
+
+// TransactionResponse represents an upload transaction
+type TransactionResponse struct {
+	ID       int64  `json:"id"`
+	FolderID int64  `json:"folder_id"`
+	Status   string `json:"status"`
+	// FileCount and TotalSize are the number and the size with versions of the files uploaded so far,
+	// files in the trash are not counted
+	FileCount int       `json:"file_count"`
+	TotalSize int64     `json:"total_size"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	// ExpiresAt is the time the pending or resumed transaction is rolled back at, it moves by the pause when a paused one resumes
+	ExpiresAt time.Time `json:"expires_at"`
+	// PausedAt is the time the paused transaction was paused at
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+}
+
+func (h *Handler) listTransactions(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	query := r.URL.Query()
+	filter := models.TransactionFilter{Status: query.Get("status")}
+	if filter.Status != "" && !models.IsTransactionStatus(filter.Status) {
+		FailedResponse(w, http.StatusBadRequest, "Invalid status")
+		return
+	}
+	if value := query.Get("folder_id"); value != "" {
+		folderID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || folderID <= 0 {
+			FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+			return
+		}
+		filter.FolderID = folderID
+	}
+
+	transactions, err := h.transactionService.ListTransactions(userID, filter)
+	if err != nil {
+		log.Warn().Msgf("Failed to list transactions of user(%d): %s", userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to list transactions")
+		return
+	}
+
+	response := make([]TransactionResponse, 0, len(transactions))
+	for i := range transactions {
+		response = append(response, newTransactionResponse(&transactions[i]))
+	}
+
+	SuccessfulResponse(w, http.StatusOK, response)
+}
+
+func newTransactionResponse(transaction *models.UploadTransaction) TransactionResponse {
+	return TransactionResponse{
+		ID:        transaction.ID,
+		FolderID:  transaction.FolderID,
+		Status:    transaction.Status,
+		FileCount: transaction.FileCount,
+		TotalSize: transaction.TotalSize,
+		CreatedAt: transaction.CreatedAt,
+		UpdatedAt: transaction.UpdatedAt,
+		ExpiresAt: transaction.ExpiresAt,
+		PausedAt:  transaction.PausedAt,
+	}
+}
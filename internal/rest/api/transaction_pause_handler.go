@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// PauseTransaction pauses an ongoing transaction
+// @Summary      Pause a transaction
+// @Description  Pauses the specified pending or resumed transaction of the folder. Files can't be uploaded in a paused transaction and it doesn't expire until it is resumed
+// @Tags         transaction
+// @Param        user_id         header    int     true  "User ID"
+// @Param        folder_id       path      int64   true  "Folder ID"
+// @Param        transaction_id  path      int64   true  "Transaction ID"
+// @Produce      json
+// @Success      200  {object}  TransactionResponse  "Transaction successfully paused"
+// @Failure      400  {object}  ErrorResponse        "Invalid folder_id or transaction_id"
+// @Failure      404  {object}  ErrorResponse        "Transaction not found"
+// @Failure      409  {object}  ErrorResponse        "Transaction is neither pending nor resumed"
+// @Failure      500  {object}  ErrorResponse        "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/transaction/{transaction_id}/pause [put]
+func (h *Handler) PauseTransaction() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.pauseTransaction(w, r)
+	})
+}
+
+func (h *Handler) pauseTransaction(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid transaction_id")
+		return
+	}
+
+	transaction, err := h.transactionService.PauseTransaction(userID, folderID, transactionID)
+	if err != nil {
+		if transactionErrorResponse(w, transactionID, err) {
+			return
+		}
+		log.Info().Msgf("Failed to pause transaction(%d): %s", transactionID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to pause transaction")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newTransactionResponse(transaction))
+}
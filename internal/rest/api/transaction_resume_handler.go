@@ -0,0 +1,65 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// ResumeTransaction resumes a paused transaction
+// @Summary      Resume a transaction
+// @Description  Moves the specified paused transaction of the folder to resumed, it accepts uploads again and its expiry moves by the time it was paused for. Returns the files already uploaded in it, so the client can continue with the rest
+// @Tags         transaction
+// @Param        user_id         header    int     true  "User ID"
+// @Param        folder_id       path      int64   true  "Folder ID"
+// @Param        transaction_id  path      int64   true  "Transaction ID"
+// @Produce      json
+// @Success      200  {object}  TransactionDetailResponse  "Transaction successfully resumed"
+// @Failure      400  {object}  ErrorResponse              "Invalid folder_id or transaction_id"
+// @Failure      404  {object}  ErrorResponse              "Transaction not found"
+// @Failure      409  {object}  ErrorResponse              "Transaction is not paused"
+// @Failure      500  {object}  ErrorResponse              "Internal Server Error"
+// @Router       /v1/folders/{folder_id}/transaction/{transaction_id}/resume [put]
+func (h *Handler) ResumeTransaction() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.resumeTransaction(w, r)
+	})
+}
+
+func (h *Handler) resumeTransaction(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid transaction_id")
+		return
+	}
+
+	transaction, err := h.transactionService.ResumeTransaction(userID, folderID, transactionID)
+	if err != nil {
+		if transactionErrorResponse(w, transactionID, err) {
+			return
+		}
+		log.Info().Msgf("Failed to resume transaction(%d): %s", transactionID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to resume transaction")
+		return
+	}
+
+	// Files received before the pause, the client uploads the rest
+	files, err := h.transactionService.GetTransactionFiles(transactionID)
+	if err != nil {
+		log.Info().Msgf("Failed to get files of transaction(%d): %s", transactionID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get transaction files")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newTransactionDetailResponse(transaction, files))
+}
@@ -1,9 +1,11 @@
 package api
 
 import (
-	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -13,13 +15,14 @@ import (
 
 // StartTransaction begins a new transaction for a specified folder
 // @Summary      Start a new transaction
-// @Description  Initiates a new transaction for the specified folder
+// @Description  Initiates a new transaction for the specified folder. A transaction which is not completed or stopped until it expires is rolled back with all its files
 // @Tags         transaction
 // @Param        user_id     header    int     true  "User ID"
 // @Param        folder_id   path      int64   true  "Folder ID"
+// @Param        ttl         query     string  false "Time the transaction stays pending, e.g. 30m, the configured one by default"
 // @Produce      json
 // @Success      201  {object}  TransactionStartResponse "Transaction successfully started"
-// @Failure      400  {object}  ErrorResponse            "Invalid folder_id"
+// @Failure      400  {object}  ErrorResponse            "Invalid folder_id or ttl"
 // @Failure      500  {object}  ErrorResponse            "Internal Server Error"
 // @Router       /v1/folders/{folder_id}/transaction/start [post]
 func (h *Handler) StartTransaction() http.Handler {
@@ -31,6 +34,8 @@ func (h *Handler) StartTransaction() http.Handler {
 // TransactionStartResponse structure to respond with transaction ID
 type TransactionStartResponse struct {
 	TransactionID int64 `json:"transaction_id"`
+	// ExpiresAt is the time the transaction is rolled back at unless it is completed or stopped before
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 func (h *Handler) startTransaction(w http.ResponseWriter, r *http.Request) {
@@ -42,33 +47,41 @@ func (h *Handler) startTransaction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	transactionID, err := h.transactionService.CreateTransaction(userID, folderID)
-	if err != nil {
-		log.Info().Msgf("Failed to create transaction for folder(%d): %s", folderID, err.Error())
-		FailedResponse(w, http.StatusInternalServerError, "Failed to create transaction")
-		return
+	var ttl time.Duration
+	if value := r.URL.Query().Get("ttl"); value != "" {
+		if ttl, err = time.ParseDuration(value); err != nil || ttl <= 0 {
+			FailedResponse(w, http.StatusBadRequest, "Invalid ttl")
+			return
+		}
 	}
 
-	// Get all parent folders whose size will be affected by the transaction
-	allAffectedFolders, err := h.folderService.GetAllParentFolders(folderID)
+	transaction, err := h.transactionService.CreateTransaction(userID, folderID, ttl)
 	if err != nil {
-		log.Info().Msgf("Failed to get all parents for folder(%d): %s", folderID, err.Error())
-		FailedResponse(w, http.StatusInternalServerError, "Internal Server Error")
+		if errors.Is(err, models.ErrInvalidTTL) {
+			FailedResponse(w, http.StatusBadRequest, "Invalid ttl")
+			return
+		}
+		log.Info().Msgf("Failed to create transaction for folder(%d): %s", folderID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to create transaction")
 		return
 	}
 
-	// Ensure that all of them are in cache
-	go func(folders []models.FolderSizeSimplified) {
-		ctx := context.Background()
-		for _, folder := range folders {
-			err := h.rc.SetOrUpdateFolderSize(ctx, folder.ID, folder.Size)
-			if err != nil {
-				log.Warn().Msgf("Error on updating folder(%d) size: %s", folder.ID, err.Error())
-			}
-		}
-	}(allAffectedFolders)
-
 	SuccessfulResponse(w, http.StatusCreated, TransactionStartResponse{
-		TransactionID: transactionID,
+		TransactionID: transaction.ID,
+		ExpiresAt:     transaction.ExpiresAt,
 	})
 }
+
+// writes the response for errors of transactions of the caller, reports whether the error is one of them
+func transactionErrorResponse(w http.ResponseWriter, transactionID int64, err error) bool {
+	var statusErr *models.TransactionStatusError
+	switch {
+	case errors.As(err, &statusErr):
+		FailedResponse(w, http.StatusConflict, fmt.Sprintf("Transaction '%d' is %s", transactionID, statusErr.Status))
+	case errors.Is(err, models.ErrNotFound):
+		FailedResponse(w, http.StatusNotFound, fmt.Sprintf("Transaction '%d' not found", transactionID))
+	default:
+		return false
+	}
+	return true
+}
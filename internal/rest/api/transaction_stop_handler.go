@@ -5,18 +5,21 @@ import (
 	"strconv"
 
 	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
 )
 
 // StopTransaction stops an ongoing transaction
 // @Summary      Stop a transaction
-// @Description  Stops the specified transaction by updating its status to "failed"
+// @Description  Rolls back the specified pending, paused or resumed transaction of the folder: its status is updated to "failed", files uploaded in it are deleted with the stored content and removed from the folder sizes. Stopping it again does nothing
 // @Tags         transaction
 // @Param        user_id         header    int     true  "User ID"
 // @Param        folder_id       path      int64   true  "Folder ID"
 // @Param        transaction_id  path      int64   true  "Transaction ID"
 // @Produce      json
 // @Success      200  {object}  nil               "Transaction successfully stopped"
-// @Failure      400  {object}  ErrorResponse     "Invalid transaction_id"
+// @Failure      400  {object}  ErrorResponse     "Invalid folder_id or transaction_id"
+// @Failure      404  {object}  ErrorResponse     "Transaction not found"
+// @Failure      409  {object}  ErrorResponse     "Transaction is completed"
 // @Failure      500  {object}  ErrorResponse     "Failed to stop transaction"
 // @Router       /v1/folders/{folder_id}/transaction/{transaction_id}/stop [put]
 func (h *Handler) StopTransaction() http.Handler {
@@ -26,14 +29,25 @@ func (h *Handler) StopTransaction() http.Handler {
 }
 
 func (h *Handler) stopTransaction(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
 	transactionID, err := strconv.ParseInt(r.PathValue("transaction_id"), 10, 64)
 	if err != nil {
 		FailedResponse(w, http.StatusBadRequest, "Invalid transaction_id")
 		return
 	}
 
-	err = h.transactionService.UpdateTransactionStatus(transactionID, "failed")
+	err = h.transactionService.StopTransaction(r.Context(), userID, folderID, transactionID)
 	if err != nil {
+		if transactionErrorResponse(w, transactionID, err) {
+			return
+		}
 		log.Info().Msgf("Failed to stop transaction(%d): %s", transactionID, err.Error())
 		FailedResponse(w, http.StatusInternalServerError, "Failed to stop transaction")
 		return
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// DeleteTrashItem permanently deletes the item from the trash
+// @Summary      Delete a trash item permanently
+// @Description  Permanently deletes a deleted folder with all its content or a deleted file, it can't be restored anymore
+// @Tags         trash
+// @Param        user_id   header    int     true  "User ID"
+// @Param        trash_id  path      int64   true  "Trash item ID"
+// @Produce      json
+// @Success      204  {object}  nil            "Item permanently deleted"
+// @Failure      400  {object}  ErrorResponse  "Invalid trash_id"
+// @Failure      404  {object}  ErrorResponse  "Trash item not found"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/trash/{trash_id} [delete]
+func (h *Handler) DeleteTrashItem() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.deleteTrashItem(w, r)
+	})
+}
+
+func (h *Handler) deleteTrashItem(w http.ResponseWriter, r *http.Request) {
+	item := h.loadTrashItem(w, r)
+	if item == nil {
+		return
+	}
+
+	err := h.trashService.DeleteTrashItem(item.ID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Trash item not found")
+			return
+		}
+		log.Warn().Msgf("failed to delete trash item(%d): %s", item.ID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to delete trash item")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// ListTrash returns deleted folders and files of the user
+// @Summary      List the trash
+// @Description  Returns folders and files deleted by the user, the newest first. Content of a deleted folder is not listed separately, it is restored and deleted permanently together with the folder
+// @Tags         trash
+// @Param        user_id   header    int     true  "User ID"
+// @Produce      json
+// @Success      200  {array}   TrashItemResponse  "Deleted items"
+// @Failure      500  {object}  ErrorResponse      "Internal Server Error"
+// @Router       /v1/trash [get]
+func (h *Handler) ListTrash() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.listTrash(w, r)
+	})
+}
+
+// TrashItemResponse represents a deleted folder or file
+type TrashItemResponse struct {
+	ID int64 `json:"id"`
+	// Type is folder or file
+	Type   string `json:"type"`
+	ItemID int64  `json:"item_id"`
+	Name   string `json:"name"`
+	// ParentFolderID is the folder the item was deleted from
+	ParentFolderID int64     `json:"parent_folder_id"`
+	Size           int64     `json:"size"`
+	DeletedAt      time.Time `json:"deleted_at"`
+	// ExpiresAt is the time after which the item is deleted permanently
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+func (h *Handler) listTrash(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	items, err := h.trashService.ListTrash(userID)
+	if err != nil {
+		log.Warn().Msgf("Failed to list trash of user(%d): %s", userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to list trash")
+		return
+	}
+
+	response := make([]TrashItemResponse, 0, len(items))
+	for i := range items {
+		response = append(response, newTrashItemResponse(&items[i]))
+	}
+
+	SuccessfulResponse(w, http.StatusOK, response)
+}
+
+func newTrashItemResponse(item *models.TrashItem) TrashItemResponse {
+	return TrashItemResponse{
+		ID:             item.ID,
+		Type:           item.ItemType,
+		ItemID:         item.ItemID,
+		Name:           item.Name,
+		ParentFolderID: item.ParentFolderID,
+		Size:           item.Size,
+		DeletedAt:      item.DeletedAt,
+		ExpiresAt:      item.ExpiresAt,
+	}
+}
+
+// loads the trash item by the path value and checks that it belongs to the user,
+// if something is wrong - writes the failed response and returns nil
+func (h *Handler) loadTrashItem(w http.ResponseWriter, r *http.Request) *models.TrashItem {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	trashID, err := strconv.ParseInt(r.PathValue("trash_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid trash_id")
+		return nil
+	}
+
+	item, err := h.trashService.GetTrashItem(trashID)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Trash item not found")
+			return nil
+		}
+		log.Warn().Msgf("failed to get trash item(%d): %s", trashID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to get trash item")
+		return nil
+	}
+
+	if item.UserID != userID {
+		FailedResponse(w, http.StatusNotFound, "Trash item not found")
+		return nil
+	}
+
+	return item
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// RestoreTrashItem brings the deleted folder or file back
+// @Summary      Restore a deleted item
+// @Description  Restores a deleted folder with all its content or a deleted file into the folder it was deleted from. If that folder does not exist anymore, the item is restored into parent_folder_id
+// @Tags         trash
+// @Param        user_id      header    int                 true  "User ID"
+// @Param        trash_id     path      int64               true  "Trash item ID"
+// @Param        restoreItem  body      RestoreItemRequest  false "Folder to restore into, if the original one is gone"
+// @Param        on_conflict  query     string              false "Name conflict policy, the configured one by default"  Enums(fail, rename, replace)
+// @Produce      json
+// @Success      200  {object}  FolderResponse  "Folder successfully restored, a restored file is returned as FileResponse"
+// @Failure      400  {object}  ErrorResponse   "Invalid trash_id, request body or name"
+// @Failure      404  {object}  ErrorResponse   "Trash item or parent folder not found"
+// @Failure      409  {object}  ErrorResponse   "Item with the same name already exists or the original folder is gone and parent_folder_id is not set"
+// @Failure      413  {object}  QuotaErrorResponse  "Restored item is larger than the storage quota"
+// @Failure      500  {object}  ErrorResponse   "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
+// @Router       /v1/trash/{trash_id}/restore [post]
+func (h *Handler) RestoreTrashItem() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.restoreTrashItem(w, r)
+	})
+}
+
+// RestoreItemRequest represents the optional request payload to restore a deleted item
+type RestoreItemRequest struct {
+	ParentFolderID int64 `json:"parent_folder_id"`
+}
+
+func (h *Handler) restoreTrashItem(w http.ResponseWriter, r *http.Request) {
+	item := h.loadTrashItem(w, r)
+	if item == nil {
+		return
+	}
+
+	policy, err := h.conflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
+	// Read request body, it is empty when the item goes back to its folder
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	var data RestoreItemRequest
+	if len(body) > 0 {
+		if err = json.Unmarshal(body, &data); err != nil {
+			FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+			return
+		}
+	}
+
+	var response interface{}
+	if item.ItemType == models.ItemTypeFolder {
+		var folder *models.Folder
+		if folder, err = h.folderService.RestoreFolder(item.ID, data.ParentFolderID, policy); err == nil {
+			response = newFolderResponse(folder)
+		}
+	} else {
+		var file *models.File
+		if file, err = h.fileService.RestoreFile(item.ID, data.ParentFolderID, policy); err == nil {
+			response = newFileResponse(file)
+		}
+	}
+	if err != nil {
+		log.Info().Msgf("Failed to restore trash item(%d): %s", item.ID, err.Error())
+		if errors.Is(err, models.ErrParentGone) {
+			FailedResponse(w, http.StatusConflict, "Original folder does not exist anymore, parent_folder_id is required")
+			return
+		}
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "Trash item or parent folder not found")
+			return
+		}
+		if nameErrorResponse(w, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
+		FailedResponse(w, http.StatusInternalServerError, "Failed to restore trash item")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, response)
+}
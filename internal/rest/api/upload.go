@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// extensions of the tus protocol supported by the resumable uploads
+const tusExtensions = "creation,termination,expiration"
+
+// loads the upload by the path value and checks that it belongs to the user,
+// if something is wrong - writes the failed response and returns nil
+func (h *Handler) loadUpload(w http.ResponseWriter, r *http.Request) *models.Upload {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+	uploadID := r.PathValue("upload_id")
+
+	upload, err := h.uploadService.GetUpload(uploadID)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrNotFound):
+			FailedResponse(w, http.StatusNotFound, "Upload not found")
+		case errors.Is(err, models.ErrUploadExpired):
+			FailedResponse(w, http.StatusGone, "Upload expired")
+		default:
+			log.Warn().Msgf("failed to get upload(%s): %s", uploadID, err.Error())
+			FailedResponse(w, http.StatusInternalServerError, "Failed to get upload")
+		}
+		return nil
+	}
+
+	if upload.UserID != userID {
+		FailedResponse(w, http.StatusNotFound, "Upload not found")
+		return nil
+	}
+
+	return upload
+}
+
+// saves the fully received upload as a file and updates the folder cache the same way as a single file upload
+func (h *Handler) completeUpload(upload *models.Upload) error {
+	added, err := h.uploadService.CompleteUpload(upload)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := h.rc.SetOrUpdateFolderSize(ctx, upload.FolderID, added); err != nil {
+		return fmt.Errorf("failed to save file to cache: %w", err)
+	}
+	return nil
+}
+
+// sets headers describing the upload state
+func setUploadHeaders(w http.ResponseWriter, upload *models.Upload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// parses Upload-Metadata header, which is a comma separated list of "key base64(value)" pairs
+func parseUploadMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	if strings.TrimSpace(header) == "" {
+		return metadata, nil
+	}
+
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("empty metadata key")
+		}
+
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value of metadata key %q: %w", key, err)
+		}
+		metadata[key] = string(value)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// CreateUpload starts a resumable upload of a file into the folder
+// @Summary      Create a resumable upload
+// @Description  Creates a tus upload. The file name is taken from the "filename" key of Upload-Metadata.
+// @Description  Content is sent with PATCH requests to the returned Location.
+// @Tags         upload
+// @Param        user_id          header    int     true  "User ID"
+// @Param        folder_id        path      int64   true  "Folder ID"
+// @Param        Tus-Resumable    header    string  true  "Protocol version, 1.0.0"
+// @Param        Upload-Length    header    int64   true  "Size of the whole file in bytes"
+// @Param        Upload-Metadata  header    string  true  "Comma separated key and base64 encoded value pairs"
+// @Param        on_conflict      query     string  false "Name conflict policy applied when the upload is finished, a new version of the existing file by default"  Enums(fail, rename, replace)
+// @Success      201  {object}  nil            "Upload created"
+// @Header       201  {string}  Location       "URL of the created upload"
+// @Header       201  {string}  Upload-Expires "Time when the unfinished upload expires"
+// @Failure      400  {object}  ErrorResponse  "Invalid folder_id, file name or upload headers"
+// @Failure      409  {object}  ErrorResponse  "Empty file with the same name already exists"
+// @Failure      412  {string}  string         "Unsupported protocol version"
+// @Failure      413  {object}  QuotaErrorResponse  "File is larger than the storage quota"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
+// @Router       /v1/folders/{folder_id}/uploads [post]
+func (h *Handler) CreateUpload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.createUpload(w, r)
+	})
+}
+
+func (h *Handler) createUpload(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDHeaderKey).(int)
+
+	folderID, err := strconv.ParseInt(r.PathValue("folder_id"), 10, 64)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid folder_id")
+		return
+	}
+
+	policy, err := h.uploadConflictPolicy(r)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid on_conflict")
+		return
+	}
+
+	// deferred length is not supported, the size should be known from the start
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		FailedResponse(w, http.StatusBadRequest, "Invalid Upload-Length")
+		return
+	}
+
+	rawMetadata := r.Header.Get("Upload-Metadata")
+	metadata, err := parseUploadMetadata(rawMetadata)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid Upload-Metadata")
+		return
+	}
+	name := metadata["filename"]
+	if name == "" {
+		FailedResponse(w, http.StatusBadRequest, "File name is missing in Upload-Metadata")
+		return
+	}
+
+	// the quota is checked again when the upload is finished, but a file which does not fit is not worth receiving
+	if err = h.userService.CheckQuota(userID, length); err != nil {
+		if quotaErrorResponse(w, err) {
+			return
+		}
+		log.Warn().Msgf("Failed to check quota of user(%d): %s", userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to create upload")
+		return
+	}
+
+	upload, err := h.uploadService.CreateUpload(userID, folderID, name, length, rawMetadata, policy)
+	if err != nil {
+		log.Info().Msgf("Failed to create upload in folder(%d): %s", folderID, err.Error())
+		if nameErrorResponse(w, err) {
+			return
+		}
+		if quotaErrorResponse(w, err) {
+			return
+		}
+		FailedResponse(w, http.StatusInternalServerError, "Failed to create upload")
+		return
+	}
+
+	// an empty file is received right away
+	if upload.Length == 0 {
+		if err = h.completeUpload(upload); err != nil {
+			log.Info().Msgf("Failed to complete upload(%s): %s", upload.ID, err.Error())
+			if nameErrorResponse(w, err) {
+				return
+			}
+			if quotaErrorResponse(w, err) {
+				return
+			}
+			FailedResponse(w, http.StatusInternalServerError, "Failed to complete upload")
+			return
+		}
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/v1/uploads/%s", upload.ID))
+	setUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusCreated)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DeleteUpload terminates the resumable upload
+// @Summary      Terminate a resumable upload
+// @Description  Stops the tus upload and removes all received chunks
+// @Tags         upload
+// @Param        user_id        header    int     true  "User ID"
+// @Param        upload_id      path      string  true  "Upload ID"
+// @Param        Tus-Resumable  header    string  true  "Protocol version, 1.0.0"
+// @Success      204  {object}  nil            "Upload terminated"
+// @Failure      404  {object}  ErrorResponse  "Upload not found"
+// @Failure      410  {object}  ErrorResponse  "Upload expired"
+// @Failure      412  {string}  string         "Unsupported protocol version"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/uploads/{upload_id} [delete]
+func (h *Handler) DeleteUpload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.deleteUpload(w, r)
+	})
+}
+
+func (h *Handler) deleteUpload(w http.ResponseWriter, r *http.Request) {
+	upload := h.loadUpload(w, r)
+	if upload == nil {
+		return
+	}
+
+	if err := h.uploadService.DeleteUpload(upload); err != nil {
+		log.Info().Msgf("Failed to delete upload(%s): %s", upload.ID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to delete upload")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
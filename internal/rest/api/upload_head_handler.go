@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// GetUploadOffset returns the number of received bytes of the resumable upload
+// @Summary      Get resumable upload offset
+// @Description  Returns the state of the tus upload, the client continues the upload from Upload-Offset
+// @Tags         upload
+// @Param        user_id        header    int     true  "User ID"
+// @Param        upload_id      path      string  true  "Upload ID"
+// @Param        Tus-Resumable  header    string  true  "Protocol version, 1.0.0"
+// @Success      200  {object}  nil            "Upload state"
+// @Header       200  {int}     Upload-Offset  "Number of received bytes"
+// @Header       200  {int}     Upload-Length  "Size of the whole file in bytes"
+// @Failure      404  {object}  ErrorResponse  "Upload not found"
+// @Failure      410  {object}  ErrorResponse  "Upload expired"
+// @Failure      412  {string}  string         "Unsupported protocol version"
+// @Router       /v1/uploads/{upload_id} [head]
+func (h *Handler) GetUploadOffset() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.getUploadOffset(w, r)
+	})
+}
+
+func (h *Handler) getUploadOffset(w http.ResponseWriter, r *http.Request) {
+	upload := h.loadUpload(w, r)
+	if upload == nil {
+		return
+	}
+
+	setUploadHeaders(w, upload)
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.Length, 10))
+	if upload.Metadata != "" {
+		w.Header().Set("Upload-Metadata", upload.Metadata)
+	}
+	// the offset changes with every chunk, so it should never be cached
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+}
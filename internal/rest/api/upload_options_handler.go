@@ -0,0 +1,28 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/saur4ig/file-storage/internal/rest/middleware"
+)
+
+// UploadOptions describes the resumable upload capabilities of the server
+// @Summary      Resumable upload capabilities
+// @Description  Returns supported tus protocol version and extensions
+// @Tags         upload
+// @Param        user_id   header    int     true  "User ID"
+// @Success      204  {object}  nil  "No Content"
+// @Header       204  {string}  Tus-Version    "Supported protocol versions"
+// @Header       204  {string}  Tus-Extension  "Supported protocol extensions"
+// @Router       /v1/uploads [options]
+func (h *Handler) UploadOptions() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.uploadOptions(w)
+	})
+}
+
+func (h *Handler) uploadOptions(w http.ResponseWriter) {
+	w.Header().Set("Tus-Version", middleware.TusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.WriteHeader(http.StatusNoContent)
+}
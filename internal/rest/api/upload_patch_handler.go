@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// PatchUpload receives the next chunk of the resumable upload
+// @Summary      Upload a chunk
+// @Description  Appends the request body to the tus upload at Upload-Offset.
+// @Description  When the last byte is received, the upload is saved as a file in the folder.
+// @Tags         upload
+// @Param        user_id        header    int     true  "User ID"
+// @Param        upload_id      path      string  true  "Upload ID"
+// @Param        Tus-Resumable  header    string  true  "Protocol version, 1.0.0"
+// @Param        Upload-Offset  header    int64   true  "Offset of the chunk, must be equal to the current upload offset"
+// @Accept       application/offset+octet-stream
+// @Success      204  {object}  nil            "Chunk received"
+// @Header       204  {int}     Upload-Offset  "Number of received bytes"
+// @Failure      400  {object}  ErrorResponse  "Invalid Upload-Offset"
+// @Failure      404  {object}  ErrorResponse  "Upload not found"
+// @Failure      409  {object}  ErrorResponse  "Upload-Offset does not match the upload offset"
+// @Failure      410  {object}  ErrorResponse  "Upload expired"
+// @Failure      412  {string}  string         "Unsupported protocol version"
+// @Failure      413  {object}  ErrorResponse  "Chunk exceeds the upload length or the file is larger than the storage quota"
+// @Failure      415  {object}  ErrorResponse  "Invalid Content-Type"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Failure      507  {object}  QuotaErrorResponse  "Storage quota exceeded"
+// @Router       /v1/uploads/{upload_id} [patch]
+func (h *Handler) PatchUpload() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.patchUpload(w, r)
+	})
+}
+
+func (h *Handler) patchUpload(w http.ResponseWriter, r *http.Request) {
+	upload := h.loadUpload(w, r)
+	if upload == nil {
+		return
+	}
+
+	if r.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		FailedResponse(w, http.StatusUnsupportedMediaType, "Invalid Content-Type")
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		FailedResponse(w, http.StatusBadRequest, "Invalid Upload-Offset")
+		return
+	}
+	if offset != upload.Offset {
+		FailedResponse(w, http.StatusConflict, "Upload-Offset does not match the upload offset")
+		return
+	}
+
+	remaining := upload.Length - upload.Offset
+	if r.ContentLength > remaining {
+		FailedResponse(w, http.StatusRequestEntityTooLarge, "Chunk exceeds the upload length")
+		return
+	}
+
+	// the chunk is streamed to the storage, the body is never allowed to pass the upload length
+	body := http.MaxBytesReader(w, r.Body, remaining)
+	updated, err := h.uploadService.WriteChunk(upload, offset, body, r.ContentLength)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.Is(err, models.ErrOffsetMismatch):
+			FailedResponse(w, http.StatusConflict, "Upload-Offset does not match the upload offset")
+		case errors.As(err, &maxBytesErr):
+			FailedResponse(w, http.StatusRequestEntityTooLarge, "Chunk exceeds the upload length")
+		default:
+			log.Info().Msgf("Failed to write upload(%s) chunk: %s", upload.ID, err.Error())
+			FailedResponse(w, http.StatusInternalServerError, "Failed to write chunk")
+		}
+		return
+	}
+
+	upload = updated
+
+	// the last chunk is received, a failed completion is retried with an empty PATCH
+	if upload.Offset == upload.Length {
+		if err = h.completeUpload(upload); err != nil {
+			log.Info().Msgf("Failed to complete upload(%s): %s", upload.ID, err.Error())
+			if nameErrorResponse(w, err) {
+				return
+			}
+			if quotaErrorResponse(w, err) {
+				return
+			}
+			FailedResponse(w, http.StatusInternalServerError, "Failed to complete upload")
+			return
+		}
+	}
+
+	setUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// SetUserQuota changes the storage quota of the user
+// @Summary      Set a user quota
+// @Description  Changes the storage quota of the user, null removes the limit. A quota below the current usage
+// @Description  does not delete anything, but new uploads are rejected. Available to admins only.
+// @Tags         admin
+// @Param        user_id        header    int                  true  "User ID of the admin"
+// @Param        target_id      path      int                  true  "ID of the user whose quota is changed"
+// @Param        quota          body      SetUserQuotaRequest  true  "New quota"
+// @Produce      json
+// @Success      200  {object}  QuotaResponse  "Quota successfully changed"
+// @Failure      400  {object}  ErrorResponse  "Invalid user id or request body"
+// @Failure      403  {string}  string         "Not an admin"
+// @Failure      404  {object}  ErrorResponse  "User not found"
+// @Failure      500  {object}  ErrorResponse  "Internal Server Error"
+// @Router       /v1/admin/users/{target_id}/quota [put]
+func (h *Handler) SetUserQuota() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h.setUserQuota(w, r)
+	})
+}
+
+// SetUserQuotaRequest represents the request payload to change the quota of the user
+type SetUserQuotaRequest struct {
+	// Quota is the max number of stored bytes, null removes the limit
+	Quota *int64 `json:"quota"`
+}
+
+func (h *Handler) setUserQuota(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.Atoi(r.PathValue("target_id"))
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Invalid user id")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to read request body")
+		return
+	}
+	defer r.Body.Close()
+
+	var data SetUserQuotaRequest
+	if err = json.Unmarshal(body, &data); err != nil {
+		FailedResponse(w, http.StatusBadRequest, "Failed to decode request")
+		return
+	}
+	if data.Quota != nil && *data.Quota < 0 {
+		FailedResponse(w, http.StatusBadRequest, "quota can't be negative")
+		return
+	}
+
+	quota, err := h.userService.SetUserQuota(userID, data.Quota)
+	if err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			FailedResponse(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Warn().Msgf("failed to set quota of user(%d): %s", userID, err.Error())
+		FailedResponse(w, http.StatusInternalServerError, "Failed to set quota")
+		return
+	}
+
+	SuccessfulResponse(w, http.StatusOK, newQuotaResponse(quota))
+}
@@ -2,7 +2,12 @@ package rest
 
 import (
 	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -11,19 +16,28 @@ import (
 	"net/http/httptest"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/johannesboyne/gofakes3"
+	"github.com/johannesboyne/gofakes3/backend/s3mem"
+	"github.com/lib/pq"
 	"github.com/redis/go-redis/v9"
+	"github.com/saur4ig/file-storage/internal/config"
 	"github.com/saur4ig/file-storage/internal/database"
+	"github.com/saur4ig/file-storage/internal/models"
 	"github.com/saur4ig/file-storage/internal/rest/api"
 	"github.com/saur4ig/file-storage/internal/rest/middleware"
+	si "github.com/saur4ig/file-storage/internal/services/interface"
 )
 
 var (
 	testDB      *sql.DB
 	redisClient *redis.Client
+	storageDir  string
 )
 
 func TestMain(m *testing.M) {
@@ -54,6 +68,13 @@ func TestMain(m *testing.M) {
 		Addr: "localhost:6380",
 	})
 
+	storageDir, err = os.MkdirTemp("", "file-storage-test")
+	if err != nil {
+		fmt.Printf("Error creating storage directory: %v\n", err)
+		tearDown()
+		os.Exit(1)
+	}
+
 	code := m.Run()
 
 	tearDown()
@@ -87,15 +108,32 @@ func tearDown() {
 	if redisClient != nil {
 		redisClient.Close()
 	}
+	if storageDir != "" {
+		os.RemoveAll(storageDir)
+	}
 }
 
 // helper function to set up the router with routes and middleware
 func setupTestRouter() http.Handler {
-	folderS, fileS, transactionS, s3S := initDBServices(testDB)
+	storage, err := newFileStorage(config.StorageConfig{Type: config.StorageTypeLocal, Path: storageDir})
+	if err != nil {
+		panic(err)
+	}
+	return setupTestRouterWithStorage(storage)
+}
+
+// helper function to set up the router which keeps files in the provided storage
+func setupTestRouterWithStorage(storage si.FileStorage) http.Handler {
 	rc := database.NewRedisCache(redisClient)
-	handler := api.New(folderS, fileS, transactionS, s3S, rc)
+	folderS, fileS, transactionS, uploadS, trashS, userS := initDBServices(testDB, storage, rc, config.Config{
+		Upload:      config.UploadConfig{Expiration: time.Hour},
+		Transaction: config.TransactionConfig{TTL: time.Hour, MaxTTL: 24 * time.Hour},
+		Trash:       config.TrashConfig{Retention: time.Hour},
+		Versions:    config.VersionsConfig{MaxVersions: 3},
+	})
+	handler := api.New(folderS, fileS, transactionS, uploadS, trashS, userS, storage, rc, models.ConflictFail)
 	router := http.NewServeMux()
-	withRoutes := routes(router, handler)
+	withRoutes := routes(router, handler, []int{1})
 	withMiddleware := middleware.Logging(middleware.Auth(withRoutes))
 	return withMiddleware
 }
@@ -155,10 +193,105 @@ func TestUploadSingleFile(t *testing.T) {
 
 	req := createRequestWithHeaders("POST", "/v1/folders/2/files", body)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	sum := sha256.Sum256([]byte("fake file content"))
+	req.Header.Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(sum[:]))
 
 	response := executeRequest(req, router)
 
 	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var s3URL string
+	if err := testDB.QueryRow(`SELECT s3_url FROM files WHERE id = 1`).Scan(&s3URL); err != nil {
+		t.Fatalf("Failed to get stored file key: %v", err)
+	}
+	content, err := os.ReadFile(filepath.Join(storageDir, filepath.FromSlash(s3URL)))
+	if err != nil {
+		t.Fatalf("Failed to read stored file: %v", err)
+	}
+	if string(content) != "fake file content" {
+		t.Errorf("Expected stored content to be 'fake file content'. Got '%s'", content)
+	}
+}
+
+// TestUploadFileChecksumMismatch tests that a file with unexpected content is rejected and not saved
+func TestUploadFileChecksumMismatch(t *testing.T) {
+	router := setupTestRouter()
+
+	body, writer := prepareMultipartFormData(t, "file", "corrupted.jpg", "fake file content")
+
+	req := createRequestWithHeaders("POST", "/v1/folders/2/files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	sum := md5.Sum([]byte("other file content"))
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusBadRequest, response.Code)
+
+	var count int
+	if err := testDB.QueryRow(`SELECT COUNT(*) FROM files WHERE name = 'corrupted.jpg'`).Scan(&count); err != nil {
+		t.Fatalf("Failed to count files: %v", err)
+	}
+	if count != 0 {
+		t.Errorf("Expected corrupted file not to be saved. Got %d files", count)
+	}
+}
+
+// TestGetFile tests the "get file" endpoint
+func TestGetFile(t *testing.T) {
+	router := setupTestRouter()
+
+	req := createRequestWithHeaders("GET", "/v1/folders/2/files/1", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var file api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&file); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if file.Name != "test.jpg" || file.Size != 17 {
+		t.Errorf("Expected file 'test.jpg' of 17 bytes. Got '%s' of %d bytes", file.Name, file.Size)
+	}
+	sum := sha256.Sum256([]byte("fake file content"))
+	if file.Checksum != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected checksum '%x'. Got '%s'", sum, file.Checksum)
+	}
+
+	// file is not located in the requested folder
+	req = createRequestWithHeaders("GET", "/v1/folders/1/files/1", nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+}
+
+// TestDownloadFile tests the "download file" endpoint with full, partial and conditional requests
+func TestDownloadFile(t *testing.T) {
+	router := setupTestRouter()
+
+	req := createRequestWithHeaders("GET", "/v1/folders/2/files/1/content", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	if response.Body.String() != "fake file content" {
+		t.Errorf("Expected content 'fake file content'. Got '%s'", response.Body.String())
+	}
+	if response.Header().Get("Accept-Ranges") != "bytes" {
+		t.Errorf("Expected Accept-Ranges to be 'bytes'. Got '%s'", response.Header().Get("Accept-Ranges"))
+	}
+	etag := response.Header().Get("ETag")
+
+	req = createRequestWithHeaders("GET", "/v1/folders/2/files/1/content", nil)
+	req.Header.Set("Range", "bytes=5-8")
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusPartialContent, response.Code)
+	if response.Body.String() != "file" {
+		t.Errorf("Expected content 'file'. Got '%s'", response.Body.String())
+	}
+	if response.Header().Get("Content-Range") != "bytes 5-8/17" {
+		t.Errorf("Expected Content-Range 'bytes 5-8/17'. Got '%s'", response.Header().Get("Content-Range"))
+	}
+
+	req = createRequestWithHeaders("GET", "/v1/folders/2/files/1/content", nil)
+	req.Header.Set("If-None-Match", etag)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusNotModified, response.Code)
 }
 
 // TestGetFolderInfoWithData tests the "get folder info" endpoint with one file stored
@@ -259,11 +392,1817 @@ func TestTransactionStop(t *testing.T) {
 func TestTransactionComplete(t *testing.T) {
 	router := setupTestRouter()
 
-	req := createRequestWithHeaders("PUT", "/v1/folders/1/transaction/1/complete", nil)
+	// the transaction of the stop test is failed already
+	started := startTransaction(t, router, 1)
+	req := createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/1/transaction/%d/complete", started.TransactionID), nil)
+
+	response := executeRequest(req, router)
+
+	checkResponseCode(t, http.StatusOK, response.Code)
+}
+
+// TestTransactionExpiry tests that the sweeper rolls back transactions which outlived their ttl
+func TestTransactionExpiry(t *testing.T) {
+	router := setupTestRouter()
+	ctx := context.Background()
+
+	createFolder(t, router, "expiring", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'expiring'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	startURL := fmt.Sprintf("/v1/folders/%d/transaction/start", folderID)
+	for _, ttl := range []string{"48h", "-1m", "soon"} {
+		req := createRequestWithHeaders("POST", startURL+"?ttl="+ttl, nil)
+		checkResponseCode(t, http.StatusBadRequest, executeRequest(req, router).Code)
+	}
+	req := createRequestWithHeaders("POST", startURL+"?ttl=30m", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var started api.TransactionStartResponse
+	if err := json.NewDecoder(response.Body).Decode(&started); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if left := time.Until(started.ExpiresAt); left < 29*time.Minute || left > 31*time.Minute {
+		t.Fatalf("Expected the transaction to expire in 30m, got %s", left)
+	}
+
+	body, writer := prepareMultipartFormData(t, "file", "abandoned.txt", "abandoned upload")
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files", folderID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("transaction_id", fmt.Sprint(started.TransactionID))
+	checkResponseCode(t, http.StatusCreated, executeRequest(req, router).Code)
+	var s3URL string
+	if err := testDB.QueryRow(`SELECT s3_url FROM files WHERE transaction_id = $1`, started.TransactionID).Scan(&s3URL); err != nil {
+		t.Fatalf("Failed to get uploaded file: %v", err)
+	}
+
+	storage, err := newFileStorage(config.StorageConfig{Type: config.StorageTypeLocal, Path: storageDir})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_, _, transactionService, _, _, _ := initDBServices(testDB, storage, database.NewRedisCache(redisClient), config.Config{
+		Transaction: config.TransactionConfig{TTL: time.Hour, MaxTTL: 24 * time.Hour},
+	})
+
+	// a transaction which did not expire yet is kept
+	if _, err = transactionService.ExpireTransactions(ctx); err != nil {
+		t.Fatalf("Failed to expire transactions: %v", err)
+	}
+	verifyTransactionStatus(t, started.TransactionID, models.TransactionPending)
+
+	// the upload added the file to the cached folder size
+	cacheKey := fmt.Sprintf("folder_id:%d", folderID)
+	if err = redisClient.Set(ctx, cacheKey, 16, 0).Err(); err != nil {
+		t.Fatalf("Failed to set cached folder size: %v", err)
+	}
+	_, err = testDB.Exec(`UPDATE upload_transactions SET expires_at = $2 WHERE id = $1`,
+		started.TransactionID, time.Now().UTC().Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Failed to expire transaction: %v", err)
+	}
+	expired, err := transactionService.ExpireTransactions(ctx)
+	if err != nil || expired == 0 {
+		t.Fatalf("Expected the transaction to expire, got %d (%v)", expired, err)
+	}
+	verifyTransactionStatus(t, started.TransactionID, models.TransactionFailed)
+
+	var files int
+	if err = testDB.QueryRow(`SELECT COUNT(*) FROM files WHERE transaction_id = $1`, started.TransactionID).Scan(&files); err != nil || files != 0 {
+		t.Errorf("Expected files of the transaction to be deleted, got %d (%v)", files, err)
+	}
+	if _, err = os.Stat(filepath.Join(storageDir, filepath.FromSlash(s3URL))); !os.IsNotExist(err) {
+		t.Errorf("Expected the stored file to be removed, got %v", err)
+	}
+	cached, err := redisClient.Get(ctx, cacheKey).Int64()
+	if err != nil || cached != 0 {
+		t.Errorf("Expected cached folder size 0. Got %d (%v)", cached, err)
+	}
+
+	var audit models.TransactionAudit
+	err = testDB.QueryRow(
+		`SELECT action, file_count, size FROM upload_transaction_audit WHERE transaction_id = $1`, started.TransactionID,
+	).Scan(&audit.Action, &audit.FileCount, &audit.Size)
+	if err != nil || audit.Action != models.TransactionAuditExpired || audit.FileCount != 1 || audit.Size != 16 {
+		t.Errorf("Unexpected audit record %+v (%v)", audit, err)
+	}
+
+	// the failed transaction is not rolled back again
+	if expired, err = transactionService.ExpireTransactions(ctx); err != nil || expired != 0 {
+		t.Errorf("Expected no expired transactions, got %d (%v)", expired, err)
+	}
+}
+
+// TestTransactionRollback tests that stopping a transaction deletes its files and that a repeated stop does nothing
+func TestTransactionRollback(t *testing.T) {
+	router := setupTestRouter()
+	ctx := context.Background()
+
+	createFolder(t, router, "stopped", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'stopped'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	started := startTransaction(t, router, folderID)
+
+	content := []string{"first stopped upload", "second stopped upload"}
+	var size int64
+	for i, data := range content {
+		name := fmt.Sprintf("stopped_%d.txt", i)
+		uploadInTransaction(t, router, folderID, fmt.Sprint(started.TransactionID), name, data, http.StatusCreated)
+		size += int64(len(data))
+	}
+	rows, err := testDB.Query(`SELECT s3_url FROM files WHERE transaction_id = $1`, started.TransactionID)
+	if err != nil {
+		t.Fatalf("Failed to get uploaded files: %v", err)
+	}
+	var s3URLs []string
+	for rows.Next() {
+		var s3URL string
+		if err = rows.Scan(&s3URL); err != nil {
+			t.Fatalf("Failed to scan uploaded file: %v", err)
+		}
+		s3URLs = append(s3URLs, s3URL)
+	}
+	rows.Close()
+	if len(s3URLs) != len(content) {
+		t.Fatalf("Expected %d files in the transaction, got %d", len(content), len(s3URLs))
+	}
+
+	// a file of the transaction in the trash stays there
+	uploadInTransaction(t, router, folderID, fmt.Sprint(started.TransactionID), "stopped_trashed.txt", "trashed upload", http.StatusCreated)
+	var trashedID int64
+	if err = testDB.QueryRow(`SELECT id FROM files WHERE name = 'stopped_trashed.txt'`).Scan(&trashedID); err != nil {
+		t.Fatalf("Failed to get uploaded file: %v", err)
+	}
+	req := createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/%d/files/%d", folderID, trashedID), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+
+	// the uploads added the files to the cached folder size
+	cacheKey := fmt.Sprintf("folder_id:%d", folderID)
+	if err = redisClient.Set(ctx, cacheKey, size, 0).Err(); err != nil {
+		t.Fatalf("Failed to set cached folder size: %v", err)
+	}
+
+	stopURL := fmt.Sprintf("/v1/folders/%d/transaction/%d/stop", folderID, started.TransactionID)
+	for i := 0; i < 2; i++ {
+		req := createRequestWithHeaders("PUT", stopURL, nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+		verifyTransactionStatus(t, started.TransactionID, models.TransactionFailed)
+
+		var files int
+		err = testDB.QueryRow(`SELECT COUNT(*) FROM files WHERE transaction_id = $1 AND trash_id IS NULL`, started.TransactionID).Scan(&files)
+		if err != nil || files != 0 {
+			t.Errorf("Expected files of the transaction to be deleted, got %d (%v)", files, err)
+		}
+		if _, ok := listTrash(t, router)["stopped_trashed.txt"]; !ok {
+			t.Errorf("Expected the trashed file of the transaction to stay in the trash")
+		}
+		for _, s3URL := range s3URLs {
+			if _, err = os.Stat(filepath.Join(storageDir, filepath.FromSlash(s3URL))); !os.IsNotExist(err) {
+				t.Errorf("Expected the stored file %s to be removed, got %v", s3URL, err)
+			}
+		}
+		cached, err := redisClient.Get(ctx, cacheKey).Int64()
+		if err != nil || cached != 0 {
+			t.Errorf("Expected cached folder size 0 after stop %d. Got %d (%v)", i+1, cached, err)
+		}
+		var audits, auditFiles int
+		var auditSize int64
+		err = testDB.QueryRow(
+			`SELECT COUNT(*), MAX(file_count), MAX(size) FROM upload_transaction_audit WHERE transaction_id = $1 AND action = $2`,
+			started.TransactionID, models.TransactionAuditStopped,
+		).Scan(&audits, &auditFiles, &auditSize)
+		if err != nil || audits != 1 {
+			t.Errorf("Expected a single audit record, got %d (%v)", audits, err)
+		}
+		if auditFiles != len(content) || auditSize != size {
+			t.Errorf("Expected %d files of size %d in the audit, got %d of size %d", len(content), size, auditFiles, auditSize)
+		}
+	}
+
+	req = createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/%d/transaction/%d/stop", folderID, 1<<40), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+}
+
+// TestTransactionPause tests that a paused transaction rejects uploads, doesn't expire
+// and reports the received files when it resumes
+func TestTransactionPause(t *testing.T) {
+	router := setupTestRouter()
+	ctx := context.Background()
+
+	createFolder(t, router, "paused", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'paused'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	transactionURL := func(transactionID int64, action string) string {
+		return fmt.Sprintf("/v1/folders/%d/transaction/%d/%s", folderID, transactionID, action)
+	}
+
+	started := startTransaction(t, router, folderID)
+	id := fmt.Sprint(started.TransactionID)
+	uploadInTransaction(t, router, folderID, id, "received.txt", "received before the pause", http.StatusCreated)
+
+	req := createRequestWithHeaders("PUT", transactionURL(started.TransactionID, "pause"), nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var paused api.TransactionResponse
+	if err := json.NewDecoder(response.Body).Decode(&paused); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if paused.Status != models.TransactionPaused || paused.PausedAt == nil {
+		t.Errorf("Expected a paused transaction, got %+v", paused)
+	}
+
+	// a paused transaction accepts neither files nor another pause or the completion
+	uploadInTransaction(t, router, folderID, id, "rejected.txt", "uploaded while paused", http.StatusConflict)
+	for _, action := range []string{"pause", "complete"} {
+		req = createRequestWithHeaders("PUT", transactionURL(started.TransactionID, action), nil)
+		checkResponseCode(t, http.StatusConflict, executeRequest(req, router).Code)
+	}
+
+	// the transaction was paused 10 minutes ago and its ttl ran out during the pause, so it is not rolled back
+	_, err := testDB.Exec(`
+		UPDATE upload_transactions
+		SET expires_at = NOW() - INTERVAL '1 minute', paused_at = NOW() - INTERVAL '10 minutes'
+		WHERE id = $1`, started.TransactionID)
+	if err != nil {
+		t.Fatalf("Failed to move the pause: %v", err)
+	}
+	storage, err := newFileStorage(config.StorageConfig{Type: config.StorageTypeLocal, Path: storageDir})
+	if err != nil {
+		t.Fatalf("Failed to create storage: %v", err)
+	}
+	_, _, transactionService, _, _, _ := initDBServices(testDB, storage, database.NewRedisCache(redisClient), config.Config{
+		Transaction: config.TransactionConfig{TTL: time.Hour, MaxTTL: 24 * time.Hour},
+	})
+	if _, err = transactionService.ExpireTransactions(ctx); err != nil {
+		t.Fatalf("Failed to expire transactions: %v", err)
+	}
+	verifyTransactionStatus(t, started.TransactionID, models.TransactionPaused)
+
+	req = createRequestWithHeaders("PUT", transactionURL(started.TransactionID, "resume"), nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var resumed api.TransactionDetailResponse
+	if err = json.NewDecoder(response.Body).Decode(&resumed); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if resumed.Status != models.TransactionResumed || resumed.PausedAt != nil {
+		t.Errorf("Expected a resumed transaction, got %+v", resumed.TransactionResponse)
+	}
+	if len(resumed.Files) != 1 || resumed.Files[0].Name != "received.txt" || resumed.FileCount != 1 {
+		t.Errorf("Expected the file received before the pause, got %+v", resumed.Files)
+	}
+	// the expiry moves by the 10 minutes of the pause
+	if left := time.Until(resumed.ExpiresAt); left < 8*time.Minute || left > 10*time.Minute {
+		t.Errorf("Expected the transaction to expire in 9m, got %s", left)
+	}
+
+	req = createRequestWithHeaders("PUT", transactionURL(started.TransactionID, "resume"), nil)
+	checkResponseCode(t, http.StatusConflict, executeRequest(req, router).Code)
+	uploadInTransaction(t, router, folderID, id, "resumed.txt", "uploaded after the resume", http.StatusCreated)
+	verifyTransactionStatus(t, started.TransactionID, models.TransactionResumed)
+
+	// a resumed transaction can be completed like a pending one
+	other := startTransaction(t, router, folderID)
+	for _, action := range []string{"pause", "resume", "complete"} {
+		req = createRequestWithHeaders("PUT", transactionURL(other.TransactionID, action), nil)
+		checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	}
+	verifyTransactionStatus(t, other.TransactionID, models.TransactionCompleted)
+
+	// a resumed transaction can be paused again and a paused one can be stopped
+	req = createRequestWithHeaders("PUT", transactionURL(started.TransactionID, "pause"), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("PUT", transactionURL(started.TransactionID, "stop"), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	verifyTransactionStatus(t, started.TransactionID, models.TransactionFailed)
+	var files int
+	if err = testDB.QueryRow(`SELECT COUNT(*) FROM files WHERE transaction_id = $1`, started.TransactionID).Scan(&files); err != nil || files != 0 {
+		t.Errorf("Expected files of the stopped transaction to be deleted, got %d (%v)", files, err)
+	}
+}
+
+// TestTransactionReplace tests that an upload in a transaction can't replace an existing file,
+// so stopping the transaction leaves the file as it was
+func TestTransactionReplace(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "replaced", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'replaced'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	content := "content before the transaction"
+	file := uploadFileContent(t, router, folderID, "replaced.txt", content, "")
+
+	started := startTransaction(t, router, folderID)
+	body, writer := prepareMultipartFormData(t, "file", "replaced.txt", "content of the transaction")
+	req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files?on_conflict=replace", folderID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("transaction_id", fmt.Sprint(started.TransactionID))
+	checkResponseCode(t, http.StatusConflict, executeRequest(req, router).Code)
+
+	req = createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/%d/transaction/%d/stop", folderID, started.TransactionID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	verifyTransactionStatus(t, started.TransactionID, models.TransactionFailed)
+
+	verifyFileVersions(t, router, folderID, file.ID, 1)
+	var size int64
+	if err := testDB.QueryRow(`SELECT size + versions_size FROM files WHERE id = $1`, file.ID).Scan(&size); err != nil {
+		t.Fatalf("Failed to get file size: %v", err)
+	}
+	if size != int64(len(content)) {
+		t.Errorf("Expected file size %d. Got %d", len(content), size)
+	}
+	verifyFolderSizeValue(t, folderID, int64(len(content)))
+}
+
+// TestTransactionFileSizes tests that deleting or moving files of an unfinished transaction
+// doesn't change folder sizes, the files are counted when the transaction completes
+func TestTransactionFileSizes(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "unfinished", 1)
+	createFolder(t, router, "unfinished_target", 1)
+	var folderID, targetID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'unfinished'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'unfinished_target'`).Scan(&targetID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	started := startTransaction(t, router, folderID)
+	id := fmt.Sprint(started.TransactionID)
+	for _, name := range []string{"deleted.txt", "moved.txt", "kept.txt"} {
+		uploadInTransaction(t, router, folderID, id, name, "unfinished upload", http.StatusCreated)
+	}
+	fileID := func(name string) int64 {
+		var fileID int64
+		if err := testDB.QueryRow(`SELECT id FROM files WHERE transaction_id = $1 AND name = $2`, started.TransactionID, name).Scan(&fileID); err != nil {
+			t.Fatalf("Failed to get uploaded file: %v", err)
+		}
+		return fileID
+	}
+
+	req := createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/%d/files/%d", folderID, fileID("deleted.txt")), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+	moveItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d/move", folderID, fileID("moved.txt")), targetID, http.StatusOK)
+	verifyFolderSizeValue(t, folderID, 0)
+	verifyFolderSizeValue(t, targetID, 0)
+
+	req = createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/%d/transaction/%d/complete", folderID, started.TransactionID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	size := int64(len("unfinished upload"))
+	verifyFolderSizeValue(t, folderID, size)
+	verifyFolderSizeValue(t, targetID, size)
+}
+
+// TestTransactionInspection tests listing transactions and getting one with its files
+func TestTransactionInspection(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "inspected", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'inspected'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	pending := startTransaction(t, router, folderID)
+	content := map[string]string{"first.txt": "first inspected upload", "second.txt": "second inspected upload"}
+	for _, name := range []string{"first.txt", "second.txt"} {
+		uploadInTransaction(t, router, folderID, fmt.Sprint(pending.TransactionID), name, content[name], http.StatusCreated)
+	}
+	totalSize := int64(len(content["first.txt"]) + len(content["second.txt"]))
+
+	failed := startTransaction(t, router, folderID)
+	req := createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/%d/transaction/%d/stop", folderID, failed.TransactionID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+
+	// the newest first
+	transactions := listTransactions(t, router, fmt.Sprintf("folder_id=%d", folderID), http.StatusOK)
+	if len(transactions) != 2 || transactions[0].ID != failed.TransactionID || transactions[1].ID != pending.TransactionID {
+		t.Fatalf("Expected transactions %d and %d, got %+v", failed.TransactionID, pending.TransactionID, transactions)
+	}
+	if transactions[0].Status != models.TransactionFailed || transactions[0].FileCount != 0 {
+		t.Errorf("Unexpected stopped transaction %+v", transactions[0])
+	}
+
+	transactions = listTransactions(t, router, fmt.Sprintf("folder_id=%d&status=pending", folderID), http.StatusOK)
+	if len(transactions) != 1 || transactions[0].ID != pending.TransactionID {
+		t.Fatalf("Expected the pending transaction %d, got %+v", pending.TransactionID, transactions)
+	}
+	if transactions[0].FileCount != 2 || transactions[0].TotalSize != totalSize || transactions[0].FolderID != int64(folderID) {
+		t.Errorf("Expected 2 files of %d bytes, got %+v", totalSize, transactions[0])
+	}
+	for _, query := range []string{"status=unknown", "folder_id=inspected", "folder_id=-1"} {
+		listTransactions(t, router, query, http.StatusBadRequest)
+	}
+
+	req = createRequestWithHeaders("GET", fmt.Sprintf("/v1/transactions/%d", pending.TransactionID), nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var detail api.TransactionDetailResponse
+	if err := json.NewDecoder(response.Body).Decode(&detail); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if detail.Status != models.TransactionPending || detail.FileCount != 2 || detail.TotalSize != totalSize {
+		t.Errorf("Unexpected transaction %+v", detail.TransactionResponse)
+	}
+	if len(detail.Files) != 2 || detail.Files[0].Name != "first.txt" || detail.Files[1].Name != "second.txt" {
+		t.Errorf("Expected the uploaded files in the upload order, got %+v", detail.Files)
+	}
+
+	// transactions of other users are not visible
+	req = createRequestWithHeaders("GET", fmt.Sprintf("/v1/transactions/%d", pending.TransactionID), nil)
+	req.Header.Set("user_id", "2")
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("GET", "/v1/transactions?folder_id="+fmt.Sprint(folderID), nil)
+	req.Header.Set("user_id", "2")
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	if body := strings.TrimSpace(response.Body.String()); body != "[]" {
+		t.Errorf("Expected no transactions of another user, got %s", body)
+	}
+	req = createRequestWithHeaders("GET", fmt.Sprintf("/v1/transactions/%d", 1<<40), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+}
+
+// TestTransactionStates tests that transactions move only between allowed statuses
+// and are available only in the folder of their user
+func TestTransactionStates(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "transitions", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'transitions'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	folderSize := func() int64 {
+		var size int64
+		if err := testDB.QueryRow(`SELECT size FROM folders WHERE id = $1`, folderID).Scan(&size); err != nil {
+			t.Fatalf("Failed to get folder size: %v", err)
+		}
+		return size
+	}
+	transactionURL := func(folderID int, transactionID int64, action string) string {
+		return fmt.Sprintf("/v1/folders/%d/transaction/%d/%s", folderID, transactionID, action)
+	}
+
+	completed := startTransaction(t, router, folderID)
+	id := fmt.Sprint(completed.TransactionID)
+	uploadInTransaction(t, router, folderID, id, "first.txt", "first upload", http.StatusCreated)
+	uploadInTransaction(t, router, folderID, "first", "invalid.txt", "invalid upload", http.StatusBadRequest)
+	uploadInTransaction(t, router, folderID, "123456789", "missing.txt", "missing upload", http.StatusNotFound)
+	uploadInTransaction(t, router, 1, id, "other.txt", "upload to another folder", http.StatusNotFound)
+	if size := folderSize(); size != 0 {
+		t.Errorf("Expected files of the pending transaction not to be counted, got folder size %d", size)
+	}
+
+	// the transaction belongs to the user and the folder it was started for
+	req := createRequestWithHeaders("PUT", transactionURL(1, completed.TransactionID, "complete"), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("PUT", transactionURL(folderID, completed.TransactionID, "stop"), nil)
+	req.Header.Set("user_id", "2")
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+	verifyTransactionStatus(t, completed.TransactionID, models.TransactionPending)
+
+	req = createRequestWithHeaders("PUT", transactionURL(folderID, completed.TransactionID, "complete"), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	verifyTransactionStatus(t, completed.TransactionID, models.TransactionCompleted)
+	if size := folderSize(); size != int64(len("first upload")) {
+		t.Errorf("Expected the completed file to be counted, got folder size %d", size)
+	}
+
+	// a completed transaction is final, its files are counted once
+	for _, action := range []string{"complete", "stop"} {
+		req = createRequestWithHeaders("PUT", transactionURL(folderID, completed.TransactionID, action), nil)
+		checkResponseCode(t, http.StatusConflict, executeRequest(req, router).Code)
+	}
+	uploadInTransaction(t, router, folderID, id, "late.txt", "late upload", http.StatusConflict)
+	verifyTransactionStatus(t, completed.TransactionID, models.TransactionCompleted)
+	if size := folderSize(); size != int64(len("first upload")) {
+		t.Errorf("Expected folder size %d, got %d", len("first upload"), size)
+	}
+
+	failed := startTransaction(t, router, folderID)
+	req = createRequestWithHeaders("PUT", transactionURL(folderID, failed.TransactionID, "stop"), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("PUT", transactionURL(folderID, failed.TransactionID, "complete"), nil)
+	checkResponseCode(t, http.StatusConflict, executeRequest(req, router).Code)
+	uploadInTransaction(t, router, folderID, fmt.Sprint(failed.TransactionID), "failed.txt", "failed upload", http.StatusConflict)
+	verifyTransactionStatus(t, failed.TransactionID, models.TransactionFailed)
+}
+
+// TestUploadFileToS3 tests the "upload file" endpoint with the s3 storage, backed by an in-process fake s3 server
+func TestUploadFileToS3(t *testing.T) {
+	const bucket = "files"
+
+	backend := s3mem.New()
+	s3Server := httptest.NewServer(gofakes3.New(backend).Server())
+	defer s3Server.Close()
+
+	storage, err := newFileStorage(config.StorageConfig{
+		Type: config.StorageTypeS3,
+		S3: config.S3Config{
+			Bucket:    bucket,
+			Region:    "us-east-1",
+			Endpoint:  strings.TrimPrefix(s3Server.URL, "http://"),
+			AccessKey: "test",
+			SecretKey: "test",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create s3 storage: %v", err)
+	}
+	router := setupTestRouterWithStorage(storage)
+
+	body, writer := prepareMultipartFormData(t, "file", "s3.txt", "s3 file content")
+
+	req := createRequestWithHeaders("POST", "/v1/folders/1/files", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	response := executeRequest(req, router)
+
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var s3URL string
+	if err := testDB.QueryRow(`SELECT s3_url FROM files WHERE name = 's3.txt'`).Scan(&s3URL); err != nil {
+		t.Fatalf("Failed to get stored file key: %v", err)
+	}
+	object, err := backend.GetObject(bucket, s3URL, nil)
+	if err != nil {
+		t.Fatalf("Failed to get stored object: %v", err)
+	}
+	defer object.Contents.Close()
+
+	content, err := io.ReadAll(object.Contents)
+	if err != nil {
+		t.Fatalf("Failed to read stored object: %v", err)
+	}
+	if string(content) != "s3 file content" {
+		t.Errorf("Expected stored content to be 's3 file content'. Got '%s'", content)
+	}
+}
 
+// TestResumableUpload tests the resumable upload endpoints: creation, chunks, offset and completion
+func TestResumableUpload(t *testing.T) {
+	router := setupTestRouter()
+
+	req := createRequestWithHeaders("OPTIONS", "/v1/uploads", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusNoContent, response.Code)
+	if response.Header().Get("Tus-Version") != "1.0.0" {
+		t.Errorf("Expected Tus-Version '1.0.0'. Got '%s'", response.Header().Get("Tus-Version"))
+	}
+
+	location := createUpload(t, router, 1, "resumable.txt", 11)
+
+	// protocol version is required
+	req = createRequestWithHeaders("HEAD", location, nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusPreconditionFailed, response.Code)
+
+	patchUpload(t, router, location, 0, "hello ", http.StatusNoContent)
+	if offset := getUploadOffset(t, router, location); offset != "6" {
+		t.Errorf("Expected Upload-Offset '6'. Got '%s'", offset)
+	}
+
+	// chunk for an already received offset is rejected
+	patchUpload(t, router, location, 0, "hello ", http.StatusConflict)
+
+	patchUpload(t, router, location, 6, "world", http.StatusNoContent)
+
+	var size int64
+	if err := testDB.QueryRow(`SELECT size FROM files WHERE name = 'resumable.txt' AND folder_id = 1`).Scan(&size); err != nil {
+		t.Fatalf("Failed to get uploaded file: %v", err)
+	}
+	if size != 11 {
+		t.Errorf("Expected uploaded file size 11. Got %d", size)
+	}
+
+	// finished upload is gone
+	req = createRequestWithHeaders("HEAD", location, nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+}
+
+// TestTerminateResumableUpload tests the termination of the resumable upload
+func TestTerminateResumableUpload(t *testing.T) {
+	router := setupTestRouter()
+
+	location := createUpload(t, router, 1, "terminated.txt", 100)
+	patchUpload(t, router, location, 0, "some bytes", http.StatusNoContent)
+
+	req := createRequestWithHeaders("DELETE", location, nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
 	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusNoContent, response.Code)
+
+	req = createRequestWithHeaders("HEAD", location, nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusNotFound, response.Code)
+}
+
+// TestDeduplicatedFiles tests that files with the same content share one stored object,
+// which is removed with the last file referencing it
+func TestDeduplicatedFiles(t *testing.T) {
+	router := setupTestRouter()
+
+	for _, name := range []string{"copy1.txt", "copy2.txt"} {
+		body, writer := prepareMultipartFormData(t, "file", name, "duplicated content")
+		req := createRequestWithHeaders("POST", "/v1/folders/1/files", body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		response := executeRequest(req, router)
+		checkResponseCode(t, http.StatusCreated, response.Code)
+	}
+
+	var ids []int64
+	var keys []string
+	rows, err := testDB.Query(`SELECT id, s3_url FROM files WHERE name IN ('copy1.txt', 'copy2.txt') ORDER BY id`)
+	if err != nil {
+		t.Fatalf("Failed to get uploaded files: %v", err)
+	}
+	for rows.Next() {
+		var id int64
+		var key string
+		if err := rows.Scan(&id, &key); err != nil {
+			t.Fatalf("Failed to scan uploaded file: %v", err)
+		}
+		ids = append(ids, id)
+		keys = append(keys, key)
+	}
+	rows.Close()
+	if len(ids) != 2 || keys[0] != keys[1] {
+		t.Fatalf("Expected 2 files sharing one object. Got %v", keys)
+	}
 
+	// statistics cover files of all users, so only admins see them
+	req := createRequestWithHeaders("GET", "/v1/storage/stats", nil)
+	req.Header.Set("user_id", "2")
+	checkResponseCode(t, http.StatusForbidden, executeRequest(req, router).Code)
+	objectPath := filepath.Join(storageDir, filepath.FromSlash(keys[0]))
+
+	req = createRequestWithHeaders("GET", "/v1/storage/stats", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var stats api.StorageStatsResponse
+	if err := json.NewDecoder(response.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if stats.LogicalSize-stats.PhysicalSize < int64(len("duplicated content")) {
+		t.Errorf("Expected physical size to be smaller than logical. Got %d and %d", stats.PhysicalSize, stats.LogicalSize)
+	}
+
+	// the object is still referenced by the second file
+	req = createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/1/files/%d", ids[0]), nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusNoContent, response.Code)
+	if _, err := os.Stat(objectPath); err != nil {
+		t.Errorf("Expected shared object to be kept: %v", err)
+	}
+
+	req = createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/1/files/%d", ids[1]), nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusNoContent, response.Code)
+	if _, err := os.Stat(objectPath); err != nil {
+		t.Errorf("Expected object to be kept while files are in the trash: %v", err)
+	}
+
+	// the object is removed when the last file is deleted permanently
+	for _, id := range ids {
+		var trashID int64
+		if err := testDB.QueryRow(`SELECT id FROM trash WHERE item_type = 'file' AND item_id = $1`, id).Scan(&trashID); err != nil {
+			t.Fatalf("Failed to get trash item: %v", err)
+		}
+		req = createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/trash/%d", trashID), nil)
+		checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+	}
+	if _, err := os.Stat(objectPath); !os.IsNotExist(err) {
+		t.Errorf("Expected object to be removed with the last file. Got %v", err)
+	}
+}
+
+// TestListFolder tests the "list folder" endpoint with pagination, sorting and type filter
+func TestListFolder(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "listing", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'listing'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	createFolder(t, router, "b", folderID)
+	createFolder(t, router, "a", folderID)
+	for name, content := range map[string]string{"small.txt": "small", "big.txt": "much bigger content"} {
+		body, writer := prepareMultipartFormData(t, "file", name, content)
+		req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files", folderID), body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		checkResponseCode(t, http.StatusCreated, executeRequest(req, router).Code)
+	}
+
+	// folders are listed first, the page is filled with them
+	page := listFolder(t, router, folderID, "limit=2")
+	verifyListItems(t, page, "a", "b")
+	if page.Folder.FileCount == nil || *page.Folder.FileCount != 2 || *page.Folder.FolderCount != 2 {
+		t.Errorf("Expected folder with 2 files and 2 folders. Got %+v", page.Folder)
+	}
+
+	page = listFolder(t, router, folderID, "limit=2&cursor="+page.NextCursor)
+	verifyListItems(t, page, "big.txt", "small.txt")
+	if page.NextCursor != "" {
+		t.Errorf("Expected the last page. Got cursor %s", page.NextCursor)
+	}
+
+	page = listFolder(t, router, folderID, "type=file&sort=size&order=desc")
+	verifyListItems(t, page, "big.txt", "small.txt")
+
+	// cursor can't be used with another sort
+	page = listFolder(t, router, folderID, "limit=1")
+	req := createRequestWithHeaders("GET", fmt.Sprintf("/v1/folders/%d/children?sort=size&cursor=%s", folderID, page.NextCursor), nil)
+	checkResponseCode(t, http.StatusBadRequest, executeRequest(req, router).Code)
+}
+
+// TestRenameAndNameConflicts tests the rename endpoints and the name conflict policies
+func TestRenameAndNameConflicts(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "naming", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'naming'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	report := uploadNamedFile(t, router, folderID, "report.txt", "", http.StatusCreated)
+	notes := uploadNamedFile(t, router, folderID, "notes.txt", "", http.StatusCreated)
+
+	// the name is taken, the fail policy rejects the upload
+	uploadNamedFile(t, router, folderID, "report.txt", "on_conflict=fail", http.StatusConflict)
+	copied := uploadNamedFile(t, router, folderID, "report.txt", "on_conflict=rename", http.StatusCreated)
+	if copied.Name != "report (1).txt" {
+		t.Errorf("Expected file to be renamed to 'report (1).txt'. Got '%s'", copied.Name)
+	}
+
+	renameItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d", folderID, notes.ID), "report.txt", http.StatusConflict)
+	renameItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d", folderID, notes.ID), "a/b.txt", http.StatusBadRequest)
+	response := renameItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d?on_conflict=replace", folderID, notes.ID), "report.txt", http.StatusOK)
+	var renamed api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&renamed); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if renamed.ID != notes.ID || renamed.Name != "report.txt" {
+		t.Errorf("Expected file %d to be renamed to 'report.txt'. Got %+v", notes.ID, renamed)
+	}
+
+	// the replaced file is deleted
+	req := createRequestWithHeaders("GET", fmt.Sprintf("/v1/folders/%d/files/%d", folderID, report.ID), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+
+	createFolder(t, router, "draft", folderID)
+	createFolder(t, router, "final", folderID)
+	var draftID int64
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'draft'`).Scan(&draftID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	renameItem(t, router, fmt.Sprintf("/v1/folders/%d", draftID), "final", http.StatusConflict)
+	response = renameItem(t, router, fmt.Sprintf("/v1/folders/%d?on_conflict=rename", draftID), "final", http.StatusOK)
+	var folder api.FolderResponse
+	if err := json.NewDecoder(response.Body).Decode(&folder); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if folder.ID != draftID || folder.Name != "final (1)" {
+		t.Errorf("Expected folder %d to be renamed to 'final (1)'. Got %+v", draftID, folder)
+	}
+
+	page := listFolder(t, router, folderID, "")
+	verifyListItems(t, page, "final", "final (1)", "report (1).txt", "report.txt")
+}
+
+// TestCopyFolderAndFile tests the "copy folder" and "copy file" endpoints
+func TestCopyFolderAndFile(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "originals", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'originals'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	createFolder(t, router, "nested", folderID)
+	var nestedID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'nested'`).Scan(&nestedID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	photo := uploadNamedFile(t, router, nestedID, "photo.jpg", "", http.StatusCreated)
+	statsBefore := getStorageStats(t, router)
+
+	// a folder can't be copied into itself
+	copyItem(t, router, fmt.Sprintf("/v1/folders/%d/copy", folderID), nestedID, http.StatusBadRequest)
+	copyItem(t, router, fmt.Sprintf("/v1/folders/%d/copy", folderID), 1, http.StatusConflict)
+
+	response := copyItem(t, router, fmt.Sprintf("/v1/folders/%d/copy?on_conflict=rename", folderID), 1, http.StatusCreated)
+	var folder api.FolderResponse
+	if err := json.NewDecoder(response.Body).Decode(&folder); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if folder.Name != "originals (1)" || folder.Size != photo.Size {
+		t.Errorf("Expected folder 'originals (1)' of size %d. Got %+v", photo.Size, folder)
+	}
+
+	page := listFolder(t, router, int(folder.ID), "")
+	verifyListItems(t, page, "nested")
+	page = listFolder(t, router, int(page.Items[0].ID), "")
+	verifyListItems(t, page, "photo.jpg")
+
+	// the copied file shares the stored content
+	statsAfter := getStorageStats(t, router)
+	if statsAfter.Files != statsBefore.Files+1 || statsAfter.Objects != statsBefore.Objects {
+		t.Errorf("Expected one more file sharing the object. Got %+v, before %+v", statsAfter, statsBefore)
+	}
+
+	response = copyItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d/copy", nestedID, photo.ID), int(folder.ID), http.StatusCreated)
+	var file api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&file); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if file.FolderID != folder.ID || file.Name != "photo.jpg" || file.Checksum != photo.Checksum {
+		t.Errorf("Expected copy of %+v in folder %d. Got %+v", photo, folder.ID, file)
+	}
+	copyItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d/copy", nestedID, photo.ID), nestedID, http.StatusConflict)
+}
+
+// TestMoveRestrictions tests that items are not moved into folders of another user and the root folder is not moved
+func TestMoveRestrictions(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "owned", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'owned'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	file := uploadNamedFile(t, router, folderID, "owned.txt", "", http.StatusCreated)
+
+	var otherUserID, otherRootID int
+	err := testDB.QueryRow(`INSERT INTO users (username, email) VALUES ('other_user', 'other_user@example.com') RETURNING id`).Scan(&otherUserID)
+	if err != nil {
+		t.Fatalf("Failed to create user: %v", err)
+	}
+	err = testDB.QueryRow(`
+		INSERT INTO folders (user_id, name, parent_folder_id, path) VALUES ($1, '/', NULL, '{}') RETURNING id`, otherUserID,
+	).Scan(&otherRootID)
+	if err != nil {
+		t.Fatalf("Failed to create root folder: %v", err)
+	}
+	if _, err = testDB.Exec(`UPDATE folders SET path = ARRAY[id] WHERE id = $1`, otherRootID); err != nil {
+		t.Fatalf("Failed to set root folder path: %v", err)
+	}
+
+	moveItem(t, router, fmt.Sprintf("/v1/folders/%d/move", folderID), otherRootID, http.StatusNotFound)
+	moveItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d/move", folderID, file.ID), otherRootID, http.StatusNotFound)
+	var files int
+	if err = testDB.QueryRow(`SELECT COUNT(*) FROM files WHERE folder_id = $1`, otherRootID).Scan(&files); err != nil || files != 0 {
+		t.Errorf("Expected nothing moved into the folder of another user, got %d files (%v)", files, err)
+	}
+	verifyFolderPathByID(t, int64(folderID), []int64{1, int64(folderID)})
+
+	moveItem(t, router, "/v1/folders/1/move", folderID, http.StatusBadRequest)
+}
+
+// TestTrash tests deleting into the trash, restoring and permanent deleting of folders and files
+func TestTrash(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "bin", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'bin'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	createFolder(t, router, "docs", folderID)
+	var docsID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'docs'`).Scan(&docsID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	letter := uploadNamedFile(t, router, docsID, "letter.txt", "", http.StatusCreated)
+	note := uploadNamedFile(t, router, folderID, "note.txt", "", http.StatusCreated)
+
+	// the root folder can't be deleted
+	checkResponseCode(t, http.StatusBadRequest, executeRequest(createRequestWithHeaders("DELETE", "/v1/folders/1", nil), router).Code)
+
+	req := createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/%d", docsID), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/%d/files/%d", folderID, note.ID), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+
+	page := listFolder(t, router, folderID, "")
+	verifyListItems(t, page)
+	if page.Folder.Size != 0 {
+		t.Errorf("Expected empty folder. Got size %d", page.Folder.Size)
+	}
+	req = createRequestWithHeaders("GET", fmt.Sprintf("/v1/folders/%d/files/%d", docsID, letter.ID), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+
+	items := listTrash(t, router)
+	docs, ok := items["docs"]
+	if !ok || docs.Type != "folder" || docs.Size != letter.Size || docs.ParentFolderID != int64(folderID) {
+		t.Fatalf("Expected folder 'docs' of size %d in the trash. Got %+v", letter.Size, items)
+	}
+	if !docs.ExpiresAt.After(docs.DeletedAt) {
+		t.Errorf("Expected expiration after deletion. Got %+v", docs)
+	}
+	if _, ok = items["letter.txt"]; ok {
+		t.Errorf("Expected content of the deleted folder not to be listed. Got %+v", items)
+	}
+
+	// the folder is restored with its content into the original folder
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/trash/%d/restore", docs.ID), nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var folder api.FolderResponse
+	if err := json.NewDecoder(response.Body).Decode(&folder); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if folder.ID != int64(docsID) || folder.Size != letter.Size {
+		t.Errorf("Expected restored folder %d of size %d. Got %+v", docsID, letter.Size, folder)
+	}
+	verifyListItems(t, listFolder(t, router, docsID, ""), "letter.txt")
+	if page = listFolder(t, router, folderID, ""); page.Folder.Size != letter.Size {
+		t.Errorf("Expected folder size %d. Got %d", letter.Size, page.Folder.Size)
+	}
+
+	// the original folder of the file is gone, so another one has to be chosen
+	req = createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/%d", folderID), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+	noteItem := listTrash(t, router)["note.txt"]
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/trash/%d/restore", noteItem.ID), nil)
+	checkResponseCode(t, http.StatusConflict, executeRequest(req, router).Code)
+
+	data, _ := json.Marshal(api.RestoreItemRequest{ParentFolderID: 1})
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/trash/%d/restore", noteItem.ID), bytes.NewBuffer(data))
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var file api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&file); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if file.ID != note.ID || file.FolderID != 1 {
+		t.Errorf("Expected file %d restored into the root folder. Got %+v", note.ID, file)
+	}
+
+	// permanently deleted items can't be restored
+	binItem := listTrash(t, router)["bin"]
+	req = createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/trash/%d", binItem.ID), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/trash/%d/restore", binItem.ID), nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+	var count int
+	if err := testDB.QueryRow(`SELECT COUNT(*) FROM folders WHERE id IN ($1, $2)`, folderID, docsID).Scan(&count); err != nil || count != 0 {
+		t.Errorf("Expected folders to be deleted. Got %d, %v", count, err)
+	}
+}
+
+// TestUploadAddsVersion tests that an upload over an existing file adds a version without on_conflict,
+// though the default policy of the test setup fails on taken names
+func TestUploadAddsVersion(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "reuploaded", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'reuploaded'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	first := uploadFileContent(t, router, folderID, "doc.txt", "first", "")
+	second := uploadFileContent(t, router, folderID, "doc.txt", "second!", "")
+	if second.ID != first.ID || second.Version != 2 {
+		t.Errorf("Expected upload to add version 2 of file %d. Got %+v", first.ID, second)
+	}
+
+	var files, versions int
+	if err := testDB.QueryRow(`SELECT COUNT(*) FROM files WHERE folder_id = $1`, folderID).Scan(&files); err != nil || files != 1 {
+		t.Errorf("Expected a single file in the folder, got %d (%v)", files, err)
+	}
+	if err := testDB.QueryRow(`SELECT COUNT(*) FROM file_versions WHERE file_id = $1`, first.ID).Scan(&versions); err != nil || versions != 1 {
+		t.Errorf("Expected a single previous version, got %d (%v)", versions, err)
+	}
+	verifyFileVersions(t, router, folderID, first.ID, 2, 1)
+	verifyFolderSizeValue(t, folderID, int64(len("first")+len("second!")))
+}
+
+// TestFileVersions tests version history of files replaced by uploads, the test setup keeps 3 versions
+func TestFileVersions(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "versioned", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'versioned'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	contents := []string{"first", "second!", "third!!!", "fourth!!!!"}
+	var file api.FileResponse
+	for i, content := range contents {
+		uploaded := uploadFileContent(t, router, folderID, "doc.txt", content, "on_conflict=replace")
+		if i > 0 && uploaded.ID != file.ID {
+			t.Errorf("Expected upload to add a version of file %d. Got %+v", file.ID, uploaded)
+		}
+		file = uploaded
+	}
+	if file.Version != 4 || file.VersionsSize != int64(len(contents[1])+len(contents[2])) {
+		t.Errorf("Expected version 4 with 2 previous versions kept. Got %+v", file)
+	}
+
+	// the oldest version is dropped, the folder size counts all kept ones
+	verifyFileVersions(t, router, folderID, file.ID, 4, 3, 2)
+	verifyFolderSizeValue(t, folderID, int64(len(contents[1])+len(contents[2])+len(contents[3])))
+
+	url := fmt.Sprintf("/v1/folders/%d/files/%d/versions", folderID, file.ID)
+	req := createRequestWithHeaders("GET", url+"/2/content", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	if response.Body.String() != contents[1] {
+		t.Errorf("Expected content of version 2 '%s'. Got '%s'", contents[1], response.Body.String())
+	}
+	req = createRequestWithHeaders("GET", url+"/1/content", nil)
+	checkResponseCode(t, http.StatusNotFound, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("GET", url+"/first/content", nil)
+	checkResponseCode(t, http.StatusBadRequest, executeRequest(req, router).Code)
+
+	// restoring adds the old content as a new version
+	req = createRequestWithHeaders("POST", url+"/2/restore", nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var restored api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&restored); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if restored.ID != file.ID || restored.Version != 5 || restored.Size != int64(len(contents[1])) {
+		t.Errorf("Expected version 5 of file %d with content of version 2. Got %+v", file.ID, restored)
+	}
+	verifyFileVersions(t, router, folderID, file.ID, 5, 4, 3)
+	verifyFolderSizeValue(t, folderID, int64(len(contents[1])+len(contents[2])+len(contents[3])))
+
+	setFolderMaxVersions(t, router, folderID, map[string]interface{}{"max_versions": 0}, http.StatusBadRequest)
+	setFolderMaxVersions(t, router, folderID, map[string]interface{}{"max_versions": 1}, http.StatusNoContent)
+	file = uploadFileContent(t, router, folderID, "doc.txt", "fifth", "on_conflict=replace")
+	verifyFileVersions(t, router, folderID, file.ID, 6)
+	verifyFolderSizeValue(t, folderID, int64(len("fifth")))
+
+	// back to the default limit
+	setFolderMaxVersions(t, router, folderID, map[string]interface{}{"max_versions": nil}, http.StatusNoContent)
+	file = uploadFileContent(t, router, folderID, "doc.txt", "sixth", "on_conflict=replace")
+	verifyFileVersions(t, router, folderID, file.ID, 7, 6)
+}
+
+// TestQuota tests the storage quota of the user and its enforcement on uploads
+func TestQuota(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "limited", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'limited'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	uploadFileContent(t, router, folderID, "base.txt", strings.Repeat("b", 30), "")
+
+	quota := getQuota(t, router)
+	var rootSize int64
+	if err := testDB.QueryRow(`SELECT size FROM folders WHERE id = 1`).Scan(&rootSize); err != nil {
+		t.Fatalf("Failed to get root folder size: %v", err)
+	}
+	if quota.Quota != nil || quota.Available != nil || quota.Used != rootSize {
+		t.Errorf("Expected unlimited quota with %d bytes used. Got %+v", rootSize, quota)
+	}
+
+	// only admins change quotas
+	req := createRequestWithHeaders("PUT", "/v1/admin/users/1/quota", strings.NewReader(`{"quota": 100}`))
+	req.Header.Set("user_id", "2")
+	checkResponseCode(t, http.StatusForbidden, executeRequest(req, router).Code)
+	setUserQuota(t, router, 1, `{"quota": -1}`, http.StatusBadRequest)
+	setUserQuota(t, router, 1000, `{"quota": 100}`, http.StatusNotFound)
+	quota = setUserQuota(t, router, 1, fmt.Sprintf(`{"quota": %d}`, rootSize+10), http.StatusOK)
+	if quota.Available == nil || *quota.Available != 10 {
+		t.Errorf("Expected 10 bytes available. Got %+v", quota)
+	}
+
+	// a file which does not fit into the free space is rejected
+	body, writer := prepareMultipartFormData(t, "file", "big.txt", strings.Repeat("x", 20))
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files", folderID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusInsufficientStorage, response.Code)
+	var quotaErr api.QuotaErrorResponse
+	if err := json.NewDecoder(response.Body).Decode(&quotaErr); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if quotaErr.Error == "" || quotaErr.Requested != 20 || quotaErr.Used != rootSize {
+		t.Errorf("Expected quota error for 20 requested bytes. Got %+v", quotaErr)
+	}
+
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/uploads", folderID), nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", fmt.Sprintf("%d", rootSize+11))
+	req.Header.Set("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte("huge.txt")))
+	checkResponseCode(t, http.StatusRequestEntityTooLarge, executeRequest(req, router).Code)
+
+	// files uploaded in a transaction take the space before it completes
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/transaction/start", folderID), nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var transaction api.TransactionStartResponse
+	if err := json.NewDecoder(response.Body).Decode(&transaction); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	for i, expectedCode := range []int{http.StatusCreated, http.StatusInsufficientStorage} {
+		body, writer = prepareMultipartFormData(t, "file", fmt.Sprintf("part%d.txt", i), strings.Repeat("p", 8))
+		req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files", folderID), body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		req.Header.Set("transaction_id", fmt.Sprintf("%d", transaction.TransactionID))
+		checkResponseCode(t, expectedCode, executeRequest(req, router).Code)
+	}
+	if quota = getQuota(t, router); quota.Pending != 8 {
+		t.Errorf("Expected 8 pending bytes. Got %+v", quota)
+	}
+	req = createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/%d/transaction/%d/stop", folderID, transaction.TransactionID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+
+	setUserQuota(t, router, 1, `{"quota": null}`, http.StatusOK)
+	uploadFileContent(t, router, folderID, "big.txt", strings.Repeat("x", 20), "")
+}
+
+// TestFolderSizeLimit tests size limits of folders, which are checked for the folder and all its parent folders
+func TestFolderSizeLimit(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "team", 1)
+	var teamID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'team'`).Scan(&teamID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	createFolder(t, router, "inner", teamID)
+	var innerID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'inner'`).Scan(&innerID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+
+	setFolderMaxSize(t, router, teamID, `{"max_size": -1}`, http.StatusBadRequest)
+	setFolderMaxSize(t, router, teamID, `{"max_size": 50}`, http.StatusNoContent)
+	page := listFolder(t, router, teamID, "")
+	if page.Folder.MaxSize == nil || *page.Folder.MaxSize != 50 {
+		t.Errorf("Expected folder max size 50. Got %+v", page.Folder)
+	}
+
+	file := uploadFileContent(t, router, innerID, "first.txt", strings.Repeat("a", 30), "")
+
+	// the parent folder limit applies to the content of its subfolders
+	body, writer := prepareMultipartFormData(t, "file", "second.txt", strings.Repeat("b", 30))
+	req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files", innerID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusInsufficientStorage, response.Code)
+	var limitErr api.QuotaErrorResponse
+	if err := json.NewDecoder(response.Body).Decode(&limitErr); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	if limitErr.FolderID != int64(teamID) || limitErr.Quota != 50 || limitErr.Used != 30 || limitErr.Requested != 30 {
+		t.Errorf("Expected limit error of folder %d. Got %+v", teamID, limitErr)
+	}
+
+	body, writer = prepareMultipartFormData(t, "file", "huge.txt", strings.Repeat("h", 60))
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files", teamID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	checkResponseCode(t, http.StatusRequestEntityTooLarge, executeRequest(req, router).Code)
+
+	copyItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d/copy", innerID, file.ID), teamID, http.StatusInsufficientStorage)
+
+	// moves out of the folder free the space, moves into it are limited
+	outside := uploadFileContent(t, router, 1, "outside.txt", strings.Repeat("o", 25), "")
+	moveURL := fmt.Sprintf("/v1/folders/1/files/%d/move", outside.ID)
+	moveItem(t, router, moveURL, teamID, http.StatusInsufficientStorage)
+	moveItem(t, router, fmt.Sprintf("/v1/folders/%d/files/%d/move", innerID, file.ID), 1, http.StatusOK)
+	moveItem(t, router, moveURL, teamID, http.StatusOK)
+	verifyFolderSizeValue(t, teamID, 25)
+
+	setFolderMaxSize(t, router, teamID, `{"max_size": null}`, http.StatusNoContent)
+	uploadFileContent(t, router, teamID, "huge.txt", strings.Repeat("h", 60), "")
+}
+
+// TestReconcileFolderSizes tests that drifted folder sizes are fixed in the database and the cache
+func TestReconcileFolderSizes(t *testing.T) {
+	router := setupTestRouter()
+	ctx := context.Background()
+
+	createFolder(t, router, "drifted", 1)
+	var folderID int
+	if err := testDB.QueryRow(`SELECT id FROM folders WHERE name = 'drifted'`).Scan(&folderID); err != nil {
+		t.Fatalf("Failed to get created folder: %v", err)
+	}
+	uploadFileContent(t, router, folderID, "content.txt", strings.Repeat("c", 20), "")
+
+	if _, err := testDB.Exec(`UPDATE folders SET size = 999 WHERE id = $1`, folderID); err != nil {
+		t.Fatalf("Failed to change folder size: %v", err)
+	}
+	cacheKey := fmt.Sprintf("folder_id:%d", folderID)
+	if err := redisClient.Set(ctx, cacheKey, 5, 0).Err(); err != nil {
+		t.Fatalf("Failed to change cached folder size: %v", err)
+	}
+
+	reconcileService := newReconcileService(testDB, database.NewRedisCache(redisClient))
+	report, err := reconcileService.ReconcileFolderSizes(ctx)
+	if err != nil {
+		t.Fatalf("Failed to reconcile folder sizes: %v", err)
+	}
+	found := map[string]models.SizeDiscrepancy{}
+	for _, discrepancy := range report.Discrepancies {
+		if discrepancy.FolderID == int64(folderID) {
+			found[discrepancy.Source] = discrepancy
+		}
+	}
+	expected := map[string]models.SizeDiscrepancy{
+		models.SizeSourceDatabase: {FolderID: int64(folderID), UserID: 1, Source: models.SizeSourceDatabase, Recorded: 999, Actual: 20},
+		models.SizeSourceCache:    {FolderID: int64(folderID), UserID: 1, Source: models.SizeSourceCache, Recorded: 5, Actual: 20},
+	}
+	if len(found) != len(expected) || found[models.SizeSourceDatabase] != expected[models.SizeSourceDatabase] ||
+		found[models.SizeSourceCache] != expected[models.SizeSourceCache] {
+		t.Errorf("Expected discrepancies %v. Got %v", expected, found)
+	}
+
+	verifyFolderSizeValue(t, folderID, 20)
+	cached, err := redisClient.Get(ctx, cacheKey).Int64()
+	if err != nil || cached != 20 {
+		t.Errorf("Expected cached folder size 20. Got %d (%v)", cached, err)
+	}
+
+	// the fixed sizes are not reported again
+	report, err = reconcileService.ReconcileFolderSizes(ctx)
+	if err != nil {
+		t.Fatalf("Failed to reconcile folder sizes: %v", err)
+	}
+	for _, discrepancy := range report.Discrepancies {
+		if discrepancy.FolderID == int64(folderID) {
+			t.Errorf("Expected no discrepancies of folder %d. Got %v", folderID, discrepancy)
+		}
+	}
+}
+
+// TestFolderPaths tests that paths of the folders follow them when they are created, moved, copied and restored
+func TestFolderPaths(t *testing.T) {
+	router := setupTestRouter()
+
+	createFolder(t, router, "path-a", 1)
+	a := folderPath(t, "path-a")
+	createFolder(t, router, "path-b", int(a[1]))
+	b := folderPath(t, "path-b")
+	createFolder(t, router, "path-c", int(b[2]))
+	c := folderPath(t, "path-c")
+	createFolder(t, router, "path-d", 1)
+	d := folderPath(t, "path-d")
+	verifyFolderPath(t, "path-c", []int64{1, a[1], b[2], c[3]})
+
+	// the subfolders follow the moved folder
+	moveItem(t, router, fmt.Sprintf("/v1/folders/%d/move", b[2]), int(d[1]), http.StatusOK)
+	verifyFolderPath(t, "path-b", []int64{1, d[1], b[2]})
+	verifyFolderPath(t, "path-c", []int64{1, d[1], b[2], c[3]})
+
+	// copies get paths of their own
+	copyItem(t, router, fmt.Sprintf("/v1/folders/%d/copy", b[2]), int(a[1]), http.StatusCreated)
+	var copiedB, copiedC int64
+	err := testDB.QueryRow(
+		`SELECT b.id, c.id FROM folders b JOIN folders c ON c.parent_folder_id = b.id
+		 WHERE b.name = 'path-b' AND b.parent_folder_id = $1`, a[1],
+	).Scan(&copiedB, &copiedC)
+	if err != nil {
+		t.Fatalf("Failed to get copied folders: %v", err)
+	}
+	verifyFolderPathByID(t, copiedC, []int64{1, a[1], copiedB, copiedC})
+
+	// the restored folder is put back with its subfolders
+	req := createRequestWithHeaders("DELETE", fmt.Sprintf("/v1/folders/%d", d[1]), nil)
+	checkResponseCode(t, http.StatusNoContent, executeRequest(req, router).Code)
+	req = createRequestWithHeaders("POST", fmt.Sprintf("/v1/trash/%d/restore", listTrash(t, router)["path-d"].ID), nil)
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+	verifyFolderPath(t, "path-c", []int64{1, d[1], b[2], c[3]})
+}
+
+func TestPaths(t *testing.T) {
+	router := setupTestRouter()
+
+	// missing folders are created once
+	created := makeFolderPath(t, router, "/path-docs/2024", http.StatusCreated)
+	existing := makeFolderPath(t, router, "/path-docs/2024", http.StatusOK)
+	if created.ID != existing.ID {
+		t.Fatalf("Expected folder %d, got %d", created.ID, existing.ID)
+	}
+
+	// the uploaded file is reachable by its path, the missing folders are created
+	body, writer := prepareMultipartFormData(t, "file", "ignored.pdf", "report")
+	req := createRequestWithHeaders("POST", "/v1/paths/path-docs/2024/report.pdf", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	checkResponseCode(t, http.StatusCreated, executeRequest(req, router).Code)
+	body, writer = prepareMultipartFormData(t, "file", "notes.txt", "notes")
+	req = createRequestWithHeaders("POST", "/v1/paths/path-docs/2025/notes.txt", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	checkResponseCode(t, http.StatusCreated, executeRequest(req, router).Code)
+
+	item := resolvePath(t, router, "path-docs/2024/report.pdf", http.StatusOK)
+	if item.Type != "file" || item.File.Name != "report.pdf" || item.File.FolderID != created.ID {
+		t.Fatalf("Unexpected item at the path: %+v", item)
+	}
+	if item = resolvePath(t, router, "path-docs", http.StatusOK); item.Type != "folder" || item.Folder.Name != "path-docs" {
+		t.Fatalf("Unexpected item at the path: %+v", item)
+	}
+	resolvePath(t, router, "path-docs/2025/notes.txt", http.StatusOK)
+	resolvePath(t, router, "path-docs/2024/missing.pdf", http.StatusNotFound)
+	resolvePath(t, router, "PATH-DOCS/2024/REPORT.PDF", http.StatusNotFound)
+
+	// names match ignoring case when the user enables it
+	updateSettings(t, router, `{"case_insensitive_paths": true}`)
+	if item = resolvePath(t, router, "PATH-DOCS/2024/REPORT.PDF", http.StatusOK); item.Type != "file" {
+		t.Fatalf("Unexpected item at the path: %+v", item)
+	}
+	if folder := makeFolderPath(t, router, "Path-Docs/2024", http.StatusOK); folder.ID != created.ID {
+		t.Fatalf("Expected folder %d, got %d", created.ID, folder.ID)
+	}
+
+	// a folder differing only in case makes the path ambiguous
+	updateSettings(t, router, `{"case_insensitive_paths": false}`)
+	makeFolderPath(t, router, "PATH-DOCS/2024", http.StatusCreated)
+	updateSettings(t, router, `{"case_insensitive_paths": true}`)
+	resolvePath(t, router, "path-docs/2024", http.StatusOK)
+	resolvePath(t, router, "Path-Docs/2024", http.StatusConflict)
+	updateSettings(t, router, `{"case_insensitive_paths": false}`)
+
+	// a rejected upload leaves no folders of its path behind
+	body, writer = prepareMultipartFormData(t, "file", "corrupted.txt", "corrupted")
+	req = createRequestWithHeaders("POST", "/v1/paths/path-rejected/checksum/corrupted.txt", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Digest", "sha-256="+base64.StdEncoding.EncodeToString(make([]byte, sha256.Size)))
+	checkResponseCode(t, http.StatusBadRequest, executeRequest(req, router).Code)
+	setUserQuota(t, router, 1, `{"quota": 0}`, http.StatusOK)
+	body, writer = prepareMultipartFormData(t, "file", "large.txt", "larger than the quota")
+	req = createRequestWithHeaders("POST", "/v1/paths/path-rejected/quota/large.txt", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	checkResponseCode(t, http.StatusRequestEntityTooLarge, executeRequest(req, router).Code)
+	setUserQuota(t, router, 1, `{"quota": null}`, http.StatusOK)
+	resolvePath(t, router, "path-rejected", http.StatusNotFound)
+}
+
+func TestBreadcrumbs(t *testing.T) {
+	router := setupTestRouter()
+
+	crumbs := makeFolderPath(t, router, "/crumbs", http.StatusCreated)
+	leaf := makeFolderPath(t, router, "/crumbs/a/b", http.StatusCreated)
+	body, writer := prepareMultipartFormData(t, "file", "c.txt", "c")
+	req := createRequestWithHeaders("POST", "/v1/paths/crumbs/a/b/c.txt", body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+	var uploaded api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&uploaded); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+
+	// the file metadata contains all folders of the file, the root folder first
+	req = createRequestWithHeaders("GET", fmt.Sprintf("/v1/folders/%d/files/%d", leaf.ID, uploaded.ID), nil)
+	response = executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+	var file api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&file); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	verifyLocation(t, file.Location, "/crumbs/a/b/c.txt", "/", "crumbs", "a", "b")
+	if file.Breadcrumbs[1].ID != crumbs.ID || file.Breadcrumbs[3].ID != leaf.ID {
+		t.Fatalf("Unexpected breadcrumbs: %+v", file.Breadcrumbs)
+	}
+
+	// the folder doesn't belong to its own breadcrumbs
+	verifyLocation(t, listFolder(t, router, int(leaf.ID), "").Location, "/crumbs/a/b", "/", "crumbs", "a")
+	verifyLocation(t, listFolder(t, router, 1, "limit=1").Location, "/")
+	verifyLocation(t, resolvePath(t, router, "crumbs/a", http.StatusOK).Folder.Location, "/crumbs/a", "/", "crumbs")
+	verifyLocation(t, resolvePath(t, router, "crumbs/a/b/c.txt", http.StatusOK).File.Location, "/crumbs/a/b/c.txt", "/", "crumbs", "a", "b")
+
+	// breadcrumbs follow the moved folder
+	moveItem(t, router, fmt.Sprintf("/v1/folders/%d/move", leaf.ID), int(crumbs.ID), http.StatusOK)
+	verifyLocation(t, listFolder(t, router, int(leaf.ID), "").Location, "/crumbs/b", "/", "crumbs")
+}
+
+func TestFolderTree(t *testing.T) {
+	router := setupTestRouter()
+
+	makeFolderPath(t, router, "/tree-top/a/b/c", http.StatusCreated)
+	top := resolvePath(t, router, "tree-top", http.StatusOK).Folder
+	for _, path := range []string{"tree-top/x.txt", "tree-top/a/y.txt", "tree-top/a/b/c/z.txt"} {
+		body, writer := prepareMultipartFormData(t, "file", "upload", "content")
+		req := createRequestWithHeaders("POST", "/v1/paths/"+path, body)
+		req.Header.Set("Content-Type", writer.FormDataContentType())
+		checkResponseCode(t, http.StatusCreated, executeRequest(req, router).Code)
+	}
+
+	// file counts include the folders below the depth
+	tree := getFolderTree(t, router, top.ID, "", http.StatusOK)
+	if tree.Name != "tree-top" || tree.FileCount != 3 || tree.Size != 21 || len(tree.Files) != 0 || len(tree.Folders) != 1 {
+		t.Fatalf("Unexpected tree: %+v", tree)
+	}
+	if a := tree.Folders[0]; a.Name != "a" || a.FileCount != 2 || a.Size != 14 || len(a.Folders) != 0 {
+		t.Fatalf("Unexpected subfolder: %+v", a)
+	}
+
+	// files of the folders above the depth are nested with the subfolders
+	tree = getFolderTree(t, router, top.ID, "depth=2&files=true", http.StatusOK)
+	if len(tree.Files) != 1 || tree.Files[0].Name != "x.txt" || tree.Files[0].Size != 7 {
+		t.Fatalf("Unexpected files: %+v", tree.Files)
+	}
+	a := tree.Folders[0]
+	if len(a.Files) != 1 || a.Files[0].Name != "y.txt" || len(a.Folders) != 1 {
+		t.Fatalf("Unexpected subfolder: %+v", a)
+	}
+	if b := a.Folders[0]; b.Name != "b" || b.FileCount != 1 || len(b.Files) != 0 || len(b.Folders) != 0 {
+		t.Fatalf("Unexpected subfolder: %+v", b)
+	}
+
+	tree = getFolderTree(t, router, top.ID, "depth=10", http.StatusOK)
+	if c := tree.Folders[0].Folders[0].Folders[0]; c.Name != "c" || c.FileCount != 1 {
+		t.Fatalf("Unexpected subfolder: %+v", c)
+	}
+	if tree = getFolderTree(t, router, top.ID, "depth=0&files=true", http.StatusOK); len(tree.Folders) != 0 || len(tree.Files) != 0 {
+		t.Fatalf("Unexpected tree: %+v", tree)
+	}
+
+	getFolderTree(t, router, top.ID, "depth=-1", http.StatusBadRequest)
+	getFolderTree(t, router, top.ID, "files=maybe", http.StatusBadRequest)
+	getFolderTree(t, router, 1<<40, "", http.StatusNotFound)
+}
+
+// starts a transaction in the folder and expects it to be created
+func startTransaction(t *testing.T, router http.Handler, folderID int) api.TransactionStartResponse {
+	req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/transaction/start", folderID), nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var started api.TransactionStartResponse
+	if err := json.NewDecoder(response.Body).Decode(&started); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	return started
+}
+
+// lists transactions of the user with the query
+func listTransactions(t *testing.T, router http.Handler, query string, expectedCode int) []api.TransactionResponse {
+	req := createRequestWithHeaders("GET", "/v1/transactions?"+query, nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+
+	var transactions []api.TransactionResponse
+	if expectedCode == http.StatusOK {
+		if err := json.NewDecoder(response.Body).Decode(&transactions); err != nil {
+			t.Fatalf("Failed to decode response body: %v", err)
+		}
+	}
+	return transactions
+}
+
+// uploads a file with the content into the folder with the transaction_id header
+func uploadInTransaction(t *testing.T, router http.Handler, folderID int, transactionID, name, content string, expectedCode int) {
+	body, writer := prepareMultipartFormData(t, "file", name, content)
+	req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files", folderID), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("transaction_id", transactionID)
+	checkResponseCode(t, expectedCode, executeRequest(req, router).Code)
+}
+
+// checks the status of the upload transaction in the database
+func verifyTransactionStatus(t *testing.T, transactionID int64, expected string) {
+	var status string
+	if err := testDB.QueryRow(`SELECT status FROM upload_transactions WHERE id = $1`, transactionID).Scan(&status); err != nil {
+		t.Fatalf("Failed to get transaction status: %v", err)
+	}
+	if status != expected {
+		t.Errorf("Expected transaction status %q. Got %q", expected, status)
+	}
+}
+
+// returns items of the trash by their names
+func listTrash(t *testing.T, router http.Handler) map[string]api.TrashItemResponse {
+	req := createRequestWithHeaders("GET", "/v1/trash", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var items []api.TrashItemResponse
+	if err := json.NewDecoder(response.Body).Decode(&items); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+
+	byName := make(map[string]api.TrashItemResponse, len(items))
+	for _, item := range items {
+		byName[item.Name] = item
+	}
+	return byName
+}
+
+// requests the folder tree and checks the response code
+func getFolderTree(t *testing.T, router http.Handler, folderID int64, query string, expectedCode int) api.TreeFolderResponse {
+	req := createRequestWithHeaders("GET", fmt.Sprintf("/v1/folders/%d/tree?%s", folderID, query), nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+
+	var tree api.TreeFolderResponse
+	if expectedCode == http.StatusOK {
+		if err := json.NewDecoder(response.Body).Decode(&tree); err != nil {
+			t.Fatalf("Failed to decode response body: %v", err)
+		}
+	}
+	return tree
+}
+
+// checks the path and the names of the breadcrumbs
+func verifyLocation(t *testing.T, location api.Location, expectedPath string, expectedNames ...string) {
+	names := make([]string, 0, len(location.Breadcrumbs))
+	for _, breadcrumb := range location.Breadcrumbs {
+		names = append(names, breadcrumb.Name)
+	}
+	if location.Path != expectedPath || !slices.Equal(names, expectedNames) {
+		t.Fatalf("Expected path %q with breadcrumbs %v, got %q with %v", expectedPath, expectedNames, location.Path, names)
+	}
+}
+
+// sends a request to create the folders of the path and checks the response code
+func makeFolderPath(t *testing.T, router http.Handler, path string, expectedCode int) api.FolderResponse {
+	req := createRequestWithHeaders("PUT", "/v1/paths"+path, nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+
+	var folder api.FolderResponse
+	if err := json.NewDecoder(response.Body).Decode(&folder); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	return folder
+}
+
+// resolves the path and checks the response code
+func resolvePath(t *testing.T, router http.Handler, path string, expectedCode int) api.PathResponse {
+	req := createRequestWithHeaders("GET", "/v1/paths/"+path, nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+
+	var item api.PathResponse
+	if expectedCode == http.StatusOK {
+		if err := json.NewDecoder(response.Body).Decode(&item); err != nil {
+			t.Fatalf("Failed to decode response body: %v", err)
+		}
+	}
+	return item
+}
+
+// updates the settings of the test user
+func updateSettings(t *testing.T, router http.Handler, payload string) {
+	req := createRequestWithHeaders("PUT", "/v1/settings", bytes.NewBufferString(payload))
+	req.Header.Set("Content-Type", "application/json")
+	checkResponseCode(t, http.StatusOK, executeRequest(req, router).Code)
+}
+
+// returns the storage stats
+func getStorageStats(t *testing.T, router http.Handler) api.StorageStatsResponse {
+	req := createRequestWithHeaders("GET", "/v1/storage/stats", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var stats api.StorageStatsResponse
+	if err := json.NewDecoder(response.Body).Decode(&stats); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	return stats
+}
+
+// sends a request to copy a folder or a file into the folder and checks the response code
+func copyItem(t *testing.T, router http.Handler, url string, newFolderID int, expectedCode int) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(map[string]interface{}{"new_folder_id": newFolderID})
+	req := createRequestWithHeaders("POST", url, bytes.NewBuffer(data))
+	req.Header.Set("Content-Type", "application/json")
+
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+	return response
+}
+
+// uploads a file with the name into the folder and checks the response code
+func uploadNamedFile(t *testing.T, router http.Handler, folderID int, name, query string, expectedCode int) api.FileResponse {
+	body, writer := prepareMultipartFormData(t, "file", name, "content of "+name)
+	req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files?%s", folderID, query), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+
+	var file api.FileResponse
+	if expectedCode == http.StatusCreated {
+		if err := json.NewDecoder(response.Body).Decode(&file); err != nil {
+			t.Fatalf("Failed to decode response body: %v", err)
+		}
+	}
+	return file
+}
+
+// uploads a file with the content into the folder and expects it to be created
+func uploadFileContent(t *testing.T, router http.Handler, folderID int, name, content, query string) api.FileResponse {
+	body, writer := prepareMultipartFormData(t, "file", name, content)
+	req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/files?%s", folderID, query), body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	var file api.FileResponse
+	if err := json.NewDecoder(response.Body).Decode(&file); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	return file
+}
+
+// checks the version numbers of the file, the first one must be current
+func verifyFileVersions(t *testing.T, router http.Handler, folderID int, fileID int64, expected ...int) {
+	req := createRequestWithHeaders("GET", fmt.Sprintf("/v1/folders/%d/files/%d/versions", folderID, fileID), nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var versions []api.FileVersionResponse
+	if err := json.NewDecoder(response.Body).Decode(&versions); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	var numbers []int
+	for _, version := range versions {
+		numbers = append(numbers, version.Version)
+	}
+	if fmt.Sprint(numbers) != fmt.Sprint(expected) || !versions[0].Current {
+		t.Errorf("Expected versions %v, the first one current. Got %+v", expected, versions)
+	}
+}
+
+// sends a request to change the version limit of the folder and checks the response code
+func setFolderMaxVersions(t *testing.T, router http.Handler, folderID int, payload map[string]interface{}, expectedCode int) {
+	data, _ := json.Marshal(payload)
+	req := createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/%d/versioning", folderID), bytes.NewBuffer(data))
+	req.Header.Set("Content-Type", "application/json")
+	checkResponseCode(t, expectedCode, executeRequest(req, router).Code)
+}
+
+// checks the stored size of the folder
+func verifyFolderSizeValue(t *testing.T, folderID int, expected int64) {
+	var size int64
+	if err := testDB.QueryRow(`SELECT size FROM folders WHERE id = $1`, folderID).Scan(&size); err != nil {
+		t.Fatalf("Failed to get folder size: %v", err)
+	}
+	if size != expected {
+		t.Errorf("Expected folder %d size %d. Got %d", folderID, expected, size)
+	}
+}
+
+// returns the quota of the test user
+func getQuota(t *testing.T, router http.Handler) api.QuotaResponse {
+	req := createRequestWithHeaders("GET", "/v1/quota", nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var quota api.QuotaResponse
+	if err := json.NewDecoder(response.Body).Decode(&quota); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	return quota
+}
+
+// sends a request to change the quota of the user and checks the response code
+func setUserQuota(t *testing.T, router http.Handler, userID int, payload string, expectedCode int) api.QuotaResponse {
+	req := createRequestWithHeaders("PUT", fmt.Sprintf("/v1/admin/users/%d/quota", userID), strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+
+	var quota api.QuotaResponse
+	if expectedCode == http.StatusOK {
+		if err := json.NewDecoder(response.Body).Decode(&quota); err != nil {
+			t.Fatalf("Failed to decode response body: %v", err)
+		}
+	}
+	return quota
+}
+
+// returns the path of the folder with the name
+func folderPath(t *testing.T, name string) []int64 {
+	var path []int64
+	if err := testDB.QueryRow(`SELECT path FROM folders WHERE name = $1`, name).Scan(pq.Array(&path)); err != nil {
+		t.Fatalf("Failed to get path of folder %q: %v", name, err)
+	}
+	return path
+}
+
+// checks the path of the folder with the name
+func verifyFolderPath(t *testing.T, name string, expected []int64) {
+	if path := folderPath(t, name); !slices.Equal(path, expected) {
+		t.Errorf("Expected path of folder %q %v. Got %v", name, expected, path)
+	}
+}
+
+// checks the path of the folder with the id
+func verifyFolderPathByID(t *testing.T, id int64, expected []int64) {
+	var path []int64
+	if err := testDB.QueryRow(`SELECT path FROM folders WHERE id = $1`, id).Scan(pq.Array(&path)); err != nil {
+		t.Fatalf("Failed to get path of folder %d: %v", id, err)
+	}
+	if !slices.Equal(path, expected) {
+		t.Errorf("Expected path of folder %d %v. Got %v", id, expected, path)
+	}
+}
+
+// sends a request to change the size limit of the folder and checks the response code
+func setFolderMaxSize(t *testing.T, router http.Handler, folderID int, payload string, expectedCode int) {
+	req := createRequestWithHeaders("PUT", fmt.Sprintf("/v1/folders/%d/size_limit", folderID), strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	checkResponseCode(t, expectedCode, executeRequest(req, router).Code)
+}
+
+// sends a request to move a folder or a file and checks the response code
+func moveItem(t *testing.T, router http.Handler, url string, newFolderID int, expectedCode int) {
+	data, _ := json.Marshal(map[string]interface{}{"new_folder_id": newFolderID})
+	req := createRequestWithHeaders("PUT", url, bytes.NewBuffer(data))
+	req.Header.Set("Content-Type", "application/json")
+	checkResponseCode(t, expectedCode, executeRequest(req, router).Code)
+}
+
+// sends a request to rename a folder or a file and checks the response code
+func renameItem(t *testing.T, router http.Handler, url, name string, expectedCode int) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(api.RenameRequest{Name: name})
+	req := createRequestWithHeaders("PATCH", url, bytes.NewBuffer(data))
+	req.Header.Set("Content-Type", "application/json")
+
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+	return response
+}
+
+// requests a page of the folder listing
+func listFolder(t *testing.T, router http.Handler, folderID int, query string) api.FolderListResponse {
+	req := createRequestWithHeaders("GET", fmt.Sprintf("/v1/folders/%d/children?%s", folderID, query), nil)
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusOK, response.Code)
+
+	var page api.FolderListResponse
+	if err := json.NewDecoder(response.Body).Decode(&page); err != nil {
+		t.Fatalf("Failed to decode response body: %v", err)
+	}
+	return page
+}
+
+// checks the names of the listing items
+func verifyListItems(t *testing.T, page api.FolderListResponse, expectedNames ...string) {
+	var names []string
+	for _, item := range page.Items {
+		names = append(names, item.Name)
+	}
+	if strings.Join(names, ",") != strings.Join(expectedNames, ",") {
+		t.Errorf("Expected items %v. Got %v", expectedNames, names)
+	}
+}
+
+// creates a resumable upload and returns its location
+func createUpload(t *testing.T, router http.Handler, folderID int, name string, length int) string {
+	req := createRequestWithHeaders("POST", fmt.Sprintf("/v1/folders/%d/uploads", folderID), nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", fmt.Sprintf("%d", length))
+	req.Header.Set("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte(name)))
+
+	response := executeRequest(req, router)
+	checkResponseCode(t, http.StatusCreated, response.Code)
+
+	location := response.Header().Get("Location")
+	if location == "" {
+		t.Fatalf("Expected Location of the created upload")
+	}
+	return location
+}
+
+// sends a chunk of the resumable upload and checks the response code
+func patchUpload(t *testing.T, router http.Handler, location string, offset int, chunk string, expectedCode int) {
+	req := createRequestWithHeaders("PATCH", location, bytes.NewBufferString(chunk))
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Offset", fmt.Sprintf("%d", offset))
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+
+	response := executeRequest(req, router)
+	checkResponseCode(t, expectedCode, response.Code)
+}
+
+// returns the offset of the resumable upload
+func getUploadOffset(t *testing.T, router http.Handler, location string) string {
+	req := createRequestWithHeaders("HEAD", location, nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+
+	response := executeRequest(req, router)
 	checkResponseCode(t, http.StatusOK, response.Code)
+	return response.Header().Get("Upload-Offset")
 }
 
 // prepares multipart form data for file uploads
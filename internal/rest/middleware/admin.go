@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"net/http"
+	"slices"
+)
+
+// Admin lets through only the requests of the admin users,
+// in reality the permission should come with the authenticated user, here the list is taken from the config
+func Admin(adminIDs []int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(UserIDHeaderKey).(int)
+			if !ok || !slices.Contains(adminIDs, userID) {
+				http.Error(w, "No rights to use the admin endpoints", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
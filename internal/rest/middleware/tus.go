@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"net/http"
+)
+
+// TusVersion is the only supported version of the tus resumable upload protocol
+const TusVersion = "1.0.0"
+
+// Tus checks the protocol version of the resumable upload requests
+// and adds Tus-Resumable header to every response
+func Tus(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Tus-Resumable", TusVersion)
+
+		// OPTIONS is used by clients to discover the server, so it is allowed without version
+		if r.Method != http.MethodOptions && r.Header.Get("Tus-Resumable") != TusVersion {
+			w.Header().Set("Tus-Version", TusVersion)
+			http.Error(w, "Unsupported tus protocol version", http.StatusPreconditionFailed)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
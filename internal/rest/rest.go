@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
@@ -11,12 +12,23 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/saur4ig/file-storage/internal/config"
 	"github.com/saur4ig/file-storage/internal/database"
+	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
+	"github.com/saur4ig/file-storage/internal/models"
 	"github.com/saur4ig/file-storage/internal/rest/api"
 	"github.com/saur4ig/file-storage/internal/rest/middleware"
 	"github.com/saur4ig/file-storage/internal/services"
 	si "github.com/saur4ig/file-storage/internal/services/interface"
 )
 
+const (
+	// how often expired resumable uploads are removed
+	uploadsCleanupInterval = 10 * time.Minute
+	// how often expired items are purged from the trash
+	trashPurgeInterval = time.Hour
+	// how often abandoned upload transactions are rolled back
+	transactionsSweepInterval = time.Minute
+)
+
 func CreateServer(conf config.Config) {
 	// initialize Redis client
 	redisClient := redis.NewClient(&redis.Options{
@@ -29,17 +41,33 @@ func CreateServer(conf config.Config) {
 	dbClient := newConnection(conf.DB)
 	log.Info().Msg("Database connected")
 
+	// initialize file storage
+	storage, err := newFileStorage(conf.Storage)
+	if err != nil {
+		log.Fatal().Msgf("could not initialize file storage: %v", err)
+	}
+	log.Info().Msgf("File storage initialized: %s", conf.Storage.Type)
+
 	// initialize services and cache
 	rc := database.NewRedisCache(redisClient)
-	folderS, fileS, transactionS, s3S := initDBServices(dbClient)
+	folderS, fileS, transactionS, uploadS, trashS, userS := initDBServices(dbClient, storage, rc, conf)
 	log.Info().Msg("Services initialized")
 
+	// remove abandoned resumable uploads in background
+	go cleanupExpiredUploads(uploadS)
+	// roll back upload transactions abandoned by their clients
+	go sweepExpiredTransactions(transactionS)
+	// permanently delete items kept in the trash longer than the retention period
+	go purgeExpiredTrash(trashS)
+	// fix folder sizes which drifted from the content of the folders
+	go reconcileFolderSizes(newReconcileService(dbClient, rc), conf.Reconcile.Interval)
+
 	// create API handler
-	handler := api.New(folderS, fileS, transactionS, s3S, rc)
+	handler := api.New(folderS, fileS, transactionS, uploadS, trashS, userS, storage, rc, conf.Naming.ConflictPolicy)
 
 	// setup routes
 	router := http.NewServeMux()
-	withRoutes := routes(router, handler)
+	withRoutes := routes(router, handler, conf.Admin.UserIDs)
 	log.Info().Msg("Routes set")
 
 	// setup middleware
@@ -95,15 +123,151 @@ func newConnection(cfg config.DbConfig) *sql.DB {
 }
 
 // initializes all services
-func initDBServices(db *sql.DB) (si.FolderService, si.FileService, si.TransactionService, si.FileStorage) {
+func initDBServices(
+	db *sql.DB, storage si.FileStorage, rc rinterface.FolderSizeCache, conf config.Config,
+) (si.FolderService, si.FileService, si.TransactionService, si.UploadService, si.TrashService, si.UserService) {
 	folderRepo := database.NewFolderRepository(db)
 	fileRepo := database.NewFileRepository(db)
 	transactionRepo := database.NewTransactionRepository(db)
+	uploadRepo := database.NewUploadRepository(db)
+	blobRepo := database.NewBlobRepository(db)
+	trashRepo := database.NewTrashRepository(db)
+	versionRepo := database.NewFileVersionRepository(db)
+	userRepo := database.NewUserRepository(db)
+
+	folderService := services.NewFolderService(folderRepo, fileRepo, blobRepo, trashRepo, versionRepo, userRepo, storage, db)
+	fileService := services.NewFileService(
+		folderRepo, fileRepo, blobRepo, trashRepo, versionRepo, userRepo, transactionRepo, storage, db,
+		conf.Versions.MaxVersions,
+	)
+	transactionService := services.NewTransactionService(
+		transactionRepo, folderRepo, fileRepo, blobRepo, versionRepo, rc, storage, db,
+		conf.Transaction.TTL, conf.Transaction.MaxTTL,
+	)
+	uploadService := services.NewUploadService(uploadRepo, fileService, storage, db, conf.Upload.Expiration)
+	trashService := services.NewTrashService(
+		trashRepo, folderRepo, fileRepo, blobRepo, versionRepo, storage, db, conf.Trash.Retention,
+	)
+
+	userService := services.NewUserService(userRepo)
+
+	return folderService, fileService, transactionService, uploadService, trashService, userService
+}
+
+// periodically removes expired resumable uploads with all received chunks
+func cleanupExpiredUploads(uploadService si.UploadService) {
+	ticker := time.NewTicker(uploadsCleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		deleted, err := uploadService.DeleteExpiredUploads()
+		if err != nil {
+			log.Warn().Msgf("Failed to delete expired uploads: %s", err.Error())
+		}
+		if deleted > 0 {
+			log.Info().Msgf("Deleted %d expired uploads", deleted)
+		}
+	}
+}
+
+// periodically rolls back pending or resumed upload transactions which outlived their ttl
+func sweepExpiredTransactions(transactionService si.TransactionService) {
+	ticker := time.NewTicker(transactionsSweepInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		expired, err := transactionService.ExpireTransactions(context.Background())
+		if err != nil {
+			log.Warn().Msgf("Failed to expire upload transactions: %s", err.Error())
+		}
+		if expired > 0 {
+			log.Info().Msgf("Rolled back %d expired upload transactions", expired)
+		}
+	}
+}
+
+// periodically purges items kept in the trash longer than the retention period
+func purgeExpiredTrash(trashService si.TrashService) {
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		purged, err := trashService.PurgeExpiredItems()
+		if err != nil {
+			log.Warn().Msgf("Failed to purge expired trash items: %s", err.Error())
+		}
+		if purged > 0 {
+			log.Info().Msgf("Purged %d expired trash items", purged)
+		}
+	}
+}
+
+// Reconcile recalculates sizes of all folders once, fixes the differing ones and logs every discrepancy found
+func Reconcile(conf config.Config) error {
+	redisClient := redis.NewClient(&redis.Options{
+		Addr: fmt.Sprintf("%s:%s", conf.Cache.Host, conf.Cache.Port),
+		DB:   0,
+	})
+	defer redisClient.Close()
+
+	dbClient := newConnection(conf.DB)
+	defer dbClient.Close()
+
+	report, err := newReconcileService(dbClient, database.NewRedisCache(redisClient)).
+		ReconcileFolderSizes(context.Background())
+	if report != nil {
+		logReconcileReport(report)
+	}
+	return err
+}
+
+// initializes the service which fixes folder sizes in the database and the cache
+func newReconcileService(db *sql.DB, rc rinterface.FolderSizeCache) si.ReconcileService {
+	return services.NewReconcileService(database.NewFolderRepository(db), database.NewUserRepository(db), rc, db)
+}
 
-	folderService := services.NewFolderService(folderRepo, fileRepo, db)
-	fileService := services.NewFileService(folderRepo, fileRepo, db)
-	transactionService := services.NewTransactionService(transactionRepo)
-	s3Service := services.NewS3Service()
+// periodically recalculates folder sizes and logs every discrepancy found
+func reconcileFolderSizes(reconcileService si.ReconcileService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
 
-	return folderService, fileService, transactionService, s3Service
+	for range ticker.C {
+		report, err := reconcileService.ReconcileFolderSizes(context.Background())
+		if err != nil {
+			log.Warn().Msgf("Failed to reconcile folder sizes: %s", err.Error())
+		}
+		if report != nil {
+			logReconcileReport(report)
+		}
+	}
+}
+
+// logs every fixed folder size of the reconciliation and its summary
+func logReconcileReport(report *models.ReconcileReport) {
+	for _, discrepancy := range report.Discrepancies {
+		log.Warn().
+			Int64("folder_id", discrepancy.FolderID).
+			Int("user_id", discrepancy.UserID).
+			Str("source", discrepancy.Source).
+			Int64("recorded", discrepancy.Recorded).
+			Int64("actual", discrepancy.Actual).
+			Msg("Fixed folder size")
+	}
+	log.Info().
+		Int("folders", report.Folders).
+		Int("users", report.Users).
+		Int("fixed", len(report.Discrepancies)).
+		Msg("Reconciled folder sizes")
+}
+
+// initializes the file storage backend chosen in the config
+func newFileStorage(cfg config.StorageConfig) (si.FileStorage, error) {
+	switch cfg.Type {
+	case config.StorageTypeS3:
+		return services.NewS3Service(cfg.S3)
+	case config.StorageTypeLocal:
+		return services.NewLocalStorage(cfg.Path)
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Type)
+	}
 }
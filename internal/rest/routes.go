@@ -8,24 +8,72 @@ import (
 )
 
 func routes(
-	router *http.ServeMux, handler *api.Handler,
+	router *http.ServeMux, handler *api.Handler, adminIDs []int,
 ) *http.ServeMux {
+	adminOnly := middleware.Admin(adminIDs)
+
 	// folder endpoints
 	router.Handle("POST /folders", handler.CreateFolder())
 	router.Handle("GET /folders/{folder_id}", middleware.FolderMiddleware(handler.GetFolder()))
+	router.Handle("GET /folders/{folder_id}/children", middleware.FolderMiddleware(handler.ListFolder()))
+	router.Handle("GET /folders/{folder_id}/tree", middleware.FolderMiddleware(handler.FolderTree()))
 	router.Handle("PUT /folders/{folder_id}/move", middleware.FolderMiddleware(handler.MoveFolder()))
+	router.Handle("PATCH /folders/{folder_id}", middleware.FolderMiddleware(handler.RenameFolder()))
+	router.Handle("POST /folders/{folder_id}/copy", middleware.FolderMiddleware(handler.CopyFolder()))
 	router.Handle("DELETE /folders/{folder_id}", middleware.FolderMiddleware(handler.RemoveFolder()))
+	router.Handle("PUT /folders/{folder_id}/versioning", middleware.FolderMiddleware(handler.SetFolderVersioning()))
+	router.Handle("PUT /folders/{folder_id}/size_limit", middleware.FolderMiddleware(handler.SetFolderSizeLimit()))
 
 	// file endpoints
-	router.Handle("GET /folders/{folder_id}/files/{file_id}", middleware.FolderMiddleware(handler.UploadFile()))
+	router.Handle("GET /folders/{folder_id}/files/{file_id}", middleware.FolderMiddleware(handler.GetFile()))
+	router.Handle("GET /folders/{folder_id}/files/{file_id}/content", middleware.FolderMiddleware(handler.DownloadFile()))
 	router.Handle("POST /folders/{folder_id}/files", middleware.FolderMiddleware(handler.UploadFile()))
 	router.Handle("PUT /folders/{folder_id}/files/{file_id}/move", middleware.FolderMiddleware(handler.MoveFile()))
+	router.Handle("PATCH /folders/{folder_id}/files/{file_id}", middleware.FolderMiddleware(handler.RenameFile()))
+	router.Handle("POST /folders/{folder_id}/files/{file_id}/copy", middleware.FolderMiddleware(handler.CopyFile()))
 	router.Handle("DELETE /folders/{folder_id}/files/{file_id}", middleware.FolderMiddleware(handler.DeleteFile()))
 
+	// file version endpoints
+	router.Handle("GET /folders/{folder_id}/files/{file_id}/versions", middleware.FolderMiddleware(handler.ListFileVersions()))
+	router.Handle("GET /folders/{folder_id}/files/{file_id}/versions/{version}/content", middleware.FolderMiddleware(handler.DownloadFileVersion()))
+	router.Handle("POST /folders/{folder_id}/files/{file_id}/versions/{version}/restore", middleware.FolderMiddleware(handler.RestoreFileVersion()))
+
+	// resumable upload endpoints (tus protocol)
+	router.Handle("OPTIONS /uploads", middleware.Tus(handler.UploadOptions()))
+	router.Handle("POST /folders/{folder_id}/uploads", middleware.FolderMiddleware(middleware.Tus(handler.CreateUpload())))
+	router.Handle("HEAD /uploads/{upload_id}", middleware.Tus(handler.GetUploadOffset()))
+	router.Handle("PATCH /uploads/{upload_id}", middleware.Tus(handler.PatchUpload()))
+	router.Handle("DELETE /uploads/{upload_id}", middleware.Tus(handler.DeleteUpload()))
+
 	// transaction endpoints
 	router.Handle("POST /folders/{folder_id}/transaction/start", middleware.FolderMiddleware(handler.StartTransaction()))
 	router.Handle("PUT /folders/{folder_id}/transaction/{transaction_id}/stop", middleware.FolderMiddleware(handler.StopTransaction()))
 	router.Handle("PUT /folders/{folder_id}/transaction/{transaction_id}/complete", middleware.FolderMiddleware(handler.CompleteTransaction()))
+	router.Handle("PUT /folders/{folder_id}/transaction/{transaction_id}/pause", middleware.FolderMiddleware(handler.PauseTransaction()))
+	router.Handle("PUT /folders/{folder_id}/transaction/{transaction_id}/resume", middleware.FolderMiddleware(handler.ResumeTransaction()))
+	router.Handle("GET /transactions", handler.ListTransactions())
+	router.Handle("GET /transactions/{transaction_id}", handler.GetTransaction())
+
+	// trash endpoints
+	router.Handle("GET /trash", handler.ListTrash())
+	router.Handle("POST /trash/{trash_id}/restore", handler.RestoreTrashItem())
+	router.Handle("DELETE /trash/{trash_id}", handler.DeleteTrashItem())
+
+	// quota endpoints
+	router.Handle("GET /quota", handler.GetQuota())
+	router.Handle("PUT /admin/users/{target_id}/quota", adminOnly(handler.SetUserQuota()))
+
+	// path endpoints
+	router.Handle("GET /paths/{path...}", handler.ResolvePath())
+	router.Handle("PUT /paths/{path...}", handler.MakeFolderPath())
+	router.Handle("POST /paths/{path...}", handler.UploadFileToPath())
+
+	// settings endpoints
+	router.Handle("GET /settings", handler.GetSettings())
+	router.Handle("PUT /settings", handler.UpdateSettings())
+
+	// storage endpoints
+	router.Handle("GET /storage/stats", adminOnly(handler.GetStorageStats()))
 
 	// just a ping
 	router.Handle("GET /ping", handler.Ping())
@@ -6,7 +6,24 @@ import (
 
 type FileService interface {
 	GetFile(fileID int64) (*models.File, error)
-	UploadFile(folderID int64, userID int, name, s3URL string, size int64, transactionID *int64) error
-	MoveFile(fileID, folderID, newFolderID int64) error
+	// UploadFile saves the file, policy defines what happens if its name is already taken,
+	// returns the number of bytes the folder size grew by
+	UploadFile(file *models.File, policy string) (int64, error)
+	// UploadFileToPath saves the file at the path under the root folder of the user creating missing folders
+	// together with the file, returns the number of bytes the folder size grew by
+	UploadFileToPath(file *models.File, path, policy string) (int64, error)
+	MoveFile(fileID, folderID, newFolderID int64, policy string) error
+	// CopyFile copies the file into the folder, policy defines what happens if its name is already taken
+	CopyFile(fileID, newFolderID int64, policy string) (*models.File, error)
+	RenameFile(fileID int64, name, policy string) (*models.File, error)
+	// DeleteFile moves the file to the trash
 	DeleteFile(id int64) error
+	// RestoreFile brings the file deleted with the trash item back, folderID is used if its folder is gone
+	RestoreFile(trashID, folderID int64, policy string) (*models.File, error)
+	GetStorageStats() (*models.StorageStats, error)
+	// ListFileVersions returns all versions of the file, the current one first
+	ListFileVersions(fileID int64) ([]models.FileVersion, error)
+	GetFileVersion(fileID int64, version int) (*models.FileVersion, error)
+	// RestoreFileVersion makes the content of the version the new current version of the file
+	RestoreFileVersion(fileID int64, version int) (*models.File, error)
 }
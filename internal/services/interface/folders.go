@@ -5,11 +5,30 @@ import (
 )
 
 type FolderService interface {
-	CreateFolder(userID int, name string, parentFolderID int64) (int64, error)
+	// CreateFolder creates a folder, policy defines what happens if its name is already taken
+	CreateFolder(userID int, name string, parentFolderID int64, policy string) (int64, error)
+	// DeleteFolder moves the folder with all its content to the trash
 	DeleteFolder(id int64) error
-	MoveFolder(folderID, newFolderID int64) error
+	// RestoreFolder brings the folder deleted with the trash item back, parentFolderID is used if its parent is gone
+	RestoreFolder(trashID, parentFolderID int64, policy string) (*models.Folder, error)
+	MoveFolder(folderID, newFolderID int64, policy string) error
+	// CopyFolder copies the folder with all its content into the new parent folder
+	CopyFolder(folderID, newFolderID int64, policy string) (*models.Folder, error)
+	RenameFolder(folderID int64, name, policy string) (*models.Folder, error)
+	// SetFolderMaxVersions sets how many versions of a file are kept in the folder, nil for the default limit
+	SetFolderMaxVersions(id int64, maxVersions *int) error
+	// SetFolderMaxSize limits the size of the folder with all its content, nil removes the limit
+	SetFolderMaxSize(id int64, maxSize *int64) error
 	UpdateFolderSize(id int64, size int64) error
 	GetFolderInfo(id int64) ([]models.FolderSize, error)
-	GetAllParentFolders(folderID int64) ([]models.FolderSizeSimplified, error)
-	UpdateMultipleFoldersSize(folders []models.FolderSizeSimplified) error
+	// WalkFolderTree calls visit for the folder of the user and its subtree down to the depth, depth-first
+	WalkFolderTree(userID int, folderID int64, opts models.TreeOptions, visit func(models.TreeNode) error) error
+	// ListFolder returns a page of subfolders and files of the folder
+	ListFolder(id int64, opts models.ListOptions) (*models.FolderListing, error)
+	// GetBreadcrumbs returns folders from the root folder to the folder itself
+	GetBreadcrumbs(folderID int64) ([]models.Breadcrumb, error)
+	// ResolvePath returns the folder or the file at the slash separated path under the root folder of the user
+	ResolvePath(userID int, path string) (*models.PathItem, error)
+	// MakeFolderPath returns the folder at the path creating missing folders on the way, reports if any was created
+	MakeFolderPath(userID int, path string) (*models.Folder, bool, error)
 }
@@ -0,0 +1,14 @@
+package _interface
+
+import (
+	"context"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// ReconcileService keeps the recorded folder sizes consistent with the content of the folders
+type ReconcileService interface {
+	// ReconcileFolderSizes recalculates sizes of all folders, fixes the differing ones in the database and the cache
+	// and reports every discrepancy found
+	ReconcileFolderSizes(ctx context.Context) (*models.ReconcileReport, error)
+}
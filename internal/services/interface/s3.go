@@ -1,13 +1,26 @@
 package _interface
 
+import (
+	"io"
+)
+
 // FileStorage is an interface that defines methods to store, generateUlr and remove files
 type FileStorage interface {
-	// UploadFile uploads a file to the storage
-	UploadFile(fileData []byte, fileName string) (fileURL string, err error)
+	// UploadFile streams a file to the storage and returns the key of the stored object
+	// with the number of written bytes, size is -1 if the length of the file is unknown
+	UploadFile(fileData io.Reader, size int64, fileName string) (fileKey string, written int64, err error)
+
+	// OpenFile opens a stored file for reading, the returned reader supports seeking
+	// so the file can be served partially
+	OpenFile(fileKey string) (io.ReadSeekCloser, error)
+
+	// GeneratePreSignedURL generates a pre-signed URL for the specified file operation,
+	// method is http.MethodGet to download the file or http.MethodPut to upload it
+	GeneratePreSignedURL(fileKey, method string) (preSignedURL string, err error)
 
-	// GeneratePreSignedURL generates a pre-signed URL for the specified file operation
-	GeneratePreSignedURL(fileName string) (preSignedURL string, err error)
+	// CopyFile copies a stored file to a new object and returns its key
+	CopyFile(fileKey, fileName string) (newFileKey string, err error)
 
 	// DeleteFile deletes a file from the storage
-	DeleteFile(fileURL string) error
+	DeleteFile(fileKey string) error
 }
@@ -1,11 +1,29 @@
 package _interface
 
 import (
+	"context"
+	"time"
+
 	"github.com/saur4ig/file-storage/internal/models"
 )
 
 type TransactionService interface {
-	CreateTransaction(userID int, folderID int64) (int64, error)
+	// CreateTransaction starts a pending transaction, which expires after the ttl, 0 for the default one
+	CreateTransaction(userID int, folderID int64, ttl time.Duration) (*models.UploadTransaction, error)
 	GetTransactionByID(id int64) (*models.UploadTransaction, error)
-	UpdateTransactionStatus(id int64, status string) error
+	// ListTransactions returns transactions of the user matching the filter, the newest first
+	ListTransactions(userID int, filter models.TransactionFilter) ([]models.UploadTransaction, error)
+	// GetTransactionFiles returns files uploaded in the transaction
+	GetTransactionFiles(id int64) ([]models.File, error)
+	// CompleteTransaction completes the pending or resumed transaction of the user and the folder, its files are added to folder sizes
+	CompleteTransaction(userID int, folderID, id int64) error
+	// PauseTransaction pauses the pending or resumed transaction of the user and the folder, its ttl stops until it is resumed
+	PauseTransaction(userID int, folderID, id int64) (*models.UploadTransaction, error)
+	// ResumeTransaction moves the paused transaction of the user and the folder to resumed, it accepts uploads again
+	ResumeTransaction(userID int, folderID, id int64) (*models.UploadTransaction, error)
+	// StopTransaction rolls back the transaction of the user and the folder with all its files,
+	// a repeated stop does nothing
+	StopTransaction(ctx context.Context, userID int, folderID, id int64) error
+	// ExpireTransactions rolls back pending or resumed transactions which outlived their ttl, returns how many
+	ExpireTransactions(ctx context.Context) (int, error)
 }
@@ -0,0 +1,15 @@
+package _interface
+
+import (
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// TrashService manages deleted folders and files, which are kept for the retention period
+type TrashService interface {
+	GetTrashItem(id int64) (*models.TrashItem, error)
+	ListTrash(userID int) ([]models.TrashItem, error)
+	// DeleteTrashItem permanently deletes the item with all its content
+	DeleteTrashItem(id int64) error
+	// PurgeExpiredItems permanently deletes all expired items and returns their number
+	PurgeExpiredItems() (int, error)
+}
@@ -0,0 +1,23 @@
+package _interface
+
+import (
+	"io"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// UploadService manages resumable uploads, which are received in chunks
+type UploadService interface {
+	// CreateUpload starts an upload, onConflict is the name conflict policy applied when the file is saved
+	CreateUpload(userID int, folderID int64, name string, length int64, metadata, onConflict string) (*models.Upload, error)
+	// GetUpload returns the upload, if it is not expired
+	GetUpload(id string) (*models.Upload, error)
+	// WriteChunk stores the chunk received for the offset and returns the updated upload,
+	// size is -1 if the length of the chunk is unknown
+	WriteChunk(upload *models.Upload, offset int64, chunk io.Reader, size int64) (*models.Upload, error)
+	// CompleteUpload turns the fully received upload into a file, returns the number of bytes the folder size grew by
+	CompleteUpload(upload *models.Upload) (int64, error)
+	DeleteUpload(upload *models.Upload) error
+	// DeleteExpiredUploads removes all expired uploads and returns their number
+	DeleteExpiredUploads() (int, error)
+}
@@ -0,0 +1,17 @@
+package _interface
+
+import (
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+type UserService interface {
+	// GetUserQuota returns the storage quota of the user with its current usage
+	GetUserQuota(userID int) (*models.UserQuota, error)
+	// SetUserQuota changes the storage quota of the user, nil removes the limit
+	SetUserQuota(userID int, quota *int64) (*models.UserQuota, error)
+	// CheckQuota returns *models.QuotaError if size more bytes do not fit into the quota of the user
+	CheckQuota(userID int, size int64) error
+	GetUserSettings(userID int) (*models.UserSettings, error)
+	// UpdateUserSettings saves the settings and returns them
+	UpdateUserSettings(settings *models.UserSettings) (*models.UserSettings, error)
+}
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
+	"github.com/saur4ig/file-storage/internal/models"
+	_interface "github.com/saur4ig/file-storage/internal/services/interface"
+)
+
+// acquireFileContent makes the new file reference the blob with its content, if the same content is
+// already stored, the file is pointed to the existing object, files without checksum keep their own objects
+func acquireFileContent(tx *sql.Tx, blobRepo rinterface.BlobRepository, file *models.File) error {
+	if file.Checksum == "" {
+		return nil
+	}
+
+	storageKey, err := blobRepo.AcquireBlob(tx, &models.Blob{
+		Checksum:   file.Checksum,
+		StorageKey: file.S3URL,
+		Size:       file.Size,
+	})
+	if err != nil {
+		return err
+	}
+
+	file.S3URL = storageKey
+	file.BlobChecksum = file.Checksum
+	return nil
+}
+
+// releaseFileContent drops the reference of the deleted file to its content,
+// returns the key of the object which is not referenced anymore or an empty string
+func releaseFileContent(tx *sql.Tx, blobRepo rinterface.BlobRepository, file *models.File) (string, error) {
+	if file.BlobChecksum == "" {
+		return file.S3URL, nil
+	}
+
+	storageKey, err := blobRepo.ReleaseBlob(tx, file.BlobChecksum)
+	if err != nil {
+		return "", fmt.Errorf("failed to release content of file(%d): %w", file.ID, err)
+	}
+	return storageKey, nil
+}
+
+// releaseVersionsContent drops references of the deleted versions to their content,
+// returns keys of the objects which are not referenced anymore
+func releaseVersionsContent(tx *sql.Tx, blobRepo rinterface.BlobRepository, versions []models.FileVersion) ([]string, error) {
+	var orphanKeys []string
+	for _, version := range versions {
+		if version.BlobChecksum == "" {
+			orphanKeys = append(orphanKeys, version.S3URL)
+			continue
+		}
+
+		storageKey, err := blobRepo.ReleaseBlob(tx, version.BlobChecksum)
+		if err != nil {
+			return nil, fmt.Errorf("failed to release content of file(%d) version %d: %w", version.FileID, version.Version, err)
+		}
+		orphanKeys = append(orphanKeys, storageKey)
+	}
+	return orphanKeys, nil
+}
+
+// releaseFilesContent drops references of the deleted files and all their versions to the content,
+// returns keys of the objects which are not referenced anymore
+func releaseFilesContent(
+	tx *sql.Tx, blobRepo rinterface.BlobRepository, versionRepo rinterface.FileVersionRepository, files []models.File,
+) ([]string, error) {
+	ids := make([]int64, len(files))
+	var orphanKeys []string
+	for i := range files {
+		ids[i] = files[i].ID
+		orphanKey, err := releaseFileContent(tx, blobRepo, &files[i])
+		if err != nil {
+			return nil, err
+		}
+		orphanKeys = append(orphanKeys, orphanKey)
+	}
+
+	versions, err := versionRepo.DeleteFileVersions(tx, ids)
+	if err != nil {
+		return nil, err
+	}
+	versionKeys, err := releaseVersionsContent(tx, blobRepo, versions)
+	if err != nil {
+		return nil, err
+	}
+	return append(orphanKeys, versionKeys...), nil
+}
+
+// copyFileContent makes the copied files reference the content of their source files, blobs get
+// the new references in one update and objects owned by the source files are copied. Returns keys of
+// the copied objects, they have to be removed if the transaction is not committed
+func copyFileContent(
+	tx *sql.Tx, blobRepo rinterface.BlobRepository, fileRepo rinterface.FileRepository,
+	storage _interface.FileStorage, files []models.File,
+) ([]string, error) {
+	refs := make(map[string]int)
+	var copiedKeys []string
+	for i := range files {
+		file := &files[i]
+		if file.BlobChecksum != "" {
+			refs[file.BlobChecksum]++
+			continue
+		}
+
+		key, err := storage.CopyFile(file.S3URL, file.Name)
+		if err != nil {
+			return copiedKeys, fmt.Errorf("failed to copy content of file(%d): %w", file.ID, err)
+		}
+		copiedKeys = append(copiedKeys, key)
+
+		if err = fileRepo.UpdateFileKey(tx, file.ID, key); err != nil {
+			return copiedKeys, err
+		}
+		file.S3URL = key
+	}
+
+	if err := blobRepo.AddBlobReferences(tx, refs); err != nil {
+		return copiedKeys, err
+	}
+	return copiedKeys, nil
+}
+
+// copyVersionContent makes the copied versions reference the content of their source versions,
+// the same way as copyFileContent does for files. Returns keys of the copied objects
+func copyVersionContent(
+	tx *sql.Tx, blobRepo rinterface.BlobRepository, versionRepo rinterface.FileVersionRepository,
+	storage _interface.FileStorage, versions []models.FileVersion,
+) ([]string, error) {
+	refs := make(map[string]int)
+	var copiedKeys []string
+	for _, version := range versions {
+		if version.BlobChecksum != "" {
+			refs[version.BlobChecksum]++
+			continue
+		}
+
+		key, err := storage.CopyFile(version.S3URL, version.S3URL)
+		if err != nil {
+			return copiedKeys, fmt.Errorf("failed to copy content of file(%d) version %d: %w", version.FileID, version.Version, err)
+		}
+		copiedKeys = append(copiedKeys, key)
+
+		if err = versionRepo.UpdateFileVersionKey(tx, version.ID, key); err != nil {
+			return copiedKeys, err
+		}
+	}
+
+	if err := blobRepo.AddBlobReferences(tx, refs); err != nil {
+		return copiedKeys, err
+	}
+	return copiedKeys, nil
+}
+
+// removeObjects removes objects which are not referenced anymore, failures are only logged,
+// a leftover object does not break anything
+func removeObjects(storage _interface.FileStorage, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := storage.DeleteFile(key); err != nil {
+			log.Warn().Msgf("Failed to remove stored file(%s): %s", key, err.Error())
+		}
+	}
+}
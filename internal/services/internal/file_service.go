@@ -2,6 +2,7 @@ package internal
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	"github.com/rs/zerolog/log"
@@ -11,13 +12,44 @@ import (
 )
 
 type fileService struct {
-	fileRepo   _interface.FileRepository
-	folderRepo _interface.FolderRepository
-	db         *sql.DB
+	fileRepo    _interface.FileRepository
+	folderRepo  _interface.FolderRepository
+	blobRepo    _interface.BlobRepository
+	trashRepo   _interface.TrashRepository
+	versionRepo _interface.FileVersionRepository
+	userRepo    _interface.UserRepository
+	// transactionRepo keeps the transaction of the upload open until the file is saved
+	transactionRepo _interface.TransactionRepository
+	storage         sinterface.FileStorage
+	db              *sql.DB
+	// maxVersions is the number of versions kept for a file, unless its folder sets another limit
+	maxVersions int
 }
 
-func NewFileService(fileRepo _interface.FileRepository, folderRepo _interface.FolderRepository, db *sql.DB) sinterface.FileService {
-	return &fileService{fileRepo: fileRepo, folderRepo: folderRepo, db: db}
+func NewFileService(
+	fileRepo _interface.FileRepository,
+	folderRepo _interface.FolderRepository,
+	blobRepo _interface.BlobRepository,
+	trashRepo _interface.TrashRepository,
+	versionRepo _interface.FileVersionRepository,
+	userRepo _interface.UserRepository,
+	transactionRepo _interface.TransactionRepository,
+	storage sinterface.FileStorage,
+	db *sql.DB,
+	maxVersions int,
+) sinterface.FileService {
+	return &fileService{
+		fileRepo:        fileRepo,
+		folderRepo:      folderRepo,
+		blobRepo:        blobRepo,
+		trashRepo:       trashRepo,
+		versionRepo:     versionRepo,
+		userRepo:        userRepo,
+		transactionRepo: transactionRepo,
+		storage:         storage,
+		db:              db,
+		maxVersions:     maxVersions,
+	}
 }
 
 // GetFile returns a file from the database
@@ -25,12 +57,87 @@ func (s *fileService) GetFile(fileID int64) (*models.File, error) {
 	return s.fileRepo.GetFileByID(fileID)
 }
 
-// UploadFile uploads a file to a folder, updates folder size if necessary
-func (s *fileService) UploadFile(folderID int64, userID int, name, s3URL string, size int64, transactionID *int64) error {
+// UploadFile creates a record of the uploaded file in a folder, updates folder size if necessary,
+// if the same content is already stored, the uploaded object is removed and the file shares the existing one.
+// The name conflict policy may change the file name, with the replace policy the upload becomes a new version
+// of the existing file, which is returned in the file then. Files uploaded in a transaction can't replace others,
+// a rollback could not bring the replaced content back. Returns the number of bytes the folder size grew by
+// or *models.QuotaError if they do not fit into the quota of the user
+func (s *fileService) UploadFile(file *models.File, policy string) (int64, error) {
+	if err := validateName(file.Name); err != nil {
+		return 0, err
+	}
+	return s.uploadFile(file, policy, nil)
+}
+
+// UploadFileToPath saves the uploaded file at the slash separated path under the root folder of the user like UploadFile,
+// missing folders of the path are created in the same transaction, so a rejected upload leaves none of them.
+// Ignoring case an existing file takes the upload under its own name, so the conflict policy applies
+func (s *fileService) UploadFileToPath(file *models.File, path, policy string) (int64, error) {
+	names, err := splitPath(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(names) == 0 {
+		return 0, fmt.Errorf("path %q has no file name: %w", path, models.ErrInvalidName)
+	}
+	settings, err := s.userRepo.GetUserSettings(file.UserID)
+	if err != nil {
+		return 0, err
+	}
+
+	folders, name := names[:len(names)-1], names[len(names)-1]
+	return s.uploadFile(file, policy, func(tx *sql.Tx) error {
+		match, err := s.folderRepo.ResolvePath(file.UserID, folders, settings.CaseInsensitivePaths)
+		if err != nil {
+			return err
+		}
+		if file.FolderID, err = createMissingFolders(tx, s.folderRepo, file.UserID, folders, match); err != nil {
+			return err
+		}
+
+		file.Name = name
+		if settings.CaseInsensitivePaths {
+			existing, err := s.fileRepo.FindFileByName(file.FolderID, name, true)
+			switch {
+			case err == nil:
+				file.Name = existing.Name
+			case !errors.Is(err, models.ErrNotFound):
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// saves the uploaded file, locate chooses the folder and the name of the file in the transaction which saves it,
+// if it is set
+func (s *fileService) uploadFile(file *models.File, policy string, locate func(tx *sql.Tx) error) (int64, error) {
+	uploaded := *file
+	var added int64
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		*file = uploaded
+		added, orphanKeys, err = s.createFile(file, policy, locate)
+		return err
+	})
+	if err != nil {
+		*file = uploaded
+		return 0, err
+	}
+
+	if file.S3URL != uploaded.S3URL {
+		removeObjects(s.storage, uploaded.S3URL)
+	}
+	removeObjects(s.storage, orphanKeys...)
+	return added, nil
+}
+
+func (s *fileService) createFile(file *models.File, policy string, locate func(tx *sql.Tx) error) (added int64, orphanKeys []string, err error) {
 	// start a transaction
 	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	// transaction rollback in case of error
@@ -38,32 +145,136 @@ func (s *fileService) UploadFile(folderID int64, userID int, name, s3URL string,
 		err = handleTxEnd(tx, err)
 	}()
 
-	file := &models.File{
-		FolderID:      folderID,
-		UserID:        userID,
-		Name:          name,
-		S3URL:         s3URL,
-		Size:          size,
-		TransactionID: transactionID,
+	// the folder sizes of files uploaded in a transaction are updated when it completes
+	inTransaction := file.TransactionID != nil
+	if inTransaction {
+		// the transaction can't be completed or stopped until the file is saved
+		if err = s.checkUploadTransaction(tx, file); err != nil {
+			return 0, nil, err
+		}
 	}
 
-	// create file in db
-	if err = s.fileRepo.CreateFile(tx, file); err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+	// the user is locked before anything is saved, so the usage does not include the upload yet,
+	// even if it is made in a transaction and counted as pending
+	quota, err := s.userRepo.LockUserQuota(tx, file.UserID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// folders created for the file are removed with the transaction if the file is rejected
+	if locate != nil {
+		if err = locate(tx); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// free the name or choose another one
+	name, existing, err := s.resolveFileName(tx, file.FolderID, file.Name, 0, policy)
+	if err != nil {
+		return 0, nil, err
+	}
+	if existing != nil && inTransaction {
+		return 0, nil, fmt.Errorf("file %q can't be replaced in a transaction: %w", name, models.ErrNameConflict)
+	}
+	file.Name = name
+
+	// reference the stored content
+	if err = acquireFileContent(tx, s.blobRepo, file); err != nil {
+		return 0, nil, err
+	}
+
+	if existing != nil {
+		// the upload replaces the content of the existing file, the previous content is kept as a version
+		if added, orphanKeys, err = s.addFileVersion(tx, existing, file); err != nil {
+			return 0, nil, err
+		}
+		*file = *existing
+	} else {
+		// create file in db
+		if err = s.fileRepo.CreateFile(tx, file); err != nil {
+			return 0, nil, fmt.Errorf("failed to create file: %w", err)
+		}
+		added = file.Size
+	}
+
+	if err = quota.Check(added); err != nil {
+		return 0, nil, err
 	}
 
 	// if single file was added - update the size of folder and parent folders
-	if transactionID == nil {
-		if err = s.folderRepo.IncreaseFolderSize(tx, folderID, size); err != nil {
-			return fmt.Errorf("failed to increase folder size: %w", err)
+	if !inTransaction {
+		if err = s.folderRepo.IncreaseFolderSize(tx, file.FolderID, added); err != nil {
+			return 0, nil, fmt.Errorf("failed to increase folder size: %w", err)
 		}
+	} else if err = s.folderRepo.CheckFolderSize(tx, file.FolderID, added); err != nil {
+		return 0, nil, err
 	}
 
+	return added, orphanKeys, nil
+}
+
+// checks that the upload transaction of the file belongs to its user and folder and is still open,
+// keeps the status of the transaction until the end of the database transaction
+func (s *fileService) checkUploadTransaction(tx *sql.Tx, file *models.File) error {
+	transaction, err := s.transactionRepo.ShareTransaction(tx, *file.TransactionID)
+	if err != nil {
+		return err
+	}
+	if transaction.UserID != file.UserID || transaction.FolderID != file.FolderID {
+		return fmt.Errorf("transaction %d not found: %w", transaction.ID, models.ErrNotFound)
+	}
+	if !transaction.Open() {
+		return &models.TransactionStatusError{ID: transaction.ID, Status: transaction.Status}
+	}
 	return nil
 }
 
-// DeleteFile deletes a file and updates the folder size
-func (s *fileService) DeleteFile(id int64) error {
+// addFileVersion makes the content the current version of the file, the previous content is kept as a version
+// and the oldest versions over the limit of the folder are deleted. Returns the number of bytes the file grew by,
+// which may be negative, and keys of the objects which are not referenced anymore
+func (s *fileService) addFileVersion(tx *sql.Tx, file *models.File, content *models.File) (int64, []string, error) {
+	previous := file.CurrentVersion()
+	if err := s.versionRepo.CreateFileVersion(tx, &previous); err != nil {
+		return 0, nil, err
+	}
+
+	maxVersions, err := s.folderRepo.GetFolderMaxVersions(tx, file.FolderID)
+	if err != nil {
+		return 0, nil, err
+	}
+	keep := s.maxVersions
+	if maxVersions != nil {
+		keep = *maxVersions
+	}
+
+	// the new content is one of the kept versions
+	pruned, err := s.versionRepo.DeleteOldFileVersions(tx, file.ID, keep-1)
+	if err != nil {
+		return 0, nil, err
+	}
+	orphanKeys, err := releaseVersionsContent(tx, s.blobRepo, pruned)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	sizeBefore := file.TotalSize()
+	file.VersionsSize += previous.Size
+	for _, version := range pruned {
+		file.VersionsSize -= version.Size
+	}
+	file.Version++
+	file.S3URL, file.Size = content.S3URL, content.Size
+	file.Checksum, file.MD5, file.BlobChecksum = content.Checksum, content.MD5, content.BlobChecksum
+	if err = s.fileRepo.UpdateFileContent(tx, file); err != nil {
+		return 0, nil, err
+	}
+
+	return file.TotalSize() - sizeBefore, orphanKeys, nil
+}
+
+// DeleteFile moves a file to the trash and updates the folder size, unless the file was uploaded in an unfinished
+// transaction, the file is deleted permanently when the trash purges it
+func (s *fileService) DeleteFile(id int64) (err error) {
 	// start a transaction
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -75,26 +286,180 @@ func (s *fileService) DeleteFile(id int64) error {
 		err = handleTxEnd(tx, err)
 	}()
 
-	// get file data
-	file, err := s.fileRepo.GetFileByID(id)
+	file, counted, err := s.lockCountedFile(tx, id)
+	if err != nil {
+		return err
+	}
+
+	item := &models.TrashItem{
+		UserID:         file.UserID,
+		ItemType:       models.ItemTypeFile,
+		ItemID:         file.ID,
+		Name:           file.Name,
+		ParentFolderID: file.FolderID,
+		Size:           file.TotalSize(),
+	}
+	if err = s.trashRepo.CreateTrashItem(tx, item); err != nil {
+		return err
+	}
+	if err = s.fileRepo.TrashFile(tx, file.ID, item.ID); err != nil {
+		return err
+	}
+
+	if !counted {
+		return nil
+	}
+	return s.folderRepo.DecreaseFolderSize(tx, file.FolderID, file.TotalSize())
+}
+
+// locks the file and keeps the status of its upload transaction, reports whether the folder sizes count the file,
+// files of an unfinished transaction are added to them when it completes. The transaction is locked before the file
+// in the order the rollback of the transaction locks them
+func (s *fileService) lockCountedFile(tx *sql.Tx, id int64) (*models.File, bool, error) {
+	transaction, err := s.transactionRepo.ShareFileTransaction(tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	file, err := s.fileRepo.LockFile(tx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	return file, transaction == nil || !transaction.Unfinished(), nil
+}
+
+// RestoreFile brings a file deleted with the trash item back into the folder it was deleted from,
+// or into the folder if that one is gone. Returns the restored file
+func (s *fileService) RestoreFile(trashID, folderID int64, policy string) (*models.File, error) {
+	var id int64
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		id, orphanKeys, err = s.restoreFile(trashID, folderID, policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return s.fileRepo.GetFileByID(id)
+}
+
+func (s *fileService) restoreFile(trashID, folderID int64, policy string) (id int64, orphanKeys []string, err error) {
+	// start a transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// transaction rollback in case of error
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	item, err := s.trashRepo.LockTrashItem(tx, trashID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if item.ItemType != models.ItemTypeFile {
+		return 0, nil, fmt.Errorf("trash item(%d) is not a file: %w", trashID, models.ErrNotFound)
+	}
+
+	// the restored file takes space of the user again
+	quota, err := s.userRepo.LockUserQuota(tx, item.UserID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err = quota.Check(item.Size); err != nil {
+		return 0, nil, err
+	}
+
+	target, err := restoreTarget(s.folderRepo, item, folderID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// free the name in the target folder or choose another one
+	name, replaced, err := s.resolveFileName(tx, target, item.Name, item.ItemID, policy)
+	if err != nil {
+		return 0, nil, err
+	}
+	if replaced != nil {
+		if orphanKeys, err = s.removeFile(tx, replaced); err != nil {
+			return 0, nil, fmt.Errorf("failed to replace file: %w", err)
+		}
+	}
+
+	if err = s.fileRepo.RestoreFile(tx, item.ID, target, name); err != nil {
+		return 0, nil, err
+	}
+	if err = s.trashRepo.DeleteTrashItem(tx, item.ID); err != nil {
+		return 0, nil, err
+	}
+
+	// a file of an unfinished upload transaction is added to the folder sizes when it completes
+	transaction, err := s.transactionRepo.ShareFileTransaction(tx, item.ItemID)
 	if err != nil {
-		return fmt.Errorf("failed to get file by ID: %w", err)
+		return 0, nil, err
+	}
+	if transaction != nil && transaction.Unfinished() {
+		return item.ItemID, orphanKeys, nil
 	}
+	if err = s.folderRepo.IncreaseFolderSize(tx, target, item.Size); err != nil {
+		return 0, nil, fmt.Errorf("failed to increase folder size: %w", err)
+	}
+	return item.ItemID, orphanKeys, nil
+}
 
-	// remove the file
-	if err = s.fileRepo.DeleteFile(tx, id); err != nil {
-		return fmt.Errorf("failed to delete file: %w", err)
+// permanently removes the file record with all its versions, releases the content and updates the folder size
+// unless the file was uploaded in an unfinished transaction, returns keys of the objects which are not referenced anymore
+func (s *fileService) removeFile(tx *sql.Tx, file *models.File) ([]string, error) {
+	transaction, err := s.transactionRepo.ShareFileTransaction(tx, file.ID)
+	if err != nil {
+		return nil, err
+	}
+	if err = s.fileRepo.DeleteFile(tx, file.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	orphanKeys, err := releaseFilesContent(tx, s.blobRepo, s.versionRepo, []models.File{*file})
+	if err != nil {
+		return nil, err
 	}
 
-	return s.folderRepo.DecreaseFolderSize(tx, file.FolderID, file.Size)
+	if transaction != nil && transaction.Unfinished() {
+		return orphanKeys, nil
+	}
+	if err = s.folderRepo.DecreaseFolderSize(tx, file.FolderID, file.TotalSize()); err != nil {
+		return nil, err
+	}
+	return orphanKeys, nil
+}
+
+// GetStorageStats returns logical and physical size of all stored files
+func (s *fileService) GetStorageStats() (*models.StorageStats, error) {
+	return s.blobRepo.GetStorageStats()
 }
 
 // MoveFile moves a file to a new folder and updates the size of both folders.
-func (s *fileService) MoveFile(fileID, folderID, newFolderID int64) error {
+func (s *fileService) MoveFile(fileID, folderID, newFolderID int64, policy string) error {
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		orphanKeys, err = s.moveFile(fileID, folderID, newFolderID, policy)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return nil
+}
+
+func (s *fileService) moveFile(fileID, folderID, newFolderID int64, policy string) (orphanKeys []string, err error) {
 	// start a transaction
 	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	// transaction rollback in case of error
@@ -102,25 +467,358 @@ func (s *fileService) MoveFile(fileID, folderID, newFolderID int64) error {
 		err = handleTxEnd(tx, err)
 	}()
 
-	// get file with data
-	file, err := s.fileRepo.GetFileByID(fileID)
+	file, counted, err := s.lockCountedFile(tx, fileID)
 	if err != nil {
-		return fmt.Errorf("failed to get file by ID: %w", err)
+		return nil, err
+	}
+
+	// files are moved only between folders of the same user
+	destination, err := s.folderRepo.GetFolderByID(newFolderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+	if destination.UserID != file.UserID {
+		return nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+	}
+
+	// free the name in the new folder or choose another one
+	name, replaced, err := s.resolveFileName(tx, newFolderID, file.Name, file.ID, policy)
+	if err != nil {
+		return nil, err
+	}
+	if replaced != nil {
+		if orphanKeys, err = s.removeFile(tx, replaced); err != nil {
+			return nil, fmt.Errorf("failed to replace file: %w", err)
+		}
 	}
 
 	// change file folder
-	err = s.fileRepo.MoveFile(tx, fileID, newFolderID)
+	err = s.fileRepo.MoveFile(tx, fileID, newFolderID, name)
 	if err != nil {
-		return fmt.Errorf("failed to move file: %w", err)
+		return nil, fmt.Errorf("failed to move file: %w", err)
 	}
 
-	// decrease the old folder size
-	if err = s.folderRepo.DecreaseFolderSize(tx, folderID, file.Size); err != nil {
-		return fmt.Errorf("failed to decrease old folder size: %w", err)
+	// a file of an unfinished upload transaction is added to the size of its folder when the transaction completes
+	if !counted {
+		return orphanKeys, nil
+	}
+
+	// decrease the old folder size, versions are moved with the file
+	if err = s.folderRepo.DecreaseFolderSize(tx, folderID, file.TotalSize()); err != nil {
+		return nil, fmt.Errorf("failed to decrease old folder size: %w", err)
 	}
 
 	// increase the new folder size
-	return s.folderRepo.IncreaseFolderSize(tx, newFolderID, file.Size)
+	if err = s.folderRepo.IncreaseFolderSize(tx, newFolderID, file.TotalSize()); err != nil {
+		return nil, err
+	}
+	return orphanKeys, nil
+}
+
+// CopyFile copies a file with all its versions into the folder and returns the copy, the copy shares
+// the stored content with the source file when it is deduplicated, otherwise the object is copied
+func (s *fileService) CopyFile(fileID, newFolderID int64, policy string) (*models.File, error) {
+	var file *models.File
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		var copiedKeys []string
+		file, copiedKeys, orphanKeys, err = s.copyFile(fileID, newFolderID, policy)
+		if err != nil {
+			removeObjects(s.storage, copiedKeys...)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return file, nil
+}
+
+func (s *fileService) copyFile(fileID, newFolderID int64, policy string) (file *models.File, copiedKeys, orphanKeys []string, err error) {
+	source, err := s.fileRepo.GetFileByID(fileID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get file by ID: %w", err)
+	}
+
+	// files are copied only between folders of the same user
+	destination, err := s.folderRepo.GetFolderByID(newFolderID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+	if destination.UserID != source.UserID {
+		return nil, nil, nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+	}
+
+	// start a transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// transaction rollback in case of error
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	// the copy with all its versions is counted by the quota, even if it shares the stored content
+	quota, err := s.userRepo.LockUserQuota(tx, source.UserID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if err = quota.Check(source.TotalSize()); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// free the name in the folder or choose another one
+	name, replaced, err := s.resolveFileName(tx, newFolderID, source.Name, 0, policy)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if replaced != nil {
+		if replaced.ID == source.ID {
+			return nil, nil, nil, fmt.Errorf("file %q can't be replaced by its copy: %w", name, models.ErrNameConflict)
+		}
+		if orphanKeys, err = s.removeFile(tx, replaced); err != nil {
+			return nil, nil, nil, fmt.Errorf("failed to replace file: %w", err)
+		}
+	}
+
+	file = &models.File{
+		FolderID:     newFolderID,
+		UserID:       source.UserID,
+		Name:         name,
+		S3URL:        source.S3URL,
+		Size:         source.Size,
+		Checksum:     source.Checksum,
+		MD5:          source.MD5,
+		BlobChecksum: source.BlobChecksum,
+		Version:      source.Version,
+		VersionsSize: source.VersionsSize,
+	}
+	if err = s.fileRepo.CreateFile(tx, file); err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	files := []models.File{*file}
+	copiedKeys, err = copyFileContent(tx, s.blobRepo, s.fileRepo, s.storage, files)
+	if err != nil {
+		return nil, copiedKeys, nil, err
+	}
+	file.S3URL = files[0].S3URL
+
+	versions, err := s.versionRepo.CopyFileVersions(tx, source.ID, file.ID)
+	if err != nil {
+		return nil, copiedKeys, nil, err
+	}
+	versionKeys, err := copyVersionContent(tx, s.blobRepo, s.versionRepo, s.storage, versions)
+	copiedKeys = append(copiedKeys, versionKeys...)
+	if err != nil {
+		return nil, copiedKeys, nil, err
+	}
+
+	if err = s.folderRepo.IncreaseFolderSize(tx, newFolderID, file.TotalSize()); err != nil {
+		return nil, copiedKeys, nil, fmt.Errorf("failed to increase folder size: %w", err)
+	}
+
+	return file, copiedKeys, orphanKeys, nil
+}
+
+// RenameFile changes the name of a file in its folder and returns the renamed file
+func (s *fileService) RenameFile(fileID int64, name, policy string) (*models.File, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	var file *models.File
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		file, orphanKeys, err = s.renameFile(fileID, name, policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return file, nil
+}
+
+func (s *fileService) renameFile(fileID int64, name, policy string) (file *models.File, orphanKeys []string, err error) {
+	file, err = s.fileRepo.GetFileByID(fileID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get file by ID: %w", err)
+	}
+
+	// start a transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// transaction rollback in case of error
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	name, replaced, err := s.resolveFileName(tx, file.FolderID, name, file.ID, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if replaced != nil {
+		if orphanKeys, err = s.removeFile(tx, replaced); err != nil {
+			return nil, nil, fmt.Errorf("failed to replace file: %w", err)
+		}
+	}
+
+	if err = s.fileRepo.RenameFile(tx, file.ID, name); err != nil {
+		return nil, nil, err
+	}
+
+	file.Name = name
+	return file, orphanKeys, nil
+}
+
+// ListFileVersions returns the current and all retained previous versions of the file, the newest first
+func (s *fileService) ListFileVersions(fileID int64) ([]models.FileVersion, error) {
+	file, err := s.fileRepo.GetFileByID(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file by ID: %w", err)
+	}
+
+	previous, err := s.versionRepo.ListFileVersions(file.ID)
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.FileVersion{file.CurrentVersion()}, previous...), nil
+}
+
+// GetFileVersion returns the version of the file by its number, the current one included
+func (s *fileService) GetFileVersion(fileID int64, version int) (*models.FileVersion, error) {
+	file, err := s.fileRepo.GetFileByID(fileID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get file by ID: %w", err)
+	}
+
+	if version == file.Version {
+		current := file.CurrentVersion()
+		return &current, nil
+	}
+	return s.versionRepo.GetFileVersion(file.ID, version)
+}
+
+// RestoreFileVersion makes the content of the previous version the new current version of the file,
+// so the history is kept. Returns the updated file
+func (s *fileService) RestoreFileVersion(fileID int64, version int) (*models.File, error) {
+	file, copiedKey, orphanKeys, err := s.restoreFileVersion(fileID, version)
+	if err != nil {
+		removeObjects(s.storage, copiedKey)
+		return nil, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return file, nil
+}
+
+func (s *fileService) restoreFileVersion(fileID int64, version int) (file *models.File, copiedKey string, orphanKeys []string, err error) {
+	// start a transaction
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	// transaction rollback in case of error
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	// the user is locked before the file, as uploads do
+	file, err = s.fileRepo.GetFileByID(fileID)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to get file by ID: %w", err)
+	}
+	quota, err := s.userRepo.LockUserQuota(tx, file.UserID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// versions of the file are changed only while it is locked
+	file, err = s.fileRepo.LockFile(tx, fileID)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if version == file.Version {
+		return file, "", nil, nil
+	}
+
+	restored, err := s.versionRepo.GetFileVersion(file.ID, version)
+	if err != nil {
+		return nil, "", nil, err
+	}
+
+	// the restored content gets one more reference, an object without blob is copied
+	content := &models.File{
+		S3URL:        restored.S3URL,
+		Size:         restored.Size,
+		Checksum:     restored.Checksum,
+		MD5:          restored.MD5,
+		BlobChecksum: restored.BlobChecksum,
+	}
+	if content.BlobChecksum != "" {
+		err = s.blobRepo.AddBlobReferences(tx, map[string]int{content.BlobChecksum: 1})
+	} else {
+		copiedKey, err = s.storage.CopyFile(restored.S3URL, file.Name)
+		content.S3URL = copiedKey
+	}
+	if err != nil {
+		return nil, copiedKey, nil, fmt.Errorf("failed to copy content of version %d: %w", version, err)
+	}
+
+	added, orphanKeys, err := s.addFileVersion(tx, file, content)
+	if err != nil {
+		return nil, copiedKey, nil, err
+	}
+
+	if err = quota.Check(added); err != nil {
+		return nil, copiedKey, nil, err
+	}
+
+	if err = s.folderRepo.IncreaseFolderSize(tx, file.FolderID, added); err != nil {
+		return nil, copiedKey, nil, fmt.Errorf("failed to increase folder size: %w", err)
+	}
+	return file, copiedKey, orphanKeys, nil
+}
+
+// resolveFileName applies the conflict policy to the file put into the folder under the name,
+// returns the name to use and the file to be replaced, if there is one. fileID is the id of
+// the file which is put, a file does not conflict with itself, 0 for a new file
+func (s *fileService) resolveFileName(
+	tx *sql.Tx, folderID int64, name string, fileID int64, policy string,
+) (string, *models.File, error) {
+	existing, err := s.fileRepo.GetFileByName(tx, folderID, name)
+	if errors.Is(err, models.ErrNotFound) {
+		return name, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if existing.ID == fileID {
+		return name, nil, nil
+	}
+
+	switch policy {
+	case models.ConflictRename:
+		taken, err := s.fileRepo.GetNamesWithPrefix(tx, folderID, suffixedNamesPrefix(name, true))
+		if err != nil {
+			return "", nil, err
+		}
+		return freeName(name, true, taken), nil, nil
+	case models.ConflictReplace:
+		return name, existing, nil
+	default:
+		return "", nil, fmt.Errorf("file %q already exists: %w", name, models.ErrNameConflict)
+	}
 }
 
 // handleTxEnd handles the end of a transaction, committing if no error, rolling back otherwise
@@ -0,0 +1,142 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+// errFolderCreated is returned when a concurrent request created a folder of the path first,
+// the next attempt finds the folder
+var errFolderCreated = errors.New("folder was created concurrently")
+
+// ResolvePath returns the folder or the file at the slash separated path under the root folder of the user,
+// a folder is preferred to a file with the same name. Names match ignoring case if the user chose so
+func (s *folderService) ResolvePath(userID int, path string) (*models.PathItem, error) {
+	names, err := splitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := s.userRepo.GetUserSettings(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	match, err := s.folderRepo.ResolvePath(userID, names, settings.CaseInsensitivePaths)
+	if err != nil {
+		return nil, err
+	}
+
+	switch match.Depth {
+	case len(names):
+		folder, err := s.folderRepo.GetFolderByID(match.FolderID)
+		if err != nil {
+			return nil, err
+		}
+		return &models.PathItem{Folder: folder}, nil
+	case len(names) - 1:
+		file, err := s.fileRepo.FindFileByName(match.FolderID, names[len(names)-1], settings.CaseInsensitivePaths)
+		if err != nil {
+			return nil, err
+		}
+		return &models.PathItem{File: file}, nil
+	default:
+		return nil, fmt.Errorf("path %q not found: %w", path, models.ErrNotFound)
+	}
+}
+
+// MakeFolderPath returns the folder at the path under the root folder of the user, missing folders on the way
+// are created like with "mkdir -p". Reports whether any folder was created
+func (s *folderService) MakeFolderPath(userID int, path string) (*models.Folder, bool, error) {
+	names, err := splitPath(path)
+	if err != nil {
+		return nil, false, err
+	}
+	settings, err := s.userRepo.GetUserSettings(userID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	id, created, err := s.makeFolderPath(userID, names, settings.CaseInsensitivePaths)
+	if err != nil {
+		return nil, false, err
+	}
+	folder, err := s.folderRepo.GetFolderByID(id)
+	if err != nil {
+		return nil, false, err
+	}
+	return folder, created, nil
+}
+
+// resolves the names and creates the missing folders, repeats it when a concurrent request
+// creates one of them first
+func (s *folderService) makeFolderPath(userID int, names []string, caseInsensitive bool) (id int64, created bool, err error) {
+	for attempt := 0; attempt < nameConflictAttempts; attempt++ {
+		id, created, err = s.makeFolders(userID, names, caseInsensitive)
+		if !errors.Is(err, errFolderCreated) {
+			break
+		}
+	}
+	return id, created, err
+}
+
+// creates the folders of the names missing under the deepest existing one in a single transaction
+func (s *folderService) makeFolders(userID int, names []string, caseInsensitive bool) (id int64, created bool, err error) {
+	match, err := s.folderRepo.ResolvePath(userID, names, caseInsensitive)
+	if err != nil {
+		return 0, false, err
+	}
+	if match.Depth == len(names) {
+		return match.FolderID, false, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	id, err = createMissingFolders(tx, s.folderRepo, userID, names, match)
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// creates the folders of the names below the deepest existing one of the match in the transaction,
+// returns the last one, errFolderCreated is returned if a concurrent request created one of them first
+func createMissingFolders(tx *sql.Tx, folderRepo rinterface.FolderRepository, userID int, names []string, match *models.PathMatch) (int64, error) {
+	id := match.FolderID
+	for _, name := range names[match.Depth:] {
+		var err error
+		if id, err = folderRepo.CreateFolder(tx, userID, name, id); err != nil {
+			if errors.Is(err, models.ErrNameConflict) {
+				return 0, fmt.Errorf("%w: %w", errFolderCreated, err)
+			}
+			return 0, err
+		}
+	}
+	return id, nil
+}
+
+// splitPath splits the slash separated path into names, empty names of repeated slashes are skipped
+func splitPath(path string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name == "" {
+			continue
+		}
+		if err := validateName(name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -2,6 +2,7 @@ package internal
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 
 	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
@@ -10,35 +11,123 @@ import (
 )
 
 type folderService struct {
-	fileRepo   rinterface.FileRepository
-	folderRepo rinterface.FolderRepository
-	db         *sql.DB
+	fileRepo    rinterface.FileRepository
+	folderRepo  rinterface.FolderRepository
+	blobRepo    rinterface.BlobRepository
+	trashRepo   rinterface.TrashRepository
+	versionRepo rinterface.FileVersionRepository
+	userRepo    rinterface.UserRepository
+	storage     _interface.FileStorage
+	db          *sql.DB
 }
 
 // NewFolderService creates a new FolderService
-func NewFolderService(folderRepo rinterface.FolderRepository, fileRepo rinterface.FileRepository, db *sql.DB) _interface.FolderService {
-	return &folderService{folderRepo: folderRepo, fileRepo: fileRepo, db: db}
+func NewFolderService(
+	folderRepo rinterface.FolderRepository,
+	fileRepo rinterface.FileRepository,
+	blobRepo rinterface.BlobRepository,
+	trashRepo rinterface.TrashRepository,
+	versionRepo rinterface.FileVersionRepository,
+	userRepo rinterface.UserRepository,
+	storage _interface.FileStorage,
+	db *sql.DB,
+) _interface.FolderService {
+	return &folderService{
+		folderRepo:  folderRepo,
+		fileRepo:    fileRepo,
+		blobRepo:    blobRepo,
+		trashRepo:   trashRepo,
+		versionRepo: versionRepo,
+		userRepo:    userRepo,
+		storage:     storage,
+		db:          db,
+	}
 }
 
-// CreateFolder creates a new folder and returns its id
-func (s *folderService) CreateFolder(userID int, name string, parentFolderID int64) (int64, error) {
-	newFolderID, err := s.folderRepo.CreateFolder(userID, name, parentFolderID)
+// CreateFolder creates a new folder and returns its id,
+// policy defines what happens if the name is already taken in the parent folder
+func (s *folderService) CreateFolder(userID int, name string, parentFolderID int64, policy string) (int64, error) {
+	if err := validateName(name); err != nil {
+		return 0, err
+	}
+
+	var newFolderID int64
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		newFolderID, orphanKeys, err = s.createFolder(userID, name, parentFolderID, policy)
+		return err
+	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to create folder: %w", err)
 	}
+
+	removeObjects(s.storage, orphanKeys...)
 	return newFolderID, nil
 }
 
+func (s *folderService) createFolder(userID int, name string, parentFolderID int64, policy string) (id int64, orphanKeys []string, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	name, replaced, err := s.resolveFolderName(tx, parentFolderID, name, 0, policy)
+	if err != nil {
+		return 0, nil, err
+	}
+	if replaced != nil {
+		if orphanKeys, err = s.removeFolder(tx, replaced); err != nil {
+			return 0, nil, fmt.Errorf("failed to replace folder: %w", err)
+		}
+	}
+
+	id, err = s.folderRepo.CreateFolder(tx, userID, name, parentFolderID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return id, orphanKeys, nil
+}
+
 // MoveFolder moves a folder to a new parent folder and updates folder sizes accordingly
-func (s *folderService) MoveFolder(folderID, newFolderID int64) error {
+func (s *folderService) MoveFolder(folderID, newFolderID int64, policy string) error {
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		orphanKeys, err = s.moveFolder(folderID, newFolderID, policy)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return nil
+}
+
+func (s *folderService) moveFolder(folderID, newFolderID int64, policy string) (orphanKeys []string, err error) {
 	folder, err := s.folderRepo.GetFolderByID(folderID)
 	if err != nil {
-		return fmt.Errorf("failed to get folder by ID: %w", err)
+		return nil, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+	if folder.ParentFolderID == nil {
+		return nil, models.ErrRootFolder
+	}
+
+	// folders are moved only between folders of the same user
+	destination, err := s.folderRepo.GetFolderByID(newFolderID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+	if destination.UserID != folder.UserID {
+		return nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
 	}
 
 	tx, err := s.db.Begin()
 	if err != nil {
-		return fmt.Errorf("failed to begin transaction: %w", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	defer func() {
@@ -47,29 +136,242 @@ func (s *folderService) MoveFolder(folderID, newFolderID int64) error {
 
 	oldFolderID := *folder.ParentFolderID
 
+	// free the name in the new parent folder or choose another one
+	name, replaced, err := s.resolveFolderName(tx, newFolderID, folder.Name, folder.ID, policy)
+	if err != nil {
+		return nil, err
+	}
+	if replaced != nil {
+		// the replaced folder can't be removed together with the moved one
+		ancestors, err := s.folderRepo.GetAllParentFolders(folderID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get folder ancestors: %w", err)
+		}
+		if containsFolder(ancestors, replaced.ID) {
+			return nil, fmt.Errorf("folder %q contains the moved folder: %w", replaced.Name, models.ErrNameConflict)
+		}
+
+		if orphanKeys, err = s.removeFolder(tx, replaced); err != nil {
+			return nil, fmt.Errorf("failed to replace folder: %w", err)
+		}
+	}
+
 	// move the folder
-	if err = s.folderRepo.MoveFolder(tx, folderID, newFolderID); err != nil {
-		return fmt.Errorf("failed to move folder: %w", err)
+	if err = s.folderRepo.MoveFolder(tx, folderID, newFolderID, name); err != nil {
+		return nil, fmt.Errorf("failed to move folder: %w", err)
 	}
 
 	// update sizes of the old and new parent folders
 	if err = s.folderRepo.DecreaseFolderSize(tx, oldFolderID, folder.Size); err != nil {
-		return fmt.Errorf("failed to decrease old folder size: %w", err)
+		return nil, fmt.Errorf("failed to decrease old folder size: %w", err)
 	}
 
 	if err = s.folderRepo.IncreaseFolderSize(tx, newFolderID, folder.Size); err != nil {
-		return fmt.Errorf("failed to increase new folder size: %w", err)
+		return nil, fmt.Errorf("failed to increase new folder size: %w", err)
 	}
 
-	return nil
+	return orphanKeys, nil
+}
+
+// CopyFolder copies a folder with all subfolders and files into the new parent folder and returns the copy,
+// everything is copied in one transaction, copied files share the stored content with the source files
+func (s *folderService) CopyFolder(folderID, newFolderID int64, policy string) (*models.Folder, error) {
+	var id int64
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		var copiedKeys []string
+		id, copiedKeys, orphanKeys, err = s.copyFolder(folderID, newFolderID, policy)
+		if err != nil {
+			removeObjects(s.storage, copiedKeys...)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return s.folderRepo.GetFolderByID(id)
+}
+
+func (s *folderService) copyFolder(folderID, newFolderID int64, policy string) (id int64, copiedKeys, orphanKeys []string, err error) {
+	source, err := s.folderRepo.GetFolderByID(folderID)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+
+	// folders are copied only between folders of the same user
+	destination, err := s.folderRepo.GetFolderByID(newFolderID)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+	if destination.UserID != source.UserID {
+		return 0, nil, nil, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+	}
+
+	// the folder can't be copied into itself
+	destinationAncestors, err := s.folderRepo.GetAllParentFolders(newFolderID)
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to get folder ancestors: %w", err)
+	}
+	if containsFolder(destinationAncestors, folderID) {
+		return 0, nil, nil, fmt.Errorf("folder %q can't be copied into itself: %w", source.Name, models.ErrInvalidDestination)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	// the copy is counted by the quota, even if its files share the stored content
+	quota, err := s.userRepo.LockUserQuota(tx, source.UserID)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if err = quota.Check(source.Size); err != nil {
+		return 0, nil, nil, err
+	}
+
+	// free the name in the new parent folder or choose another one
+	name, replaced, err := s.resolveFolderName(tx, newFolderID, source.Name, 0, policy)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	if replaced != nil {
+		// the replaced folder can't be removed together with the copied one
+		ancestors, err := s.folderRepo.GetAllParentFolders(folderID)
+		if err != nil {
+			return 0, nil, nil, fmt.Errorf("failed to get folder ancestors: %w", err)
+		}
+		if containsFolder(ancestors, replaced.ID) {
+			return 0, nil, nil, fmt.Errorf("folder %q contains the copied folder: %w", replaced.Name, models.ErrNameConflict)
+		}
+
+		if orphanKeys, err = s.removeFolder(tx, replaced); err != nil {
+			return 0, nil, nil, fmt.Errorf("failed to replace folder: %w", err)
+		}
+	}
+
+	// copy the whole tree, folders first, so files have where to go
+	folders, err := s.folderRepo.CopyFolderTree(tx, folderID, newFolderID, name)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	for _, folder := range folders {
+		if folder.SourceID == folderID {
+			id = folder.ID
+		}
+	}
+
+	files, err := s.fileRepo.CopyFolderTreeFiles(tx, folders)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	copiedKeys, err = copyFileContent(tx, s.blobRepo, s.fileRepo, s.storage, files)
+	if err != nil {
+		return 0, copiedKeys, nil, err
+	}
+
+	// copied files keep their history, folder sizes count it
+	versions, err := s.versionRepo.CopyFolderTreeFileVersions(tx, folders)
+	if err != nil {
+		return 0, copiedKeys, nil, err
+	}
+	versionKeys, err := copyVersionContent(tx, s.blobRepo, s.versionRepo, s.storage, versions)
+	copiedKeys = append(copiedKeys, versionKeys...)
+	if err != nil {
+		return 0, copiedKeys, nil, err
+	}
+
+	// sizes of the copied folders are copied too, only the new parent folders grow
+	if err = s.folderRepo.IncreaseFolderSize(tx, newFolderID, source.Size); err != nil {
+		return 0, copiedKeys, nil, fmt.Errorf("failed to increase new folder size: %w", err)
+	}
+
+	return id, copiedKeys, orphanKeys, nil
+}
+
+// checks whether the folder is in the list
+func containsFolder(folders []models.FolderSizeSimplified, id int64) bool {
+	for _, folder := range folders {
+		if folder.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// RenameFolder changes the name of a folder in its parent folder and returns the renamed folder
+func (s *folderService) RenameFolder(folderID int64, name, policy string) (*models.Folder, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	var folder *models.Folder
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		folder, orphanKeys, err = s.renameFolder(folderID, name, policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return folder, nil
+}
+
+func (s *folderService) renameFolder(folderID int64, name, policy string) (folder *models.Folder, orphanKeys []string, err error) {
+	folder, err = s.folderRepo.GetFolderByID(folderID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+	if folder.ParentFolderID == nil {
+		return nil, nil, fmt.Errorf("root folder can't be renamed: %w", models.ErrInvalidName)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	name, replaced, err := s.resolveFolderName(tx, *folder.ParentFolderID, name, folder.ID, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	if replaced != nil {
+		if orphanKeys, err = s.removeFolder(tx, replaced); err != nil {
+			return nil, nil, fmt.Errorf("failed to replace folder: %w", err)
+		}
+	}
+
+	if err = s.folderRepo.RenameFolder(tx, folder.ID, name); err != nil {
+		return nil, nil, err
+	}
+
+	folder.Name = name
+	return folder, orphanKeys, nil
 }
 
-// DeleteFolder deletes a folder and updates the parent folder size
-func (s *folderService) DeleteFolder(id int64) error {
+// DeleteFolder moves a folder with all subfolders and files to the trash and updates the parent folder size,
+// the folder is deleted permanently when the trash purges it
+func (s *folderService) DeleteFolder(id int64) (err error) {
 	folder, err := s.folderRepo.GetFolderByID(id)
 	if err != nil {
 		return fmt.Errorf("failed to get folder by ID: %w", err)
 	}
+	if folder.ParentFolderID == nil {
+		return models.ErrRootFolder
+	}
 
 	tx, err := s.db.Begin()
 	if err != nil {
@@ -80,17 +382,176 @@ func (s *folderService) DeleteFolder(id int64) error {
 		err = handleTxEnd(tx, err)
 	}()
 
-	if err = s.folderRepo.DeleteFolder(tx, id); err != nil {
-		return fmt.Errorf("failed to delete folder: %w", err)
+	item := &models.TrashItem{
+		UserID:         folder.UserID,
+		ItemType:       models.ItemTypeFolder,
+		ItemID:         folder.ID,
+		Name:           folder.Name,
+		ParentFolderID: *folder.ParentFolderID,
+		Size:           folder.Size,
+	}
+	if err = s.trashRepo.CreateTrashItem(tx, item); err != nil {
+		return err
 	}
 
-	if err = s.folderRepo.DecreaseFolderSize(tx, *folder.ParentFolderID, folder.Size); err != nil {
-		return fmt.Errorf("failed to decrease parent folder size: %w", err)
+	// mark the whole tree as deleted with the trash item
+	if err = s.folderRepo.TrashFolder(tx, folder.ID, item.ID); err != nil {
+		return err
+	}
+	if err = s.fileRepo.TrashFolderFiles(tx, item.ID); err != nil {
+		return err
 	}
 
+	if err = s.folderRepo.DecreaseFolderSize(tx, item.ParentFolderID, folder.Size); err != nil {
+		return fmt.Errorf("failed to decrease parent folder size: %w", err)
+	}
 	return nil
 }
 
+// RestoreFolder brings a folder deleted with the trash item back with all its content, into the folder
+// it was deleted from, or into the parent folder if that one is gone. Returns the restored folder
+func (s *folderService) RestoreFolder(trashID, parentFolderID int64, policy string) (*models.Folder, error) {
+	var id int64
+	var orphanKeys []string
+	err := retryOnNameConflict(policy, func() (err error) {
+		id, orphanKeys, err = s.restoreFolder(trashID, parentFolderID, policy)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return s.folderRepo.GetFolderByID(id)
+}
+
+func (s *folderService) restoreFolder(trashID, parentFolderID int64, policy string) (id int64, orphanKeys []string, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	item, err := s.trashRepo.LockTrashItem(tx, trashID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if item.ItemType != models.ItemTypeFolder {
+		return 0, nil, fmt.Errorf("trash item(%d) is not a folder: %w", trashID, models.ErrNotFound)
+	}
+
+	// the restored folder takes space of the user again
+	quota, err := s.userRepo.LockUserQuota(tx, item.UserID)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err = quota.Check(item.Size); err != nil {
+		return 0, nil, err
+	}
+
+	target, err := restoreTarget(s.folderRepo, item, parentFolderID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// free the name in the target folder or choose another one
+	name, replaced, err := s.resolveFolderName(tx, target, item.Name, item.ItemID, policy)
+	if err != nil {
+		return 0, nil, err
+	}
+	if replaced != nil {
+		if orphanKeys, err = s.removeFolder(tx, replaced); err != nil {
+			return 0, nil, fmt.Errorf("failed to replace folder: %w", err)
+		}
+	}
+
+	if err = s.folderRepo.RestoreFolder(tx, item.ID, item.ItemID, target, name); err != nil {
+		return 0, nil, err
+	}
+	if err = s.fileRepo.RestoreFolderFiles(tx, item.ID); err != nil {
+		return 0, nil, err
+	}
+	if err = s.trashRepo.DeleteTrashItem(tx, item.ID); err != nil {
+		return 0, nil, err
+	}
+
+	if err = s.folderRepo.IncreaseFolderSize(tx, target, item.Size); err != nil {
+		return 0, nil, fmt.Errorf("failed to increase parent folder size: %w", err)
+	}
+	return item.ItemID, orphanKeys, nil
+}
+
+// permanently removes the folder with all subfolders and files, releases the content of the files
+// and updates the parent folder size, returns keys of the objects which are not referenced anymore.
+// Subfolders and files which are already in the trash are left for the trash purge
+func (s *folderService) removeFolder(tx *sql.Tx, folder *models.Folder) ([]string, error) {
+	files, err := s.fileRepo.DeleteFolderTreeFiles(tx, folder.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete folder files: %w", err)
+	}
+
+	orphanKeys, err := releaseFilesContent(tx, s.blobRepo, s.versionRepo, files)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.folderRepo.DeleteFolder(tx, folder.ID); err != nil {
+		return nil, fmt.Errorf("failed to delete folder: %w", err)
+	}
+
+	if err = s.folderRepo.DecreaseFolderSize(tx, *folder.ParentFolderID, folder.Size); err != nil {
+		return nil, fmt.Errorf("failed to decrease parent folder size: %w", err)
+	}
+
+	return orphanKeys, nil
+}
+
+// resolveFolderName applies the conflict policy to the folder put into the parent folder under the name,
+// returns the name to use and the folder to be replaced, if there is one. folderID is the id of
+// the folder which is put, a folder does not conflict with itself, 0 for a new folder
+func (s *folderService) resolveFolderName(
+	tx *sql.Tx, parentID int64, name string, folderID int64, policy string,
+) (string, *models.Folder, error) {
+	existing, err := s.folderRepo.GetFolderByName(tx, parentID, name)
+	if errors.Is(err, models.ErrNotFound) {
+		return name, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if existing.ID == folderID {
+		return name, nil, nil
+	}
+
+	switch policy {
+	case models.ConflictRename:
+		taken, err := s.folderRepo.GetNamesWithPrefix(tx, parentID, suffixedNamesPrefix(name, false))
+		if err != nil {
+			return "", nil, err
+		}
+		return freeName(name, false, taken), nil, nil
+	case models.ConflictReplace:
+		return name, existing, nil
+	default:
+		return "", nil, fmt.Errorf("folder %q already exists: %w", name, models.ErrNameConflict)
+	}
+}
+
+// SetFolderMaxVersions sets how many versions of a file are kept in the folder, nil resets it to the default,
+// files over the limit lose their oldest versions with the next upload
+func (s *folderService) SetFolderMaxVersions(id int64, maxVersions *int) error {
+	return s.folderRepo.UpdateFolderMaxVersions(id, maxVersions)
+}
+
+// SetFolderMaxSize sets the max size of the folder, nil removes the limit. A limit below the current size
+// does not delete anything, but the folder can't grow anymore
+func (s *folderService) SetFolderMaxSize(id int64, maxSize *int64) error {
+	return s.folderRepo.UpdateFolderMaxSize(id, maxSize)
+}
+
 // UpdateFolderSize updates the size of a specified folder
 func (s *folderService) UpdateFolderSize(id, size int64) error {
 	if err := s.folderRepo.UpdateFolderSize(id, size); err != nil {
@@ -99,13 +560,22 @@ func (s *folderService) UpdateFolderSize(id, size int64) error {
 	return nil
 }
 
-// GetAllParentFolders retrieves all parent folders up to the root
-func (s *folderService) GetAllParentFolders(folderID int64) ([]models.FolderSizeSimplified, error) {
-	folders, err := s.folderRepo.GetAllParentFolders(folderID)
+// WalkFolderTree passes the folder of the user with its subtree down to the depth to visit, nodes are not
+// collected, so trees of any size can be streamed
+func (s *folderService) WalkFolderTree(userID int, folderID int64, opts models.TreeOptions, visit func(models.TreeNode) error) error {
+	if err := s.folderRepo.WalkFolderTree(userID, folderID, opts, visit); err != nil {
+		return fmt.Errorf("failed to walk folder tree: %w", err)
+	}
+	return nil
+}
+
+// GetBreadcrumbs retrieves folders on the way from the root folder to the folder
+func (s *folderService) GetBreadcrumbs(folderID int64) ([]models.Breadcrumb, error) {
+	breadcrumbs, err := s.folderRepo.GetBreadcrumbs(folderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get all parent folders: %w", err)
+		return nil, fmt.Errorf("failed to get breadcrumbs: %w", err)
 	}
-	return folders, nil
+	return breadcrumbs, nil
 }
 
 // GetFolderInfo retrieves detailed information about a folder
@@ -117,20 +587,88 @@ func (s *folderService) GetFolderInfo(id int64) ([]models.FolderSize, error) {
 	return info, nil
 }
 
-// UpdateMultipleFoldersSize updates the sizes of multiple folders within a transaction
-func (s *folderService) UpdateMultipleFoldersSize(folders []models.FolderSizeSimplified) error {
-	tx, err := s.db.Begin()
+// ListFolder returns a page of the folder content, subfolders are listed before files,
+// both are sorted by the same key
+func (s *folderService) ListFolder(id int64, opts models.ListOptions) (*models.FolderListing, error) {
+	folder, err := s.folderRepo.GetFolderEntry(id)
 	if err != nil {
-		return fmt.Errorf("failed to start transaction: %w", err)
+		return nil, fmt.Errorf("failed to get folder: %w", err)
 	}
 
-	defer func() {
-		err = handleTxEnd(tx, err)
-	}()
+	listing := &models.FolderListing{Folder: *folder}
+	remaining := opts.Limit
 
-	if err = s.folderRepo.UpdateMultipleFoldersSize(tx, folders); err != nil {
-		return fmt.Errorf("failed to update multiple folders' sizes: %w", err)
+	if opts.Type != models.ItemTypeFile && (opts.After == nil || opts.After.Type == models.ItemTypeFolder) {
+		// one more item shows whether there is a next page
+		page := opts
+		page.Limit = remaining + 1
+		folders, err := s.folderRepo.ListSubfolders(id, page)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list subfolders: %w", err)
+		}
+
+		if len(folders) > remaining {
+			listing.Folders = folders[:remaining]
+			listing.Next = folderCursor(listing.Folders[remaining-1], opts.Sort)
+			return listing, nil
+		}
+		listing.Folders = folders
+		remaining -= len(folders)
+
+		// files are listed from the beginning after the last folder
+		opts.After = nil
 	}
 
-	return nil
+	if opts.Type == models.ItemTypeFolder {
+		return listing, nil
+	}
+
+	page := opts
+	page.Limit = remaining + 1
+	files, err := s.fileRepo.ListFolderFiles(id, page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list folder files: %w", err)
+	}
+
+	if len(files) > remaining {
+		listing.Files = files[:remaining]
+		if remaining == 0 {
+			// the page is filled with folders, files start on the next one
+			listing.Next = &models.ListCursor{Type: models.ItemTypeFile}
+		} else {
+			listing.Next = fileCursor(listing.Files[remaining-1], opts.Sort)
+		}
+		return listing, nil
+	}
+	listing.Files = files
+
+	return listing, nil
+}
+
+// returns the listing position of the folder
+func folderCursor(folder models.FolderEntry, sort string) *models.ListCursor {
+	cursor := &models.ListCursor{Type: models.ItemTypeFolder, ID: folder.ID}
+	switch sort {
+	case models.SortBySize:
+		cursor.Size = folder.Size
+	case models.SortByCreatedAt:
+		cursor.CreatedAt = folder.CreatedAt
+	default:
+		cursor.Name = folder.Name
+	}
+	return cursor
+}
+
+// returns the listing position of the file
+func fileCursor(file models.File, sort string) *models.ListCursor {
+	cursor := &models.ListCursor{Type: models.ItemTypeFile, ID: file.ID}
+	switch sort {
+	case models.SortBySize:
+		cursor.Size = file.Size
+	case models.SortByCreatedAt:
+		cursor.CreatedAt = file.CreatedAt
+	default:
+		cursor.Name = file.Name
+	}
+	return cursor
 }
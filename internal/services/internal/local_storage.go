@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// UploadFile streams the file into the storage directory and returns its object key
+func (s *localStorage) UploadFile(fileData io.Reader, size int64, fileName string) (fileKey string, written int64, err error) {
+	fileKey, err = newObjectKey(fileName)
+	if err != nil {
+		return "", 0, err
+	}
+
+	fullPath, err := s.objectPath(fileKey)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(fullPath), 0o755); err != nil {
+		return "", 0, fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// write into a temporary file first, so a half written object is never visible under its key
+	tmp, err := os.CreateTemp(filepath.Dir(fullPath), ".upload-*")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	if written, err = io.Copy(tmp, fileData); err != nil {
+		return "", 0, fmt.Errorf("failed to write file: %w", err)
+	}
+	if size >= 0 && written != size {
+		err = fmt.Errorf("file size mismatch: expected %d bytes, got %d", size, written)
+		return "", 0, err
+	}
+	if err = tmp.Sync(); err != nil {
+		return "", 0, fmt.Errorf("failed to sync file: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return "", 0, fmt.Errorf("failed to close file: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), fullPath); err != nil {
+		return "", 0, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	return fileKey, written, nil
+}
+
+// OpenFile opens the object from the storage directory
+func (s *localStorage) OpenFile(fileKey string) (io.ReadSeekCloser, error) {
+	fullPath, err := s.objectPath(fileKey)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return file, nil
+}
+
+// GeneratePreSignedURL is not supported, files on a local disk are served only through the app
+func (s *localStorage) GeneratePreSignedURL(fileKey, method string) (preSignedURL string, err error) {
+	return "", errors.New("pre-signed URLs are not supported by the local storage")
+}
+
+// CopyFile writes the content of the object into a new one
+func (s *localStorage) CopyFile(fileKey, fileName string) (string, error) {
+	content, err := s.OpenFile(fileKey)
+	if err != nil {
+		return "", err
+	}
+	defer content.Close()
+
+	newFileKey, _, err := s.UploadFile(content, -1, fileName)
+	if err != nil {
+		return "", err
+	}
+	return newFileKey, nil
+}
+
+// DeleteFile removes the object from the storage directory, missing objects are ignored
+func (s *localStorage) DeleteFile(fileKey string) error {
+	fullPath, err := s.objectPath(fileKey)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(fullPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+	return nil
+}
+
+// converts an object key to the path on disk, keys are not allowed to leave the root directory
+func (s *localStorage) objectPath(fileKey string) (string, error) {
+	cleaned := path.Clean("/" + fileKey)
+	if cleaned == "/" || strings.TrimPrefix(cleaned, "/") != fileKey {
+		return "", fmt.Errorf("invalid object key: %q", fileKey)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(fileKey)), nil
+}
+
+// newObjectKey generates a random key like "ab/cd/abcd...ef.jpg",
+// the first two levels spread objects between directories
+func newObjectKey(fileName string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate object key: %w", err)
+	}
+	id := hex.EncodeToString(buf)
+
+	return fmt.Sprintf("%s/%s/%s%s", id[0:2], id[2:4], id, objectExt(fileName)), nil
+}
+
+// returns a sanitized extension of the file name, it keeps keys readable for the operators
+func objectExt(fileName string) string {
+	ext := strings.ToLower(path.Ext(fileName))
+	if ext == "" || len(ext) > 16 {
+		return ""
+	}
+	for _, r := range ext[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return ext
+}
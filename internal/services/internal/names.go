@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/saur4ig/file-storage/internal/models"
+)
+
+const (
+	// max length of file and folder names, limited by the database columns
+	maxNameLength = 255
+	// how many times an operation is repeated when a concurrent request takes the free name found for it
+	nameConflictAttempts = 3
+)
+
+// validateName checks that the name can be used for a file or a folder
+func validateName(name string) error {
+	switch {
+	case name == "", name == ".", name == "..":
+		return fmt.Errorf("%q is reserved: %w", name, models.ErrInvalidName)
+	case strings.ContainsAny(name, "/\x00"):
+		return fmt.Errorf("%q contains forbidden characters: %w", name, models.ErrInvalidName)
+	case utf8.RuneCountInString(name) > maxNameLength:
+		return fmt.Errorf("name is longer than %d characters: %w", maxNameLength, models.ErrInvalidName)
+	}
+	return nil
+}
+
+// splits the name to the part which gets the conflict suffix and the part kept after it,
+// files keep their extension, so "report.pdf" becomes "report (1).pdf"
+func splitName(name string, isFile bool) (base, ext string) {
+	if !isFile {
+		return name, ""
+	}
+	ext = path.Ext(name)
+	if ext == name {
+		// hidden files like ".env" have no extension
+		return name, ""
+	}
+	return strings.TrimSuffix(name, ext), ext
+}
+
+// returns the prefix of all names which freeName could produce for the name
+func suffixedNamesPrefix(name string, isFile bool) string {
+	base, _ := splitName(name, isFile)
+	return base + " ("
+}
+
+// freeName returns the first name like "report (1).pdf" which is not taken,
+// taken should contain all names of the folder starting with suffixedNamesPrefix
+func freeName(name string, isFile bool, taken []string) string {
+	used := make(map[string]bool, len(taken))
+	for _, n := range taken {
+		used[n] = true
+	}
+
+	base, ext := splitName(name, isFile)
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if !used[candidate] {
+			return candidate
+		}
+	}
+}
+
+// retryOnNameConflict repeats the operation with the rename policy, when a concurrent request
+// took the free name between the lookup and the update, other policies run it once unless
+// a concurrent request created a folder the operation was going to create
+func retryOnNameConflict(policy string, op func() error) error {
+	err := op()
+	for attempt := 1; attempt < nameConflictAttempts; attempt++ {
+		renamed := policy == models.ConflictRename && errors.Is(err, models.ErrNameConflict)
+		if !renamed && !errors.Is(err, errFolderCreated) {
+			break
+		}
+		err = op()
+	}
+	return err
+}
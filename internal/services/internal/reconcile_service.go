@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
+	"github.com/saur4ig/file-storage/internal/models"
+	_interface "github.com/saur4ig/file-storage/internal/services/interface"
+)
+
+type reconcileService struct {
+	folderRepo rinterface.FolderRepository
+	userRepo   rinterface.UserRepository
+	cache      rinterface.FolderSizeCache
+	db         *sql.DB
+}
+
+// NewReconcileService creates a new ReconcileService, which fixes folder sizes in the database and the cache
+func NewReconcileService(
+	folderRepo rinterface.FolderRepository,
+	userRepo rinterface.UserRepository,
+	cache rinterface.FolderSizeCache,
+	db *sql.DB,
+) _interface.ReconcileService {
+	return &reconcileService{
+		folderRepo: folderRepo,
+		userRepo:   userRepo,
+		cache:      cache,
+		db:         db,
+	}
+}
+
+// ReconcileFolderSizes recalculates folder sizes user by user, so folders of only one user are locked at once.
+// A failure of one user does not stop the others, the report contains everything fixed before the error
+func (s *reconcileService) ReconcileFolderSizes(ctx context.Context) (*models.ReconcileReport, error) {
+	userIDs, err := s.userRepo.GetUserIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReconcileReport{}
+	var errs []error
+	for _, userID := range userIDs {
+		if err = ctx.Err(); err != nil {
+			errs = append(errs, err)
+			break
+		}
+
+		checks, err := s.reconcileUser(userID)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user(%d): %w", userID, err))
+			continue
+		}
+		report.Users++
+		report.Folders += len(checks)
+
+		var cached []models.FolderSizeSimplified
+		for _, check := range checks {
+			if check.Recorded != check.Actual {
+				report.Discrepancies = append(report.Discrepancies, models.SizeDiscrepancy{
+					FolderID: check.ID,
+					UserID:   userID,
+					Source:   models.SizeSourceDatabase,
+					Recorded: check.Recorded,
+					Actual:   check.Actual,
+				})
+			}
+			// the cached size of a folder with files of an unfinished transaction includes them already,
+			// the database counts them when the transaction completes
+			if !check.InTransaction {
+				cached = append(cached, models.FolderSizeSimplified{ID: check.ID, Size: check.Actual})
+			}
+		}
+
+		stale, err := s.cache.RefreshFolderSizes(ctx, cached)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("user(%d): %w", userID, err))
+			continue
+		}
+		actual := make(map[int64]int64, len(cached))
+		for _, folder := range cached {
+			actual[folder.ID] = folder.Size
+		}
+		for _, folder := range stale {
+			report.Discrepancies = append(report.Discrepancies, models.SizeDiscrepancy{
+				FolderID: folder.ID,
+				UserID:   userID,
+				Source:   models.SizeSourceCache,
+				Recorded: folder.Size,
+				Actual:   actual[folder.ID],
+			})
+		}
+	}
+
+	return report, errors.Join(errs...)
+}
+
+// recalculates sizes of the folders of the user and fixes the differing ones in the database
+func (s *reconcileService) reconcileUser(userID int) (checks []models.FolderSizeCheck, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	checks, err = s.folderRepo.CalculateFolderSizes(tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixed []models.FolderSizeSimplified
+	for _, check := range checks {
+		if check.Recorded != check.Actual {
+			fixed = append(fixed, models.FolderSizeSimplified{ID: check.ID, Size: check.Actual})
+		}
+	}
+	if err = s.folderRepo.UpdateMultipleFoldersSize(tx, fixed); err != nil {
+		return nil, err
+	}
+	return checks, nil
+}
@@ -1,22 +1,125 @@
 package internal
 
-const MOCKED_URL1 = "https://picsum.photos/100"
-const MOCKED_URL2 = "https://picsum.photos/50"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
 
-// No real file storage is implemented, it's kinda "mocked" methods to simulate working with s3
-// No bucket logic implemented as well, assuming that it should be present in real project.
+	"github.com/minio/minio-go/v7"
+	"github.com/saur4ig/file-storage/internal/models"
+)
 
-// UploadFile responsible for uploading file to s3
-func (s *s3Service) UploadFile(fileData []byte, fileName string) (fileURL string, err error) {
-	return MOCKED_URL1, nil
+// how long the pre-signed URL stays valid
+const preSignedURLExpiry = 15 * time.Minute
+
+// size of the parts of multipart uploads, a part is buffered in memory while it is sent,
+// without it an upload of unknown size buffers the largest part allowed, about 560 MiB.
+// With 10000 parts at most an object can take up to 160 GiB
+const uploadPartSize = 16 << 20
+
+// UploadFile responsible for streaming file to s3, files of unknown size are sent as multipart uploads
+func (s *s3Service) UploadFile(fileData io.Reader, size int64, fileName string) (fileKey string, written int64, err error) {
+	fileKey, err = newObjectKey(fileName)
+	if err != nil {
+		return "", 0, err
+	}
+
+	info, err := s.client.PutObject(context.Background(), s.bucket, fileKey, fileData, size, minio.PutObjectOptions{
+		ContentType: models.ContentType(fileName),
+		PartSize:    uploadPartSize,
+		// the payload is sent unsigned, so the body is streamed as is
+		// instead of being wrapped into aws-chunked encoding
+		DisableContentSha256: true,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to put object: %w", err)
+	}
+
+	return fileKey, info.Size, nil
+}
+
+// OpenFile responsible for reading file from s3, the object is fetched lazily on reads
+func (s *s3Service) OpenFile(fileKey string) (io.ReadSeekCloser, error) {
+	object, err := s.client.GetObject(context.Background(), s.bucket, fileKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object: %w", err)
+	}
+
+	// fail early if the object is missing instead of on the first read
+	if _, err = object.Stat(); err != nil {
+		object.Close()
+		return nil, fmt.Errorf("failed to stat object: %w", err)
+	}
+	return object, nil
+}
+
+// GeneratePreSignedURL responsible for generating a temporary url to download or upload a file
+func (s *s3Service) GeneratePreSignedURL(fileKey, method string) (preSignedURL string, err error) {
+	ctx := context.Background()
+
+	var u fmt.Stringer
+	switch method {
+	case http.MethodGet:
+		u, err = s.client.PresignedGetObject(ctx, s.bucket, fileKey, preSignedURLExpiry, nil)
+	case http.MethodPut:
+		u, err = s.client.PresignedPutObject(ctx, s.bucket, fileKey, preSignedURLExpiry)
+	default:
+		return "", fmt.Errorf("unsupported pre-signed URL method: %s", method)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to generate pre-signed URL: %w", err)
+	}
+
+	return u.String(), nil
+}
+
+// CopyFile responsible for copying file inside the bucket, the content is not downloaded
+func (s *s3Service) CopyFile(fileKey, fileName string) (string, error) {
+	newFileKey, err := newObjectKey(fileName)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.client.CopyObject(context.Background(),
+		minio.CopyDestOptions{Bucket: s.bucket, Object: newFileKey},
+		minio.CopySrcOptions{Bucket: s.bucket, Object: fileKey},
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to copy object: %w", err)
+	}
+	return newFileKey, nil
 }
 
-// GeneratePreSignedURL responsible for reserving url for a file
-func (s *s3Service) GeneratePreSignedURL(fileName string) (preSignedURL string, err error) {
-	return MOCKED_URL2, nil
+// DeleteFile responsible for removing file from storage, missing objects are ignored
+func (s *s3Service) DeleteFile(fileKey string) error {
+	err := s.client.RemoveObject(context.Background(), s.bucket, fileKey, minio.RemoveObjectOptions{})
+	if err != nil && minio.ToErrorResponse(err).Code != "NoSuchKey" {
+		return fmt.Errorf("failed to remove object: %w", err)
+	}
+	return nil
 }
 
-// DeleteFile responsible for removing file from storage
-func (s *s3Service) DeleteFile(fileURL string) error {
+// ensures the bucket exists, creating it if needed
+func (s *s3Service) ensureBucket(ctx context.Context, region string) error {
+	exists, err := s.client.BucketExists(ctx, s.bucket)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket: %w", err)
+	}
+	if exists {
+		return nil
+	}
+
+	err = s.client.MakeBucket(ctx, s.bucket, minio.MakeBucketOptions{Region: region})
+	if err != nil {
+		// bucket could be created by another instance at the same time
+		var errResp minio.ErrorResponse
+		if errors.As(err, &errResp) && errResp.Code == "BucketAlreadyOwnedByYou" {
+			return nil
+		}
+		return fmt.Errorf("failed to create bucket: %w", err)
+	}
 	return nil
 }
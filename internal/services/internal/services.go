@@ -1,12 +1,47 @@
 package internal
 
 import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/saur4ig/file-storage/internal/config"
 	_interface "github.com/saur4ig/file-storage/internal/services/interface"
 )
 
 type s3Service struct {
+	client *minio.Client
+	bucket string
+}
+
+type localStorage struct {
+	root string
+}
+
+// NewS3Service creates a storage which keeps files in the S3 compatible object storage
+func NewS3Service(cfg config.S3Config) (_interface.FileStorage, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create s3 client: %w", err)
+	}
+
+	s := &s3Service{client: client, bucket: cfg.Bucket}
+	if err = s.ensureBucket(context.Background(), cfg.Region); err != nil {
+		return nil, err
+	}
+	return s, nil
 }
 
-func NewS3Service() _interface.FileStorage {
-	return &s3Service{}
+// NewLocalStorage creates a storage which keeps files in the root directory
+func NewLocalStorage(root string) (_interface.FileStorage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	return &localStorage{root: root}, nil
 }
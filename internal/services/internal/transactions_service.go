@@ -1,32 +1,272 @@
 package internal
 
 import (
-	"errors"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
 
+	"github.com/rs/zerolog/log"
 	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
 	"github.com/saur4ig/file-storage/internal/models"
 	_interface "github.com/saur4ig/file-storage/internal/services/interface"
 )
 
+// how many expired transactions are loaded at once
+const expiredTransactionsBatch = 100
+
 type transactionService struct {
 	transactionRepo rinterface.TransactionRepository
+	folderRepo      rinterface.FolderRepository
+	fileRepo        rinterface.FileRepository
+	blobRepo        rinterface.BlobRepository
+	versionRepo     rinterface.FileVersionRepository
+	cache           rinterface.FolderSizeCache
+	storage         _interface.FileStorage
+	db              *sql.DB
+	// ttl is the default time a transaction stays pending, maxTTL is the longest one a client may request
+	ttl    time.Duration
+	maxTTL time.Duration
 }
 
-func NewTransactionService(transactionRepo rinterface.TransactionRepository) _interface.TransactionService {
-	return &transactionService{transactionRepo: transactionRepo}
+// NewTransactionService creates a new TransactionService, transactions expire after ttl unless they request
+// another one up to maxTTL
+func NewTransactionService(
+	transactionRepo rinterface.TransactionRepository,
+	folderRepo rinterface.FolderRepository,
+	fileRepo rinterface.FileRepository,
+	blobRepo rinterface.BlobRepository,
+	versionRepo rinterface.FileVersionRepository,
+	cache rinterface.FolderSizeCache,
+	storage _interface.FileStorage,
+	db *sql.DB,
+	ttl, maxTTL time.Duration,
+) _interface.TransactionService {
+	return &transactionService{
+		transactionRepo: transactionRepo,
+		folderRepo:      folderRepo,
+		fileRepo:        fileRepo,
+		blobRepo:        blobRepo,
+		versionRepo:     versionRepo,
+		cache:           cache,
+		storage:         storage,
+		db:              db,
+		ttl:             ttl,
+		maxTTL:          maxTTL,
+	}
 }
 
-func (s *transactionService) CreateTransaction(userID int, folderID int64) (int64, error) {
-	return s.transactionRepo.CreateTransaction(userID, folderID)
+// CreateTransaction starts a pending transaction, which expires after the ttl, 0 for the default one.
+// If the ttl is longer than allowed - models.ErrInvalidTTL is returned
+func (s *transactionService) CreateTransaction(userID int, folderID int64, ttl time.Duration) (*models.UploadTransaction, error) {
+	if ttl == 0 {
+		ttl = s.ttl
+	}
+	if ttl < 0 || ttl > s.maxTTL {
+		return nil, fmt.Errorf("ttl %s is not within %s: %w", ttl, s.maxTTL, models.ErrInvalidTTL)
+	}
+	return s.transactionRepo.CreateTransaction(userID, folderID, time.Now().UTC().Add(ttl))
 }
 
 func (s *transactionService) GetTransactionByID(id int64) (*models.UploadTransaction, error) {
 	return s.transactionRepo.GetTransactionByID(id)
 }
 
-func (s *transactionService) UpdateTransactionStatus(id int64, status string) error {
-	if status != "pending" && status != "completed" && status != "failed" {
-		return errors.New("invalid status")
+// ListTransactions returns transactions of the user matching the filter, the newest first
+func (s *transactionService) ListTransactions(userID int, filter models.TransactionFilter) ([]models.UploadTransaction, error) {
+	return s.transactionRepo.GetUserTransactions(userID, filter)
+}
+
+// GetTransactionFiles returns files uploaded in the transaction, which are not in the trash
+func (s *transactionService) GetTransactionFiles(id int64) ([]models.File, error) {
+	return s.fileRepo.GetTransactionFiles(id)
+}
+
+// CompleteTransaction completes the pending or resumed transaction of the user and the folder, files uploaded in it are added
+// to the sizes of their folders, the cached sizes include them since the upload. If there is no such transaction -
+// models.ErrNotFound is returned, if it is neither pending nor resumed - *models.TransactionStatusError
+func (s *transactionService) CompleteTransaction(userID int, folderID, id int64) (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	return s.transactionRepo.UpdateTransactionStatus(id, status)
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	// the status changes after the running uploads in the transaction are saved, later ones are rejected
+	if _, err = s.transactionRepo.UpdateTransactionStatus(tx, id, userID, folderID, models.TransactionCompleted); err != nil {
+		return err
+	}
+
+	folders, err := s.fileRepo.GetTransactionFolderSizes(tx, id)
+	if err != nil {
+		return err
+	}
+	for _, folder := range folders {
+		if err = s.folderRepo.IncreaseFolderSize(tx, folder.ID, folder.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PauseTransaction pauses the pending or resumed transaction of the user and the folder, files can't be uploaded in it
+// and its ttl doesn't run until it is resumed. If there is no such transaction - models.ErrNotFound is returned,
+// if it is neither pending nor resumed - *models.TransactionStatusError
+func (s *transactionService) PauseTransaction(userID int, folderID, id int64) (*models.UploadTransaction, error) {
+	return s.updateTransactionStatus(userID, folderID, id, models.TransactionPaused)
+}
+
+// ResumeTransaction moves the paused transaction of the user and the folder to resumed, it accepts uploads again
+// and its expiry moves by the time it was paused for. If there is no such transaction - models.ErrNotFound is returned,
+// if it is not paused - *models.TransactionStatusError
+func (s *transactionService) ResumeTransaction(userID int, folderID, id int64) (*models.UploadTransaction, error) {
+	return s.updateTransactionStatus(userID, folderID, id, models.TransactionResumed)
+}
+
+// moves the transaction to the status, which doesn't change anything else, after the running uploads in it are saved
+func (s *transactionService) updateTransactionStatus(
+	userID int, folderID, id int64, status string,
+) (transaction *models.UploadTransaction, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	return s.transactionRepo.UpdateTransactionStatus(tx, id, userID, folderID, status)
+}
+
+// StopTransaction marks the transaction of the user and the folder failed and deletes the files uploaded in it
+// with their stored objects, their bytes are dropped from the cached folder sizes. Stopping a failed transaction
+// does nothing, so a failed request can be retried. If there is no such transaction - models.ErrNotFound is returned,
+// if it is completed - *models.TransactionStatusError
+func (s *transactionService) StopTransaction(ctx context.Context, userID int, folderID, id int64) error {
+	transaction := &models.UploadTransaction{ID: id, UserID: userID, FolderID: folderID}
+	_, err := s.rollbackTransaction(ctx, transaction, models.TransactionAuditStopped)
+	return err
+}
+
+// ExpireTransactions rolls back pending or resumed transactions which outlived their ttl in batches,
+// returns the number of rolled back transactions
+func (s *transactionService) ExpireTransactions(ctx context.Context) (int, error) {
+	expired := 0
+	for {
+		transactions, err := s.transactionRepo.GetExpiredTransactions(time.Now().UTC(), expiredTransactionsBatch)
+		if err != nil {
+			return expired, err
+		}
+
+		for _, transaction := range transactions {
+			rolledBack, err := s.rollbackTransaction(ctx, &transaction, models.TransactionAuditExpired)
+			if err != nil {
+				return expired, fmt.Errorf("failed to expire transaction(%d): %w", transaction.ID, err)
+			}
+			if rolledBack {
+				expired++
+			}
+		}
+
+		if len(transactions) < expiredTransactionsBatch {
+			return expired, nil
+		}
+	}
+}
+
+// rollbackTransaction marks the transaction failed, deletes the files uploaded in it and records the action
+// in the audit, owned identifies the transaction with the user and the folder it must belong to. Bytes of the files
+// are dropped from the cached folder sizes and their stored objects are removed after the commit.
+// Reports false if the transaction is failed already or not expired anymore
+func (s *transactionService) rollbackTransaction(
+	ctx context.Context, owned *models.UploadTransaction, action string,
+) (bool, error) {
+	id := owned.ID
+	rolledBack, files, orphanKeys, err := s.deleteTransactionFiles(owned, action)
+	if err != nil || !rolledBack {
+		return false, err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+
+	// the upload handler added the files to the cached sizes of their folders
+	sizes := make(map[int64]int64)
+	for _, file := range files {
+		sizes[file.FolderID] += file.TotalSize()
+	}
+	folders := make([]models.FolderSizeSimplified, 0, len(sizes))
+	for folderID, size := range sizes {
+		folders = append(folders, models.FolderSizeSimplified{ID: folderID, Size: size})
+	}
+	if err = s.cache.DecreaseFolderSizes(ctx, folders); err != nil {
+		// the reconciliation fixes the cached sizes later
+		log.Warn().Msgf("Failed to drop files of transaction(%d) from the cache: %s", id, err.Error())
+	}
+
+	return true, nil
+}
+
+// fails the transaction and deletes its files in a single database transaction, reports whether the transaction
+// was rolled back, returns the deleted files and keys of the objects which are not referenced anymore
+func (s *transactionService) deleteTransactionFiles(
+	owned *models.UploadTransaction, action string,
+) (rolledBack bool, files []models.File, orphanKeys []string, err error) {
+	id := owned.ID
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	// running uploads in the transaction are saved before the lock, later ones and the completion wait for it
+	transaction, err := s.transactionRepo.LockTransaction(tx, id)
+	if err != nil {
+		return false, nil, nil, err
+	}
+	if transaction.UserID != owned.UserID || transaction.FolderID != owned.FolderID {
+		return false, nil, nil, fmt.Errorf("transaction %d not found: %w", id, models.ErrNotFound)
+	}
+	if transaction.Status == models.TransactionFailed {
+		return false, nil, nil, nil
+	}
+	// the ttl of a paused transaction doesn't run
+	expiring := action == models.TransactionAuditExpired
+	if expiring && (!transaction.Open() || transaction.ExpiresAt.After(time.Now().UTC())) {
+		return false, nil, nil, nil
+	}
+	if _, err = s.transactionRepo.UpdateTransactionStatus(
+		tx, id, transaction.UserID, transaction.FolderID, models.TransactionFailed,
+	); err != nil {
+		return false, nil, nil, err
+	}
+
+	if files, err = s.fileRepo.DeleteTransactionFiles(tx, id); err != nil {
+		return false, nil, nil, err
+	}
+	if orphanKeys, err = releaseFilesContent(tx, s.blobRepo, s.versionRepo, files); err != nil {
+		return false, nil, nil, err
+	}
+
+	audit := &models.TransactionAudit{
+		TransactionID: transaction.ID,
+		UserID:        transaction.UserID,
+		FolderID:      transaction.FolderID,
+		Action:        action,
+		FileCount:     len(files),
+	}
+	for _, file := range files {
+		audit.Size += file.TotalSize()
+	}
+	if err = s.transactionRepo.CreateTransactionAudit(tx, audit); err != nil {
+		return false, nil, nil, err
+	}
+
+	return true, files, orphanKeys, nil
 }
@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
+	"github.com/saur4ig/file-storage/internal/models"
+	_interface "github.com/saur4ig/file-storage/internal/services/interface"
+)
+
+// how many expired trash items are loaded at once
+const expiredTrashBatch = 100
+
+type trashService struct {
+	trashRepo   rinterface.TrashRepository
+	folderRepo  rinterface.FolderRepository
+	fileRepo    rinterface.FileRepository
+	blobRepo    rinterface.BlobRepository
+	versionRepo rinterface.FileVersionRepository
+	storage     _interface.FileStorage
+	db          *sql.DB
+	retention   time.Duration
+}
+
+// NewTrashService creates a new TrashService, deleted items are kept in the trash for retention
+func NewTrashService(
+	trashRepo rinterface.TrashRepository,
+	folderRepo rinterface.FolderRepository,
+	fileRepo rinterface.FileRepository,
+	blobRepo rinterface.BlobRepository,
+	versionRepo rinterface.FileVersionRepository,
+	storage _interface.FileStorage,
+	db *sql.DB,
+	retention time.Duration,
+) _interface.TrashService {
+	return &trashService{
+		trashRepo:   trashRepo,
+		folderRepo:  folderRepo,
+		fileRepo:    fileRepo,
+		blobRepo:    blobRepo,
+		versionRepo: versionRepo,
+		storage:     storage,
+		db:          db,
+		retention:   retention,
+	}
+}
+
+// GetTrashItem returns the item from the trash
+func (s *trashService) GetTrashItem(id int64) (*models.TrashItem, error) {
+	item, err := s.trashRepo.GetTrashItem(id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.ExpiresAt = item.DeletedAt.Add(s.retention)
+	return item, nil
+}
+
+// ListTrash returns all items in the trash of the user
+func (s *trashService) ListTrash(userID int) ([]models.TrashItem, error) {
+	items, err := s.trashRepo.ListTrashItems(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range items {
+		items[i].ExpiresAt = items[i].DeletedAt.Add(s.retention)
+	}
+	return items, nil
+}
+
+// DeleteTrashItem permanently deletes the item with all its content,
+// stored objects are removed when no other file references them
+func (s *trashService) DeleteTrashItem(id int64) error {
+	orphanKeys, err := s.purgeItem(id)
+	if err != nil {
+		return err
+	}
+
+	removeObjects(s.storage, orphanKeys...)
+	return nil
+}
+
+// PurgeExpiredItems permanently deletes items kept in the trash longer than the retention period, returns the number
+// of deleted items, items restored or purged by another request meanwhile are not counted
+func (s *trashService) PurgeExpiredItems() (int, error) {
+	purged := 0
+	for {
+		items, err := s.trashRepo.GetExpiredTrashItems(time.Now().UTC().Add(-s.retention), expiredTrashBatch)
+		if err != nil {
+			return purged, err
+		}
+
+		for _, item := range items {
+			err = s.DeleteTrashItem(item.ID)
+			if errors.Is(err, models.ErrNotFound) {
+				// restored or purged by another request meanwhile
+				continue
+			}
+			if err != nil {
+				return purged, fmt.Errorf("failed to purge trash item(%d): %w", item.ID, err)
+			}
+			purged++
+		}
+
+		if len(items) < expiredTrashBatch {
+			return purged, nil
+		}
+	}
+}
+
+func (s *trashService) purgeItem(id int64) (orphanKeys []string, err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+	}()
+
+	// the item can't be restored while it is purged
+	item, err := s.trashRepo.LockTrashItem(tx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := s.fileRepo.DeleteTrashedFiles(tx, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if orphanKeys, err = releaseFilesContent(tx, s.blobRepo, s.versionRepo, files); err != nil {
+		return nil, err
+	}
+
+	if err = s.folderRepo.DeleteTrashedFolders(tx, item.ID); err != nil {
+		return nil, err
+	}
+	if err = s.trashRepo.DeleteTrashItem(tx, item.ID); err != nil {
+		return nil, err
+	}
+
+	return orphanKeys, nil
+}
+
+// restoreTarget returns the folder the trash item is restored to: the folder it was deleted from,
+// if it still exists, otherwise the requested one
+func restoreTarget(folderRepo rinterface.FolderRepository, item *models.TrashItem, requestedID int64) (int64, error) {
+	folder, err := folderRepo.GetFolderByID(item.ParentFolderID)
+	if err == nil {
+		return folder.ID, nil
+	}
+	if !errors.Is(err, models.ErrNotFound) {
+		return 0, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+
+	if requestedID == 0 {
+		return 0, fmt.Errorf("folder(%d) of %q: %w", item.ParentFolderID, item.Name, models.ErrParentGone)
+	}
+	folder, err = folderRepo.GetFolderByID(requestedID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get folder by ID: %w", err)
+	}
+	if folder.UserID != item.UserID {
+		return 0, fmt.Errorf("folder not found: %w", models.ErrNotFound)
+	}
+	return folder.ID, nil
+}
@@ -0,0 +1,286 @@
+package internal
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/saur4ig/file-storage/internal/checksum"
+	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
+	"github.com/saur4ig/file-storage/internal/models"
+	_interface "github.com/saur4ig/file-storage/internal/services/interface"
+)
+
+// number of expired uploads removed at once
+const expiredUploadsBatch = 100
+
+type uploadService struct {
+	uploadRepo  rinterface.UploadRepository
+	fileService _interface.FileService
+	storage     _interface.FileStorage
+	db          *sql.DB
+	expiration  time.Duration
+}
+
+// NewUploadService creates a new UploadService, unfinished uploads are kept for expiration after the last chunk
+func NewUploadService(
+	uploadRepo rinterface.UploadRepository,
+	fileService _interface.FileService,
+	storage _interface.FileStorage,
+	db *sql.DB,
+	expiration time.Duration,
+) _interface.UploadService {
+	return &uploadService{
+		uploadRepo:  uploadRepo,
+		fileService: fileService,
+		storage:     storage,
+		db:          db,
+		expiration:  expiration,
+	}
+}
+
+// CreateUpload registers a new upload of the file with known length
+func (s *uploadService) CreateUpload(
+	userID int, folderID int64, name string, length int64, metadata, onConflict string,
+) (*models.Upload, error) {
+	// the name is checked right away, not after the whole file is received
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	id, err := newUploadID()
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &models.Upload{
+		ID:         id,
+		UserID:     userID,
+		FolderID:   folderID,
+		Name:       name,
+		Length:     length,
+		Metadata:   metadata,
+		OnConflict: onConflict,
+		ExpiresAt:  s.expiresAt(),
+	}
+	if err = s.uploadRepo.CreateUpload(upload); err != nil {
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+	return upload, nil
+}
+
+// GetUpload returns the upload by id, expired uploads are treated as gone
+func (s *uploadService) GetUpload(id string) (*models.Upload, error) {
+	upload, err := s.uploadRepo.GetUploadByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, models.ErrUploadExpired
+	}
+	return upload, nil
+}
+
+// WriteChunk stores the chunk as a separate object and moves the upload offset
+func (s *uploadService) WriteChunk(upload *models.Upload, offset int64, chunk io.Reader, size int64) (updated *models.Upload, err error) {
+	if offset != upload.Offset {
+		return nil, models.ErrOffsetMismatch
+	}
+
+	partKey, written, err := s.storage.UploadFile(chunk, size, upload.Name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store upload part: %w", err)
+	}
+	if written == 0 {
+		// nothing received, there is no need to keep an empty part
+		s.deleteObject(partKey)
+		return upload, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		s.deleteObject(partKey)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		err = handleTxEnd(tx, err)
+		if err != nil {
+			s.deleteObject(partKey)
+		}
+	}()
+
+	updated, err = s.uploadRepo.MoveUploadOffset(tx, upload.ID, offset, written, s.expiresAt())
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.uploadRepo.CreateUploadPart(tx, &models.UploadPart{
+		UploadID:   upload.ID,
+		PartNumber: updated.Parts,
+		S3URL:      partKey,
+		Size:       written,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// CompleteUpload joins all received parts into one object and saves it as a file,
+// returns the number of bytes the folder size grew by
+func (s *uploadService) CompleteUpload(upload *models.Upload) (int64, error) {
+	if upload.Offset != upload.Length {
+		return 0, fmt.Errorf("upload is not finished: %d of %d bytes received", upload.Offset, upload.Length)
+	}
+
+	parts, err := s.uploadRepo.GetUploadParts(upload.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	content := &partsReader{storage: s.storage, parts: parts}
+	defer content.Close()
+
+	// checksums are calculated while the parts are joined
+	sums := checksum.NewReader(content)
+	fileKey, written, err := s.storage.UploadFile(sums, upload.Length, upload.Name)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store uploaded file: %w", err)
+	}
+
+	added, err := s.fileService.UploadFile(&models.File{
+		FolderID: upload.FolderID,
+		UserID:   upload.UserID,
+		Name:     upload.Name,
+		S3URL:    fileKey,
+		Size:     written,
+		Checksum: sums.SHA256(),
+		MD5:      sums.MD5(),
+	}, upload.OnConflict)
+	if err != nil {
+		s.deleteObject(fileKey)
+		return 0, fmt.Errorf("failed to save uploaded file: %w", err)
+	}
+
+	// the file is saved, parts are not needed anymore
+	if err = s.uploadRepo.DeleteUpload(upload.ID); err != nil {
+		log.Warn().Msgf("Failed to delete completed upload(%s): %s", upload.ID, err.Error())
+		return added, nil
+	}
+	s.deleteParts(parts)
+
+	return added, nil
+}
+
+// DeleteUpload terminates the upload and removes all received parts
+func (s *uploadService) DeleteUpload(upload *models.Upload) error {
+	parts, err := s.uploadRepo.GetUploadParts(upload.ID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.uploadRepo.DeleteUpload(upload.ID); err != nil {
+		return err
+	}
+	s.deleteParts(parts)
+
+	return nil
+}
+
+// DeleteExpiredUploads removes expired uploads in batches
+func (s *uploadService) DeleteExpiredUploads() (int, error) {
+	deleted := 0
+	for {
+		uploads, err := s.uploadRepo.GetExpiredUploads(time.Now().UTC(), expiredUploadsBatch)
+		if err != nil {
+			return deleted, err
+		}
+
+		for i := range uploads {
+			if err = s.DeleteUpload(&uploads[i]); err != nil {
+				return deleted, fmt.Errorf("failed to delete expired upload(%s): %w", uploads[i].ID, err)
+			}
+			deleted++
+		}
+
+		if len(uploads) < expiredUploadsBatch {
+			return deleted, nil
+		}
+	}
+}
+
+// returns the expiration time of the upload with activity right now
+func (s *uploadService) expiresAt() time.Time {
+	return time.Now().UTC().Add(s.expiration)
+}
+
+// removes stored parts, failures are only logged
+func (s *uploadService) deleteParts(parts []models.UploadPart) {
+	for _, part := range parts {
+		s.deleteObject(part.S3URL)
+	}
+}
+
+// removes a stored object, failures are only logged
+func (s *uploadService) deleteObject(fileKey string) {
+	if err := s.storage.DeleteFile(fileKey); err != nil {
+		log.Warn().Msgf("Failed to remove stored object(%s): %s", fileKey, err.Error())
+	}
+}
+
+// partsReader reads stored parts one after another, only one part is opened at a time
+type partsReader struct {
+	storage _interface.FileStorage
+	parts   []models.UploadPart
+	current io.ReadCloser
+}
+
+func (p *partsReader) Read(b []byte) (int, error) {
+	for {
+		if p.current == nil {
+			if len(p.parts) == 0 {
+				return 0, io.EOF
+			}
+			part, err := p.storage.OpenFile(p.parts[0].S3URL)
+			if err != nil {
+				return 0, fmt.Errorf("failed to open upload part %d: %w", p.parts[0].PartNumber, err)
+			}
+			p.current = part
+			p.parts = p.parts[1:]
+		}
+
+		n, err := p.current.Read(b)
+		if errors.Is(err, io.EOF) {
+			p.current.Close()
+			p.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+func (p *partsReader) Close() error {
+	if p.current == nil {
+		return nil
+	}
+	return p.current.Close()
+}
+
+// generates a random upload id
+func newUploadID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate upload id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,51 @@
+package internal
+
+import (
+	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
+	"github.com/saur4ig/file-storage/internal/models"
+	_interface "github.com/saur4ig/file-storage/internal/services/interface"
+)
+
+type userService struct {
+	userRepo rinterface.UserRepository
+}
+
+func NewUserService(userRepo rinterface.UserRepository) _interface.UserService {
+	return &userService{userRepo: userRepo}
+}
+
+// GetUserQuota returns the quota of the user, the usage is taken from the stored sizes of the root folders
+func (s *userService) GetUserQuota(userID int) (*models.UserQuota, error) {
+	return s.userRepo.GetUserQuota(userID)
+}
+
+// SetUserQuota changes the quota of the user and returns it with the current usage,
+// a quota below the usage does not delete anything, but new data can't be stored
+func (s *userService) SetUserQuota(userID int, quota *int64) (*models.UserQuota, error) {
+	if err := s.userRepo.UpdateUserQuota(userID, quota); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetUserQuota(userID)
+}
+
+// CheckQuota checks the free space without reserving it, the request is checked again when its data is saved
+func (s *userService) CheckQuota(userID int, size int64) error {
+	quota, err := s.userRepo.GetUserQuota(userID)
+	if err != nil {
+		return err
+	}
+	return quota.Check(size)
+}
+
+// GetUserSettings returns the settings of the user
+func (s *userService) GetUserSettings(userID int) (*models.UserSettings, error) {
+	return s.userRepo.GetUserSettings(userID)
+}
+
+// UpdateUserSettings saves the settings of the user and returns them as stored
+func (s *userService) UpdateUserSettings(settings *models.UserSettings) (*models.UserSettings, error) {
+	if err := s.userRepo.UpdateUserSettings(settings); err != nil {
+		return nil, err
+	}
+	return s.userRepo.GetUserSettings(settings.UserID)
+}
@@ -2,24 +2,100 @@ package services
 
 import (
 	"database/sql"
+	"time"
 
+	"github.com/saur4ig/file-storage/internal/config"
 	rinterface "github.com/saur4ig/file-storage/internal/database/interface"
 	_interface "github.com/saur4ig/file-storage/internal/services/interface"
 	"github.com/saur4ig/file-storage/internal/services/internal"
 )
 
-func NewS3Service() _interface.FileStorage {
-	return internal.NewS3Service()
+func NewS3Service(cfg config.S3Config) (_interface.FileStorage, error) {
+	return internal.NewS3Service(cfg)
 }
 
-func NewTransactionService(tr rinterface.TransactionRepository) _interface.TransactionService {
-	return internal.NewTransactionService(tr)
+func NewLocalStorage(root string) (_interface.FileStorage, error) {
+	return internal.NewLocalStorage(root)
 }
 
-func NewFileService(folderRepo rinterface.FolderRepository, fileRepo rinterface.FileRepository, db *sql.DB) _interface.FileService {
-	return internal.NewFileService(fileRepo, folderRepo, db)
+func NewTransactionService(
+	transactionRepo rinterface.TransactionRepository,
+	folderRepo rinterface.FolderRepository,
+	fileRepo rinterface.FileRepository,
+	blobRepo rinterface.BlobRepository,
+	versionRepo rinterface.FileVersionRepository,
+	cache rinterface.FolderSizeCache,
+	storage _interface.FileStorage,
+	db *sql.DB,
+	ttl, maxTTL time.Duration,
+) _interface.TransactionService {
+	return internal.NewTransactionService(
+		transactionRepo, folderRepo, fileRepo, blobRepo, versionRepo, cache, storage, db, ttl, maxTTL,
+	)
 }
 
-func NewFolderService(folderRepo rinterface.FolderRepository, fileRepo rinterface.FileRepository, db *sql.DB) _interface.FolderService {
-	return internal.NewFolderService(folderRepo, fileRepo, db)
+func NewUserService(ur rinterface.UserRepository) _interface.UserService {
+	return internal.NewUserService(ur)
+}
+
+func NewFileService(
+	folderRepo rinterface.FolderRepository,
+	fileRepo rinterface.FileRepository,
+	blobRepo rinterface.BlobRepository,
+	trashRepo rinterface.TrashRepository,
+	versionRepo rinterface.FileVersionRepository,
+	userRepo rinterface.UserRepository,
+	transactionRepo rinterface.TransactionRepository,
+	storage _interface.FileStorage,
+	db *sql.DB,
+	maxVersions int,
+) _interface.FileService {
+	return internal.NewFileService(
+		fileRepo, folderRepo, blobRepo, trashRepo, versionRepo, userRepo, transactionRepo, storage, db, maxVersions,
+	)
+}
+
+func NewFolderService(
+	folderRepo rinterface.FolderRepository,
+	fileRepo rinterface.FileRepository,
+	blobRepo rinterface.BlobRepository,
+	trashRepo rinterface.TrashRepository,
+	versionRepo rinterface.FileVersionRepository,
+	userRepo rinterface.UserRepository,
+	storage _interface.FileStorage,
+	db *sql.DB,
+) _interface.FolderService {
+	return internal.NewFolderService(folderRepo, fileRepo, blobRepo, trashRepo, versionRepo, userRepo, storage, db)
+}
+
+func NewTrashService(
+	trashRepo rinterface.TrashRepository,
+	folderRepo rinterface.FolderRepository,
+	fileRepo rinterface.FileRepository,
+	blobRepo rinterface.BlobRepository,
+	versionRepo rinterface.FileVersionRepository,
+	storage _interface.FileStorage,
+	db *sql.DB,
+	retention time.Duration,
+) _interface.TrashService {
+	return internal.NewTrashService(trashRepo, folderRepo, fileRepo, blobRepo, versionRepo, storage, db, retention)
+}
+
+func NewUploadService(
+	uploadRepo rinterface.UploadRepository,
+	fileService _interface.FileService,
+	storage _interface.FileStorage,
+	db *sql.DB,
+	expiration time.Duration,
+) _interface.UploadService {
+	return internal.NewUploadService(uploadRepo, fileService, storage, db, expiration)
+}
+
+func NewReconcileService(
+	folderRepo rinterface.FolderRepository,
+	userRepo rinterface.UserRepository,
+	cache rinterface.FolderSizeCache,
+	db *sql.DB,
+) _interface.ReconcileService {
+	return internal.NewReconcileService(folderRepo, userRepo, cache, db)
 }